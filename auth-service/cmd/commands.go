@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"auth-service/internal/clients"
+	"auth-service/internal/provisioning"
+	"auth-service/internal/repository"
+	"auth-service/pkg/config"
+	"auth-service/pkg/database"
+
+	"shared/mail"
+)
+
+const usage = `Usage:
+  auth-service                              start the HTTP and gRPC servers
+  auth-service users import [flags] FILE    provision users from a CSV file
+
+The CSV needs a header row with an email column and an optional password column.
+`
+
+// runCommand handles the admin subcommands and returns the process exit code
+func runCommand(cfg *config.Config, args []string) int {
+	if len(args) >= 2 && args[0] == "users" && args[1] == "import" {
+		return runUsersImport(cfg, args[2:])
+	}
+
+	fmt.Fprint(os.Stderr, usage)
+	return 2
+}
+
+func runUsersImport(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("users import", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "validate and report without creating anything")
+	invite := flags.Bool("invite", false, "create rows without a password and email them an invite")
+	batchSize := flags.Int("batch-size", 50, "rows looked up and created per database query and user service call, at most 100")
+	resume := flags.Bool("resume", false, "skip rows the report already marks as done and append to it")
+	reportPath := flags.String("report", "import-report.csv", "per-row results file, a dry run writes FILE.dry-run.csv for FILE.csv instead")
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, "Usage: auth-service users import [flags] FILE\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	if err := database.InitDB(cfg); err != nil {
+		log.Printf("Failed to initialize database: %v", err)
+		return 1
+	}
+
+	userServiceClient, err := clients.NewUserServiceClient(cfg.UserServiceURL)
+	if err != nil {
+		log.Printf("Failed to create user service client: %v", err)
+		return 1
+	}
+	defer userServiceClient.Close()
+
+	mailer, err := mail.NewSender(cfg.GetMailConfig())
+	if err != nil {
+		log.Printf("Failed to create mail sender: %v", err)
+		return 1
+	}
+
+	options := provisioning.Options{
+		DryRun:     *dryRun,
+		Invite:     *invite,
+		BatchSize:  *batchSize,
+		Resume:     *resume,
+		ReportPath: *reportPath,
+		LoginURL:   cfg.InviteLoginURL,
+	}
+	importer := provisioning.NewImporter(repository.NewCredentialRepository(database.GetDB()), userServiceClient, mailer, options)
+
+	summary, err := importer.Import(context.Background(), flags.Arg(0))
+	if summary != nil {
+		fmt.Printf("Rows: %d, done: %d, skipped: %d, failed: %d (report: %s)\n",
+			summary.Total, summary.Done, summary.Skipped, summary.Failed, options.ReportFile())
+	}
+	if err != nil {
+		log.Printf("Import aborted: %v", err)
+		return 1
+	}
+	if summary.Failed > 0 {
+		return 1
+	}
+	return 0
+}
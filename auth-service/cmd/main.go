@@ -3,17 +3,25 @@ package main
 import (
 	"log"
 	"net"
+	"os"
 	"strconv"
+	"time"
 
 	"auth-service/internal/clients"
+	"auth-service/internal/geoip"
 	authGrpc "auth-service/internal/grpc"
 	"auth-service/internal/handlers"
 	"auth-service/internal/repository"
+	"auth-service/internal/risk"
 	"auth-service/internal/services"
 	"auth-service/pkg/config"
 	"auth-service/pkg/database"
 
+	"shared/locale"
+	"shared/mail"
 	"shared/proto/auth_service"
+	"shared/proto/data_export"
+	"shared/ratelimit"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
@@ -25,6 +33,11 @@ func main() {
 		log.Fatal("Failed to load configuration:", err)
 	}
 
+	// Admin subcommands run instead of the servers
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cfg, os.Args[1:]))
+	}
+
 	if err := database.InitDB(cfg); err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
@@ -35,19 +48,65 @@ func main() {
 	}
 	defer userServiceClient.Close()
 
+	mailer, err := mail.NewSender(cfg.GetMailConfig())
+	if err != nil {
+		log.Fatal("Failed to create mail sender:", err)
+	}
+
+	// Shared Redis for rate limits, nil means in-memory limits only
+	redisClient := ratelimit.ConnectRedis(cfg.RedisURL)
+	if redisClient != nil {
+		defer redisClient.Close()
+	}
+
+	magicLinkLimiters := services.MagicLinkLimiters{
+		Email: ratelimit.New(redisClient, "ratelimit:auth-service:magic-link:email:", ratelimit.Policy{
+			Algorithm: ratelimit.SlidingWindow,
+			Limit:     cfg.MagicLinkEmailLimitPerHour,
+			Window:    time.Hour,
+		}),
+		IP: ratelimit.New(redisClient, "ratelimit:auth-service:magic-link:ip:", ratelimit.Policy{
+			Algorithm: ratelimit.SlidingWindow,
+			Limit:     cfg.MagicLinkIPLimitPerHour,
+			Window:    time.Hour,
+		}),
+	}
+
 	credentialRepo := repository.NewCredentialRepository(database.GetDB())
+	magicLinkRepo := repository.NewMagicLinkRepository(database.GetDB())
+	loginEventRepo := repository.NewLoginEventRepository(database.GetDB())
+	stepUpRepo := repository.NewStepUpRepository(database.GetDB())
+
+	// Suspicious login detection, impossible travel needs a GeoIP database
+	var geo geoip.Resolver
+	riskRules := []risk.Rule{
+		risk.NewDeviceRule(loginEventRepo, 30),
+		risk.NewIPRangeRule(loginEventRepo, 20),
+		risk.FailureBurstRule(loginEventRepo, cfg.FailureBurstThreshold, cfg.GetFailureBurstWindow(), 40),
+	}
+	if cfg.GeoIPDatabasePath != "" {
+		geo, err = geoip.LoadCSV(cfg.GeoIPDatabasePath)
+		if err != nil {
+			log.Fatal("Failed to load GeoIP database:", err)
+		}
+		riskRules = append(riskRules, risk.ImpossibleTravelRule(loginEventRepo, float64(cfg.ImpossibleTravelKmh), 50))
+	}
+	riskEngine := risk.NewEngine(riskRules...)
 
-	authService := services.NewAuthService(credentialRepo, userServiceClient, cfg)
+	authService := services.NewAuthService(credentialRepo, magicLinkRepo, loginEventRepo, stepUpRepo, userServiceClient, mailer, magicLinkLimiters, riskEngine, geo, cfg)
 
-	authHandler := handlers.NewAuthHandler(authService)
+	authHandler := handlers.NewAuthHandler(authService, cfg)
+
+	rateLimiter := ratelimit.NewMiddleware(redisClient, "auth-service", cfg.RateLimitPolicies)
 
 	authServer := authGrpc.NewAuthServer(cfg)
-	go startGRPCServer(authServer, cfg)
+	exportServer := authGrpc.NewExportServer(credentialRepo, loginEventRepo)
+	go startGRPCServer(authServer, exportServer, cfg)
 
-	startHTTPServer(cfg, authHandler)
+	startHTTPServer(cfg, authHandler, rateLimiter)
 }
 
-func startGRPCServer(authServer *authGrpc.AuthServer, cfg *config.Config) {
+func startGRPCServer(authServer *authGrpc.AuthServer, exportServer *authGrpc.ExportServer, cfg *config.Config) {
 	log.Printf("Starting gRPC server setup...")
 
 	grpcPort, err := strconv.Atoi(cfg.Port)
@@ -65,6 +124,7 @@ func startGRPCServer(authServer *authGrpc.AuthServer, cfg *config.Config) {
 
 	grpcServer := grpc.NewServer()
 	auth_service.RegisterAuthServiceServer(grpcServer, authServer)
+	data_export.RegisterDataExportServer(grpcServer, exportServer)
 
 	if err := grpcServer.Serve(lis); err != nil {
 		log.Printf("Failed to start gRPC server: %v", err)
@@ -72,7 +132,7 @@ func startGRPCServer(authServer *authGrpc.AuthServer, cfg *config.Config) {
 	}
 }
 
-func startHTTPServer(cfg *config.Config, authHandler *handlers.AuthHandler) {
+func startHTTPServer(cfg *config.Config, authHandler *handlers.AuthHandler, rateLimiter *ratelimit.Middleware) {
 	gin.SetMode(cfg.GinMode)
 
 	r := gin.Default()
@@ -80,8 +140,12 @@ func startHTTPServer(cfg *config.Config, authHandler *handlers.AuthHandler) {
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
+	if cfg.RateLimitEnabled {
+		r.Use(rateLimiter.Handler())
+	}
+
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
+		locale.JSON(c, 200, gin.H{
 			"status":  "healthy",
 			"service": "auth-service",
 			"port":    cfg.Port,
@@ -92,7 +156,11 @@ func startHTTPServer(cfg *config.Config, authHandler *handlers.AuthHandler) {
 	{
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/login/verify", authHandler.VerifyStepUp)
 		authGroup.POST("/refresh", authHandler.RefreshToken)
+		authGroup.POST("/magic-link", authHandler.RequestMagicLink)
+		authGroup.GET("/magic-link/consume", authHandler.ConsumeMagicLink)
+		authGroup.POST("/token/exchange", authHandler.ExchangeToken)
 	}
 
 	log.Printf("HTTP server starting on port %s", cfg.Port)
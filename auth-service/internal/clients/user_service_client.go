@@ -36,7 +36,19 @@ func (c *UserServiceClient) CreateUser(ctx context.Context, req *user_service.Cr
 
 	response, err := c.client.CreateUser(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %v", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return response, nil
+}
+
+func (c *UserServiceClient) BatchCreateUsers(ctx context.Context, req *user_service.BatchCreateUsersRequest) (*user_service.BatchCreateUsersResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	response, err := c.client.BatchCreateUsers(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create users: %w", err)
 	}
 
 	return response, nil
@@ -48,7 +60,19 @@ func (c *UserServiceClient) GetUserByEmail(ctx context.Context, req *user_servic
 
 	response, err := c.client.GetUserByEmail(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user by email: %v", err)
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return response, nil
+}
+
+func (c *UserServiceClient) GetUserPreferences(ctx context.Context, req *user_service.GetUserPreferencesRequest) (*user_service.GetUserPreferencesResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	response, err := c.client.GetUserPreferences(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
 	}
 
 	return response, nil
@@ -12,6 +12,22 @@ type AuthRes struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresAt    int64  `json:"expires_at"`
+
+	// Set instead of tokens when a risky login needs an emailed code
+	StepUpRequired bool   `json:"step_up_required,omitempty"`
+	ChallengeID    string `json:"challenge_id,omitempty"`
+}
+
+// ClientInfo describes the client making an auth request
+type ClientInfo struct {
+	IPAddress         string
+	UserAgent         string
+	DeviceFingerprint string
+}
+
+type StepUpVerifyReq struct {
+	ChallengeID string `json:"challenge_id" binding:"required"`
+	Code        string `json:"code" binding:"required,len=6,numeric"`
 }
 
 type RefreshTokenReq struct {
@@ -23,3 +39,35 @@ type RefreshTokenRes struct {
 	RefreshToken string `json:"refresh_token"`
 	ExpiresAt    int64  `json:"expires_at"`
 }
+
+type MagicLinkReq struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type MagicLinkRes struct {
+	Message   string `json:"message"`
+	ExpiresAt int64  `json:"expires_at"`
+}
+
+// RFC 8693 token exchange identifiers
+const (
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	TokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+)
+
+type TokenExchangeReq struct {
+	GrantType          string `json:"grant_type" form:"grant_type" binding:"required"`
+	SubjectToken       string `json:"subject_token" form:"subject_token" binding:"required"`
+	SubjectTokenType   string `json:"subject_token_type" form:"subject_token_type" binding:"required"`
+	Audience           string `json:"audience" form:"audience" binding:"required"`
+	Scope              string `json:"scope" form:"scope" binding:"required"`
+	RequestedTokenType string `json:"requested_token_type,omitempty" form:"requested_token_type"`
+}
+
+type TokenExchangeRes struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	Scope           string `json:"scope"`
+}
@@ -0,0 +1,131 @@
+package geoip
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+)
+
+type Location struct {
+	Country   string  `json:"country"`
+	City      string  `json:"city"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Resolver maps an IP address to an approximate location
+type Resolver interface {
+	Lookup(ip string) (*Location, bool)
+}
+
+type ipRange struct {
+	start    netip.Addr
+	end      netip.Addr
+	location Location
+}
+
+type csvDatabase struct {
+	ranges []ipRange
+}
+
+// LoadCSV reads a local GeoIP database in the common range CSV layout
+//
+//	start_ip,end_ip,country_code,city,latitude,longitude
+//
+// used by the free DB-IP and IP2Location city exports. Lines that can't be
+// parsed (such as a header) are skipped.
+func LoadCSV(path string) (Resolver, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database: %v", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+
+	var ranges []ipRange
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GeoIP database: %v", err)
+		}
+		if len(record) < 6 {
+			continue
+		}
+
+		start, err := netip.ParseAddr(record[0])
+		if err != nil {
+			continue
+		}
+		end, err := netip.ParseAddr(record[1])
+		if err != nil {
+			continue
+		}
+		latitude, err := strconv.ParseFloat(record[4], 64)
+		if err != nil {
+			continue
+		}
+		longitude, err := strconv.ParseFloat(record[5], 64)
+		if err != nil {
+			continue
+		}
+
+		ranges = append(ranges, ipRange{
+			start: start.Unmap(),
+			end:   end.Unmap(),
+			location: Location{
+				Country:   record[2],
+				City:      record[3],
+				Latitude:  latitude,
+				Longitude: longitude,
+			},
+		})
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.Less(ranges[j].start)
+	})
+
+	return &csvDatabase{ranges: ranges}, nil
+}
+
+func (d *csvDatabase) Lookup(ip string) (*Location, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return nil, false
+	}
+	addr = addr.Unmap()
+
+	// Find the last range starting at or before addr
+	i := sort.Search(len(d.ranges), func(i int) bool {
+		return addr.Less(d.ranges[i].start)
+	}) - 1
+	if i < 0 || d.ranges[i].end.Less(addr) {
+		return nil, false
+	}
+
+	location := d.ranges[i].location
+	return &location, true
+}
+
+// DistanceKm is the great-circle distance between two locations
+func DistanceKm(a, b Location) float64 {
+	const earthRadiusKm = 6371.0
+
+	lat1 := a.Latitude * math.Pi / 180
+	lat2 := b.Latitude * math.Pi / 180
+	dLat := (b.Latitude - a.Latitude) * math.Pi / 180
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
@@ -3,18 +3,22 @@ package grpc
 import (
 	"context"
 	"log"
-	"time"
 
 	"auth-service/pkg/config"
 
 	"shared/proto/auth_service"
 
 	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Claims struct {
-	Email  string `json:"email"`
-	UserID uint   `json:"user_id"`
+	Email    string `json:"email"`
+	UserID   uint   `json:"user_id"`
+	Scope    string `json:"scope,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	Timezone string `json:"tz,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -37,6 +41,11 @@ func (s *AuthServer) ValidateToken(ctx context.Context, req *auth_service.Valida
 		}, nil
 	}
 
+	// Callers must name themselves so tokens for other services are refused
+	if req.Audience == "" {
+		return nil, status.Error(codes.InvalidArgument, "audience is required")
+	}
+
 	// Parse and validate the JWT token
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(req.Token, claims, func(token *jwt.Token) (interface{}, error) {
@@ -44,7 +53,7 @@ func (s *AuthServer) ValidateToken(ctx context.Context, req *auth_service.Valida
 			return nil, jwt.ErrInvalidKeyType
 		}
 		return []byte(s.config.JWTSecret), nil
-	})
+	}, jwt.WithAudience(req.Audience), jwt.WithIssuer("auth-service"), jwt.WithExpirationRequired())
 
 	if err != nil {
 		log.Printf("Failed to parse token: %v", err)
@@ -61,11 +70,11 @@ func (s *AuthServer) ValidateToken(ctx context.Context, req *auth_service.Valida
 		}, nil
 	}
 
-	// Check if token is expired
-	if claims.ExpiresAt != nil && claims.ExpiresAt.Before(time.Now()) {
+	// The parser already rejects expired tokens, but the response needs both times
+	if claims.ExpiresAt == nil || claims.IssuedAt == nil {
 		return &auth_service.ValidateTokenResponse{
 			IsValid:      false,
-			ErrorMessage: "Token has expired",
+			ErrorMessage: "Token is missing exp or iat",
 		}, nil
 	}
 
@@ -82,6 +91,10 @@ func (s *AuthServer) ValidateToken(ctx context.Context, req *auth_service.Valida
 			Subject:   claims.Subject,
 			ExpiresAt: claims.ExpiresAt.Unix(),
 			IssuedAt:  claims.IssuedAt.Unix(),
+			Audience:  claims.Audience,
+			Scope:     claims.Scope,
+			Locale:    claims.Locale,
+			Timezone:  claims.Timezone,
 		},
 	}, nil
 }
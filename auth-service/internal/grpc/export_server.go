@@ -0,0 +1,105 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+
+	"shared/dataexport"
+	"shared/proto/data_export"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+)
+
+// ExportServer contributes credentials, sessions and login events to a
+// user's data export
+type ExportServer struct {
+	data_export.UnimplementedDataExportServer
+	credentialRepo repository.CredentialRepository
+	loginEventRepo repository.LoginEventRepository
+}
+
+func NewExportServer(credentialRepo repository.CredentialRepository, loginEventRepo repository.LoginEventRepository) *ExportServer {
+	return &ExportServer{
+		credentialRepo: credentialRepo,
+		loginEventRepo: loginEventRepo,
+	}
+}
+
+// session is a refresh token without the token itself
+type session struct {
+	ID                uint      `json:"id"`
+	IPAddress         string    `json:"ip_address"`
+	UserAgent         string    `json:"user_agent"`
+	DeviceFingerprint string    `json:"device_fingerprint"`
+	RiskScore         int       `json:"risk_score"`
+	IsRevoked         bool      `json:"is_revoked"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (s *ExportServer) ExportUserData(ctx context.Context, req *data_export.ExportUserDataRequest) (*data_export.ExportUserDataResponse, error) {
+	if req.Email == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	credential, err := s.credentialRepo.GetByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &data_export.ExportUserDataResponse{}, nil
+		}
+		log.Printf("Failed to get credential for export: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get credentials")
+	}
+
+	tokens, err := s.credentialRepo.GetRefreshTokensForCredential(credential.ID)
+	if err != nil {
+		log.Printf("Failed to get sessions for export: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get sessions")
+	}
+	sessions := make([]session, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, session{
+			ID:                token.ID,
+			IPAddress:         token.IPAddress,
+			UserAgent:         token.UserAgent,
+			DeviceFingerprint: token.DeviceFingerprint,
+			RiskScore:         token.RiskScore,
+			IsRevoked:         token.IsRevoked,
+			ExpiresAt:         token.ExpiresAt,
+			CreatedAt:         token.CreatedAt,
+		})
+	}
+
+	events, err := s.loginEventRepo.ListForCredential(credential.ID, credential.Email)
+	if err != nil {
+		log.Printf("Failed to get login events for export: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get login events")
+	}
+	if events == nil {
+		events = []models.LoginEvent{}
+	}
+
+	response := &data_export.ExportUserDataResponse{}
+	for _, item := range []struct {
+		name string
+		data any
+	}{
+		{"credentials", credential}, // the password hash is never serialized
+		{"sessions", sessions},
+		{"login_events", events},
+	} {
+		file, err := dataexport.JSONFile(item.name, item.data)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		response.Files = append(response.Files, file)
+	}
+	return response, nil
+}
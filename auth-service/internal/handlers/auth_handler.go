@@ -1,22 +1,36 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"log"
 	"net/http"
 
 	"auth-service/internal/dto"
 	"auth-service/internal/services"
+	"auth-service/pkg/config"
+
+	"shared/locale"
+	"shared/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	magicLinkNonceCookie = "magic_link_nonce"
+	magicLinkCookiePath  = "/api/v1/auth/magic-link"
+)
+
 type AuthHandler struct {
 	authService services.AuthService
+	config      *config.Config
 }
 
-func NewAuthHandler(authService services.AuthService) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, config *config.Config) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
+		config:      config,
 	}
 }
 
@@ -27,14 +41,14 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	// Meaning it will parse the request body and populate the req struct with the data
 	// This is a serializer for JSON data
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+		locale.JSON(c, http.StatusBadRequest, gin.H{
 			"error":   "Invalid request",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	response, err := h.authService.Register(&req)
+	response, err := h.authService.Register(&req, clientInfo(c))
 	if err != nil {
 		// log the error
 		log.Println("Error:", err.Error())
@@ -42,59 +56,187 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		//! Not a good practice to check for error messages like this
 		// Will improve this later
 		if err.Error() == "email already exists" {
-			c.JSON(http.StatusConflict, gin.H{
+			locale.JSON(c, http.StatusConflict, gin.H{
 				"error": err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
+		locale.JSON(c, http.StatusInternalServerError, gin.H{
 			"error": "Failed to register user",
 		})
 
 		return
 	}
 
-	c.JSON(http.StatusCreated, response)
+	locale.JSON(c, http.StatusCreated, response)
 }
 
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.AuthReq
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+		locale.JSON(c, http.StatusBadRequest, gin.H{
 			"error":   "Invalid request",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(&req, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to login",
+		utils.HandleError(c, err)
+		return
+	}
+
+	if response.StepUpRequired {
+		locale.JSON(c, http.StatusAccepted, response)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, response)
+}
+
+func (h *AuthHandler) VerifyStepUp(c *gin.Context) {
+	var req dto.StepUpVerifyReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		locale.JSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
 		})
+		return
+	}
+
+	response, err := h.authService.VerifyStepUp(&req, clientInfo(c))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	locale.JSON(c, http.StatusOK, response)
 }
 
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req dto.RefreshTokenReq
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+		locale.JSON(c, http.StatusBadRequest, gin.H{
 			"error":   "Invalid request",
 			"details": err.Error(),
 		})
 		return
 	}
 
-	response, err := h.authService.RefreshToken(&req)
+	response, err := h.authService.RefreshToken(&req, clientInfo(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
+		var customErr *utils.CustomError
+		if errors.As(err, &customErr) {
+			utils.HandleError(c, customErr)
+			return
+		}
+		locale.JSON(c, http.StatusInternalServerError, gin.H{
 			"error": "Failed to refresh token",
 		})
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, response)
+}
+
+func (h *AuthHandler) RequestMagicLink(c *gin.Context) {
+	var req dto.MagicLinkReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		locale.JSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	response, nonce, err := h.authService.RequestMagicLink(&req, c.ClientIP())
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// Bind the link to this browser; Lax so the cookie is sent when the
+	// emailed link is opened as a top-level navigation
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(magicLinkNonceCookie, nonce, int(h.config.GetMagicLinkTTL().Seconds()), magicLinkCookiePath, "", gin.Mode() == gin.ReleaseMode, true)
+
+	locale.JSON(c, http.StatusAccepted, response)
+}
+
+func (h *AuthHandler) ConsumeMagicLink(c *gin.Context) {
+	token := c.Query("token")
+	nonce, _ := c.Cookie(magicLinkNonceCookie)
+
+	response, err := h.authService.ConsumeMagicLink(token, nonce, clientInfo(c))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	// The nonce is single-use along with the link
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(magicLinkNonceCookie, "", -1, magicLinkCookiePath, "", gin.Mode() == gin.ReleaseMode, true)
+
+	locale.JSON(c, http.StatusOK, response)
+}
+
+// ExchangeToken is the RFC 8693 token exchange endpoint. It accepts form or
+// JSON bodies and answers with OAuth-style errors.
+func (h *AuthHandler) ExchangeToken(c *gin.Context) {
+	var req dto.TokenExchangeReq
+
+	if err := c.ShouldBind(&req); err != nil {
+		locale.JSON(c, http.StatusBadRequest, gin.H{
+			"error":             "invalid_request",
+			"error_description": err.Error(),
+		})
+		return
+	}
+
+	response, err := h.authService.ExchangeToken(&req)
+	if err != nil {
+		var oauthErr *services.OAuthError
+		if errors.As(err, &oauthErr) {
+			locale.JSON(c, oauthErr.Status, oauthErr)
+			return
+		}
+		locale.JSON(c, http.StatusInternalServerError, gin.H{
+			"error":             "server_error",
+			"error_description": "Failed to exchange token",
+		})
+		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.Header("Cache-Control", "no-store")
+	locale.JSON(c, http.StatusOK, response)
+}
+
+// clientInfo identifies the caller for session records and risk checks.
+// Clients may send their own X-Device-Fingerprint, otherwise one is derived
+// from headers that stay stable for a browser.
+func clientInfo(c *gin.Context) dto.ClientInfo {
+	userAgent := c.Request.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	fingerprint := c.GetHeader("X-Device-Fingerprint")
+	if fingerprint == "" && userAgent != "" {
+		fingerprint = userAgent + "|" + c.GetHeader("Accept-Language")
+	}
+	if fingerprint != "" {
+		hash := sha256.Sum256([]byte(fingerprint))
+		fingerprint = hex.EncodeToString(hash[:])
+	}
+
+	return dto.ClientInfo{
+		IPAddress:         c.ClientIP(),
+		UserAgent:         userAgent,
+		DeviceFingerprint: fingerprint,
+	}
 }
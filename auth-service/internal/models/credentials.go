@@ -19,15 +19,21 @@ type Credential struct {
 	RefreshTokens []RefreshToken `gorm:"foreignKey:CredentialID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
+// RefreshToken doubles as the login session, so it also records where the
+// session was started and how risky the login looked
 type RefreshToken struct {
-	ID           uint           `gorm:"primaryKey" json:"id"`
-	CredentialID uint           `gorm:"not null;index" json:"credential_id"`
-	Token        string         `gorm:"uniqueIndex;not null;size:500" json:"token"`
-	ExpiresAt    time.Time      `gorm:"not null" json:"expires_at"`
-	IsRevoked    bool           `gorm:"default:false" json:"is_revoked"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	CredentialID      uint           `gorm:"not null;index" json:"credential_id"`
+	Token             string         `gorm:"uniqueIndex;not null;size:500" json:"token"`
+	ExpiresAt         time.Time      `gorm:"not null" json:"expires_at"`
+	IsRevoked         bool           `gorm:"default:false" json:"is_revoked"`
+	IPAddress         string         `gorm:"size:45" json:"ip_address"`
+	UserAgent         string         `gorm:"size:255" json:"user_agent"`
+	DeviceFingerprint string         `gorm:"size:64" json:"device_fingerprint"`
+	RiskScore         int            `gorm:"default:0" json:"risk_score"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
 
 	Credential Credential `gorm:"foreignKey:CredentialID" json:"-"`
 }
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+type LoginEventType string
+
+const (
+	LoginEventSuccess        LoginEventType = "login_success"
+	LoginEventFailure        LoginEventType = "login_failure"
+	LoginEventStepUpRequired LoginEventType = "step_up_required"
+	LoginEventStepUpFailure  LoginEventType = "step_up_failure"
+)
+
+type LoginMethod string
+
+const (
+	LoginMethodPassword  LoginMethod = "password"
+	LoginMethodMagicLink LoginMethod = "magic_link"
+	LoginMethodStepUp    LoginMethod = "step_up"
+)
+
+// LoginEvent is an append-only record of every login attempt. CredentialID is
+// nil for attempts against unknown emails.
+type LoginEvent struct {
+	ID                uint           `gorm:"primaryKey" json:"id"`
+	CredentialID      *uint          `gorm:"index" json:"credential_id"`
+	Email             string         `gorm:"size:255;index" json:"email"`
+	EventType         LoginEventType `gorm:"size:30;not null;index" json:"event_type"`
+	Method            LoginMethod    `gorm:"size:20" json:"method"`
+	IPAddress         string         `gorm:"size:45;index" json:"ip_address"`
+	UserAgent         string         `gorm:"size:255" json:"user_agent"`
+	DeviceFingerprint string         `gorm:"size:64" json:"device_fingerprint"`
+	Country           string         `gorm:"size:2" json:"country,omitempty"`
+	City              string         `gorm:"size:100" json:"city,omitempty"`
+	Latitude          *float64       `json:"latitude,omitempty"`
+	Longitude         *float64       `json:"longitude,omitempty"`
+	RiskScore         int            `gorm:"default:0" json:"risk_score"`
+	RiskReasons       string         `gorm:"size:500" json:"risk_reasons,omitempty"`
+	CreatedAt         time.Time      `gorm:"index" json:"created_at"`
+}
+
+func (LoginEvent) TableName() string {
+	return "login_events"
+}
+
+// StepUpChallenge holds a login that passed the password check but looked
+// risky enough to need an emailed verification code before tokens are issued
+type StepUpChallenge struct {
+	ID                string     `gorm:"primaryKey;size:36" json:"id"`
+	CredentialID      uint       `gorm:"not null;index" json:"credential_id"`
+	CodeHash          string     `gorm:"not null;size:64" json:"-"`
+	IPAddress         string     `gorm:"size:45" json:"ip_address"`
+	UserAgent         string     `gorm:"size:255" json:"user_agent"`
+	DeviceFingerprint string     `gorm:"size:64" json:"device_fingerprint"`
+	RiskScore         int        `json:"risk_score"`
+	Attempts          int        `gorm:"default:0" json:"attempts"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	CompletedAt       *time.Time `json:"completed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	Credential Credential `gorm:"foreignKey:CredentialID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (StepUpChallenge) TableName() string {
+	return "step_up_challenges"
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// MagicLink is a single-use passwordless login link. Only hashes of the
+// emailed token and the browser nonce are stored.
+type MagicLink struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	CredentialID uint       `gorm:"not null;index" json:"credential_id"`
+	TokenHash    string     `gorm:"uniqueIndex;not null;size:64" json:"-"`
+	NonceHash    string     `gorm:"not null;size:64" json:"-"`
+	IPAddress    string     `gorm:"size:45" json:"ip_address"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt       *time.Time `json:"used_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+
+	Credential Credential `gorm:"foreignKey:CredentialID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (MagicLink) TableName() string {
+	return "magic_links"
+}
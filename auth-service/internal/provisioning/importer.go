@@ -0,0 +1,435 @@
+package provisioning
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"auth-service/internal/clients"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+
+	"shared/mail"
+	"shared/proto/user_service"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Row outcomes written to the report
+const (
+	StatusCreated     = "created"
+	StatusInvited     = "invited"
+	StatusExists      = "exists"
+	StatusWouldCreate = "would_create"
+	StatusWouldInvite = "would_invite"
+	StatusFailed      = "failed"
+)
+
+// maxBatchSize is the most emails BatchCreateUsers takes per call
+const maxBatchSize = 100
+
+type Options struct {
+	DryRun     bool
+	Invite     bool // email an invite to rows without a password
+	BatchSize  int  // rows looked up and created per query and RPC, at most 100
+	Resume     bool // skip rows the report already marks as done
+	ReportPath string
+	LoginURL   string // included in invite emails
+}
+
+// ReportFile is where the report is written. A dry run writes next to
+// ReportPath rather than over it, the real report is what Resume reads.
+func (o Options) ReportFile() string {
+	if !o.DryRun {
+		return o.ReportPath
+	}
+	ext := filepath.Ext(o.ReportPath)
+	return strings.TrimSuffix(o.ReportPath, ext) + ".dry-run" + ext
+}
+
+type Summary struct {
+	Total   int
+	Done    int // created, invited or already existing
+	Skipped int // finished in a previous run
+	Failed  int
+}
+
+type row struct {
+	number   int // line number in the CSV, header is line 1
+	email    string
+	password string
+}
+
+type Importer struct {
+	credentialRepo    repository.CredentialRepository
+	userServiceClient *clients.UserServiceClient
+	mailer            mail.Sender
+	options           Options
+}
+
+func NewImporter(credentialRepo repository.CredentialRepository, userServiceClient *clients.UserServiceClient, mailer mail.Sender, options Options) *Importer {
+	if options.BatchSize <= 0 {
+		options.BatchSize = 50
+	}
+	options.BatchSize = min(options.BatchSize, maxBatchSize)
+
+	return &Importer{
+		credentialRepo:    credentialRepo,
+		userServiceClient: userServiceClient,
+		mailer:            mailer,
+		options:           options,
+	}
+}
+
+// Import provisions every row of the CSV at path. The CSV needs an email
+// column and may have a password column. Each row's outcome is appended to
+// the report, which a later run with Resume uses to skip finished rows. A
+// dry run reads the report but writes its own, see Options.ReportFile.
+func (i *Importer) Import(ctx context.Context, path string) (*Summary, error) {
+	rows, err := readRows(path)
+	if err != nil {
+		return nil, err
+	}
+
+	finished := map[int]bool{}
+	if i.options.Resume {
+		if finished, err = readFinishedRows(i.options.ReportPath); err != nil {
+			return nil, err
+		}
+	}
+
+	reportFile, report, err := openReport(i.options.ReportFile(), i.options.Resume && !i.options.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	defer reportFile.Close()
+	defer report.Flush()
+
+	summary := &Summary{Total: len(rows)}
+	seen := map[string]int{}
+
+	var pending []row
+	for _, r := range rows {
+		if finished[r.number] {
+			summary.Skipped++
+			continue
+		}
+
+		if err := i.validate(r, seen); err != nil {
+			i.record(report, summary, r, StatusFailed, err.Error())
+			continue
+		}
+
+		pending = append(pending, r)
+		if len(pending) == i.options.BatchSize {
+			if err := i.processBatch(ctx, pending, report, summary); err != nil {
+				return summary, err
+			}
+			pending = nil
+		}
+	}
+
+	if len(pending) > 0 {
+		if err := i.processBatch(ctx, pending, report, summary); err != nil {
+			return summary, err
+		}
+	}
+
+	return summary, nil
+}
+
+func (i *Importer) validate(r row, seen map[string]int) error {
+	if _, err := netmail.ParseAddress(r.email); err != nil {
+		return fmt.Errorf("invalid email")
+	}
+	// Emails are unique regardless of case, see processBatch
+	key := strings.ToLower(r.email)
+	if first, ok := seen[key]; ok {
+		return fmt.Errorf("duplicate of row %d", first)
+	}
+	seen[key] = r.number
+
+	if r.password == "" && !i.options.Invite {
+		return fmt.Errorf("password is required unless --invite is set")
+	}
+	if r.password != "" && len(r.password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	return nil
+}
+
+// processBatch provisions a batch with one credential lookup, one insert
+// for the new credentials and one BatchCreateUsers call, only invites go
+// out one by one. It is safe to repeat: a credential left behind by a
+// failed run only gets its missing user record created, and its invite
+// sent again if it was never given a password.
+func (i *Importer) processBatch(ctx context.Context, batch []row, report *csv.Writer, summary *Summary) error {
+	emails := make([]string, len(batch))
+	for n, r := range batch {
+		emails[n] = r.email
+	}
+
+	existing, err := i.credentialRepo.GetByEmails(emails)
+	if err != nil {
+		return fmt.Errorf("failed to look up existing credentials: %v", err)
+	}
+	existingByEmail := make(map[string]models.Credential, len(existing))
+	for _, credential := range existing {
+		existingByEmail[strings.ToLower(credential.Email)] = credential
+	}
+	exists := func(r row) bool {
+		_, ok := existingByEmail[strings.ToLower(r.email)]
+		return ok
+	}
+	// An invited user whose invite failed has no password and no other way
+	// to sign in, so the invite goes out again
+	needsInvite := func(r row) bool {
+		credential, ok := existingByEmail[strings.ToLower(r.email)]
+		if !ok {
+			return r.password == ""
+		}
+		return credential.Password == "" && i.options.Invite
+	}
+
+	if i.options.DryRun {
+		for _, r := range batch {
+			switch {
+			case needsInvite(r):
+				i.record(report, summary, r, StatusWouldInvite, "")
+			case exists(r):
+				i.record(report, summary, r, StatusExists, "")
+			default:
+				i.record(report, summary, r, StatusWouldCreate, "")
+			}
+		}
+		report.Flush()
+		return report.Error()
+	}
+
+	// Row number to why it failed
+	failures := map[int]string{}
+
+	var credentials []models.Credential
+	var created []row
+	for _, r := range batch {
+		if exists(r) {
+			continue
+		}
+		credential, err := newCredential(r)
+		if err != nil {
+			failures[r.number] = err.Error()
+			continue
+		}
+		credentials = append(credentials, *credential)
+		created = append(created, r)
+	}
+	if len(credentials) > 0 {
+		if err := i.credentialRepo.CreateBatch(credentials); err != nil {
+			for _, r := range created {
+				failures[r.number] = fmt.Sprintf("failed to create credential: %v", err)
+			}
+		}
+	}
+
+	// Existing credentials too, their user record may be what a failed run
+	// left out
+	var userEmails []string
+	for _, r := range batch {
+		if _, failed := failures[r.number]; !failed {
+			userEmails = append(userEmails, r.email)
+		}
+	}
+	if len(userEmails) > 0 {
+		_, err := i.userServiceClient.BatchCreateUsers(ctx, &user_service.BatchCreateUsersRequest{Emails: userEmails})
+		if err != nil {
+			for _, r := range batch {
+				if _, failed := failures[r.number]; !failed {
+					failures[r.number] = fmt.Sprintf("failed to create user record: %v", err)
+				}
+			}
+		}
+	}
+
+	for _, r := range batch {
+		if message, failed := failures[r.number]; failed {
+			i.record(report, summary, r, StatusFailed, message)
+			continue
+		}
+		switch {
+		case needsInvite(r):
+			if err := i.sendInvite(ctx, r.email); err != nil {
+				i.record(report, summary, r, StatusFailed, err.Error())
+				continue
+			}
+			i.record(report, summary, r, StatusInvited, "")
+		case exists(r):
+			i.record(report, summary, r, StatusExists, "")
+		default:
+			i.record(report, summary, r, StatusCreated, "")
+		}
+	}
+
+	report.Flush()
+	return report.Error()
+}
+
+// newCredential hashes the row's password. Invited users sign in with a
+// magic link, so their credential has no password hash and password login
+// always fails for them.
+func newCredential(r row) (*models.Credential, error) {
+	credential := &models.Credential{
+		Email:    r.email,
+		IsActive: true,
+	}
+	if r.password == "" {
+		return credential, nil
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(r.password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %v", err)
+	}
+	credential.Password = string(hashedPassword)
+	return credential, nil
+}
+
+func (i *Importer) sendInvite(ctx context.Context, email string) error {
+	msg := mail.Message{
+		To:      email,
+		Subject: "Your account is ready",
+		Body: fmt.Sprintf("An account has been created for you with this email address.\n\nTo sign in, open %s and choose \"Email me a login link\". No password is needed.\n",
+			i.options.LoginURL),
+	}
+	if err := i.mailer.Send(ctx, msg); err != nil {
+		return fmt.Errorf("account created but invite email failed: %v", err)
+	}
+	return nil
+}
+
+func (i *Importer) record(report *csv.Writer, summary *Summary, r row, status string, message string) {
+	if status == StatusFailed {
+		summary.Failed++
+		log.Printf("Row %d (%s): %s", r.number, r.email, message)
+	} else {
+		summary.Done++
+	}
+
+	report.Write([]string{strconv.Itoa(r.number), r.email, status, message})
+}
+
+// -----------------------
+// -- Helper functions --
+// -----------------------
+
+func readRows(path string) ([]row, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+
+	emailCol, passwordCol := -1, -1
+	for n, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "email":
+			emailCol = n
+		case "password":
+			passwordCol = n
+		}
+	}
+	if emailCol < 0 {
+		return nil, fmt.Errorf("CSV header must contain an email column")
+	}
+
+	var rows []row
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %v", line, err)
+		}
+
+		r := row{number: line}
+		if emailCol < len(record) {
+			r.email = strings.TrimSpace(record[emailCol])
+		}
+		if passwordCol >= 0 && passwordCol < len(record) {
+			r.password = record[passwordCol]
+		}
+		rows = append(rows, r)
+	}
+
+	return rows, nil
+}
+
+func readFinishedRows(reportPath string) (map[int]bool, error) {
+	finished := map[int]bool{}
+
+	file, err := os.Open(reportPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return finished, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %v", err)
+	}
+
+	for _, record := range records {
+		if len(record) < 3 {
+			continue
+		}
+		number, err := strconv.Atoi(record[0])
+		if err != nil {
+			continue // header
+		}
+		switch record[2] {
+		case StatusCreated, StatusInvited, StatusExists:
+			finished[number] = true
+		}
+	}
+
+	return finished, nil
+}
+
+func openReport(reportPath string, appendToExisting bool) (*os.File, *csv.Writer, error) {
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendToExisting {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(reportPath, flags, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open report: %v", err)
+	}
+
+	writer := csv.NewWriter(file)
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		writer.Write([]string{"row", "email", "status", "message"})
+	}
+
+	return file, writer, nil
+}
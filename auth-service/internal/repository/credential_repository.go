@@ -8,7 +8,9 @@ import (
 
 type CredentialRepository interface {
 	Create(credential *models.Credential) error
+	CreateBatch(credentials []models.Credential) error
 	GetByEmail(email string) (*models.Credential, error)
+	GetByEmails(emails []string) ([]models.Credential, error)
 	GetByID(id uint) (*models.Credential, error)
 	Update(credential *models.Credential) error
 	Delete(id uint) error
@@ -16,6 +18,7 @@ type CredentialRepository interface {
 	GetRefreshTokenByToken(token string) (*models.RefreshToken, error)
 	RevokeRefreshToken(token string) error
 	RevokeAllRefreshTokensForCredential(credentialID uint) error
+	GetRefreshTokensForCredential(credentialID uint) ([]models.RefreshToken, error)
 }
 
 type credentialRepository struct {
@@ -30,6 +33,11 @@ func (r *credentialRepository) Create(credential *models.Credential) error {
 	return r.db.Create(credential).Error
 }
 
+// CreateBatch inserts credentials in one statement, all or none
+func (r *credentialRepository) CreateBatch(credentials []models.Credential) error {
+	return r.db.Create(&credentials).Error
+}
+
 func (r *credentialRepository) GetByEmail(email string) (*models.Credential, error) {
 	var credential models.Credential
 	err := r.db.Where("email = ?", email).First(&credential).Error
@@ -39,6 +47,14 @@ func (r *credentialRepository) GetByEmail(email string) (*models.Credential, err
 	return &credential, nil
 }
 
+func (r *credentialRepository) GetByEmails(emails []string) ([]models.Credential, error) {
+	var credentials []models.Credential
+	if err := r.db.Where("email IN ?", emails).Find(&credentials).Error; err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
 func (r *credentialRepository) GetByID(id uint) (*models.Credential, error) {
 	var credential models.Credential
 	err := r.db.First(&credential, id).Error
@@ -80,3 +96,9 @@ func (r *credentialRepository) RevokeAllRefreshTokensForCredential(credentialID
 		Where("credential_id = ?", credentialID).
 		Update("is_revoked", true).Error
 }
+
+func (r *credentialRepository) GetRefreshTokensForCredential(credentialID uint) ([]models.RefreshToken, error) {
+	var tokens []models.RefreshToken
+	err := r.db.Where("credential_id = ?", credentialID).Order("created_at").Find(&tokens).Error
+	return tokens, err
+}
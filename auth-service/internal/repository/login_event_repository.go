@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"auth-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LoginEventRepository interface {
+	Create(event *models.LoginEvent) error
+	CountSuccessful(credentialID uint) (int64, error)
+	HasSuccessfulWithFingerprint(credentialID uint, fingerprint string) (bool, error)
+	GetRecentSuccessfulIPs(credentialID uint, limit int) ([]string, error)
+	GetLastSuccessfulWithLocation(credentialID uint) (*models.LoginEvent, error)
+	CountDistinctFailedEmailsByIP(ipAddress string, since time.Time) (int64, error)
+	ListForCredential(credentialID uint, email string) ([]models.LoginEvent, error)
+}
+
+type loginEventRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginEventRepository(db *gorm.DB) LoginEventRepository {
+	return &loginEventRepository{db: db}
+}
+
+func (r *loginEventRepository) Create(event *models.LoginEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *loginEventRepository) CountSuccessful(credentialID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LoginEvent{}).
+		Where("credential_id = ? AND event_type = ?", credentialID, models.LoginEventSuccess).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *loginEventRepository) HasSuccessfulWithFingerprint(credentialID uint, fingerprint string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.LoginEvent{}).
+		Where("credential_id = ? AND event_type = ? AND device_fingerprint = ?", credentialID, models.LoginEventSuccess, fingerprint).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *loginEventRepository) GetRecentSuccessfulIPs(credentialID uint, limit int) ([]string, error) {
+	var ips []string
+	err := r.db.Model(&models.LoginEvent{}).
+		Where("credential_id = ? AND event_type = ?", credentialID, models.LoginEventSuccess).
+		Group("ip_address").
+		Order("MAX(created_at) DESC").
+		Limit(limit).
+		Pluck("ip_address", &ips).Error
+	return ips, err
+}
+
+func (r *loginEventRepository) GetLastSuccessfulWithLocation(credentialID uint) (*models.LoginEvent, error) {
+	var event models.LoginEvent
+	err := r.db.Where("credential_id = ? AND event_type = ? AND latitude IS NOT NULL", credentialID, models.LoginEventSuccess).
+		Order("created_at DESC").
+		First(&event).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *loginEventRepository) CountDistinctFailedEmailsByIP(ipAddress string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.LoginEvent{}).
+		Where("ip_address = ? AND event_type = ? AND created_at >= ?", ipAddress, models.LoginEventFailure, since).
+		Distinct("email").
+		Count(&count).Error
+	return count, err
+}
+
+// ListForCredential returns every login attempt for the credential,
+// including failed attempts made before it existed under the same email
+func (r *loginEventRepository) ListForCredential(credentialID uint, email string) ([]models.LoginEvent, error) {
+	var events []models.LoginEvent
+	err := r.db.Where("credential_id = ? OR email = ?", credentialID, email).
+		Order("created_at").
+		Find(&events).Error
+	return events, err
+}
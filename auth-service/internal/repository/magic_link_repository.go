@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"time"
+
+	"auth-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type MagicLinkRepository interface {
+	Create(link *models.MagicLink) error
+	GetByTokenHash(tokenHash string) (*models.MagicLink, error)
+	MarkUsed(id uint) (bool, error)
+}
+
+type magicLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkRepository(db *gorm.DB) MagicLinkRepository {
+	return &magicLinkRepository{db: db}
+}
+
+func (r *magicLinkRepository) Create(link *models.MagicLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *magicLinkRepository) GetByTokenHash(tokenHash string) (*models.MagicLink, error) {
+	var link models.MagicLink
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// MarkUsed consumes the link and reports whether this call was the one that
+// consumed it, so two concurrent requests can never both succeed
+func (r *magicLinkRepository) MarkUsed(id uint) (bool, error) {
+	result := r.db.Model(&models.MagicLink{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
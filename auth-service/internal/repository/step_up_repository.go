@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"time"
+
+	"auth-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type StepUpRepository interface {
+	Create(challenge *models.StepUpChallenge) error
+	GetByID(id string) (*models.StepUpChallenge, error)
+	IncrementAttempts(id string) error
+	MarkCompleted(id string) (bool, error)
+}
+
+type stepUpRepository struct {
+	db *gorm.DB
+}
+
+func NewStepUpRepository(db *gorm.DB) StepUpRepository {
+	return &stepUpRepository{db: db}
+}
+
+func (r *stepUpRepository) Create(challenge *models.StepUpChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *stepUpRepository) GetByID(id string) (*models.StepUpChallenge, error) {
+	var challenge models.StepUpChallenge
+	if err := r.db.Where("id = ?", id).First(&challenge).Error; err != nil {
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+func (r *stepUpRepository) IncrementAttempts(id string) error {
+	return r.db.Model(&models.StepUpChallenge{}).
+		Where("id = ?", id).
+		Update("attempts", gorm.Expr("attempts + 1")).Error
+}
+
+// MarkCompleted reports whether this call completed the challenge, so a code
+// can only ever be redeemed once
+func (r *stepUpRepository) MarkCompleted(id string) (bool, error) {
+	result := r.db.Model(&models.StepUpChallenge{}).
+		Where("id = ? AND completed_at IS NULL", id).
+		Update("completed_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
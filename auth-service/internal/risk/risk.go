@@ -0,0 +1,86 @@
+package risk
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"auth-service/internal/geoip"
+)
+
+// Attempt is a login that passed authentication and is being scored
+type Attempt struct {
+	CredentialID      uint
+	Email             string
+	IPAddress         string
+	UserAgent         string
+	DeviceFingerprint string
+	Location          *geoip.Location // nil when the IP can't be located
+	Time              time.Time
+}
+
+// Signal is one suspicious finding and how much it adds to the risk score
+type Signal struct {
+	Rule   string `json:"rule"`
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+// Rule is a pluggable detector. It returns a nil signal when the attempt
+// looks normal to it.
+type Rule interface {
+	Name() string
+	Evaluate(attempt *Attempt) (*Signal, error)
+}
+
+type Assessment struct {
+	Score   int      `json:"score"`
+	Signals []Signal `json:"signals"`
+}
+
+// MaxScore caps the combined score of all signals
+const MaxScore = 100
+
+// Reasons joins the signal reasons for storage and emails
+func (a *Assessment) Reasons() string {
+	reasons := make([]string, 0, len(a.Signals))
+	for _, signal := range a.Signals {
+		reasons = append(reasons, signal.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules}
+}
+
+// Assess runs every rule and sums their scores. A failing rule is logged and
+// skipped so detection problems never block logins.
+func (e *Engine) Assess(attempt *Attempt) *Assessment {
+	assessment := &Assessment{}
+
+	for _, rule := range e.rules {
+		signal, err := rule.Evaluate(attempt)
+		if err != nil {
+			log.Printf("Risk rule %s failed: %v", rule.Name(), err)
+			continue
+		}
+		if signal == nil {
+			continue
+		}
+
+		signal.Rule = rule.Name()
+		assessment.Signals = append(assessment.Signals, *signal)
+		assessment.Score += signal.Score
+	}
+
+	if assessment.Score > MaxScore {
+		assessment.Score = MaxScore
+	}
+
+	return assessment
+}
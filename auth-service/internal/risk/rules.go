@@ -0,0 +1,187 @@
+package risk
+
+import (
+	"fmt"
+	"net/netip"
+	"time"
+
+	"auth-service/internal/geoip"
+	"auth-service/internal/repository"
+)
+
+// -----------------------
+// -- New device --
+// -----------------------
+
+type newDeviceRule struct {
+	events repository.LoginEventRepository
+	score  int
+}
+
+// NewDeviceRule flags logins from a device fingerprint never seen on a
+// successful login for the account. First-ever logins are not flagged.
+func NewDeviceRule(events repository.LoginEventRepository, score int) Rule {
+	return &newDeviceRule{events: events, score: score}
+}
+
+func (r *newDeviceRule) Name() string {
+	return "new_device"
+}
+
+func (r *newDeviceRule) Evaluate(attempt *Attempt) (*Signal, error) {
+	if attempt.DeviceFingerprint == "" {
+		return nil, nil
+	}
+
+	previous, err := r.events.CountSuccessful(attempt.CredentialID)
+	if err != nil || previous == 0 {
+		return nil, err
+	}
+
+	known, err := r.events.HasSuccessfulWithFingerprint(attempt.CredentialID, attempt.DeviceFingerprint)
+	if err != nil || known {
+		return nil, err
+	}
+
+	return &Signal{Score: r.score, Reason: "login from a new device"}, nil
+}
+
+// -----------------------
+// -- New IP range --
+// -----------------------
+
+type newIPRangeRule struct {
+	events  repository.LoginEventRepository
+	score   int
+	history int
+}
+
+// NewIPRangeRule flags logins from outside every network (/24 for IPv4, /48
+// for IPv6) the account recently logged in from
+func NewIPRangeRule(events repository.LoginEventRepository, score int) Rule {
+	return &newIPRangeRule{events: events, score: score, history: 20}
+}
+
+func (r *newIPRangeRule) Name() string {
+	return "new_ip_range"
+}
+
+func (r *newIPRangeRule) Evaluate(attempt *Attempt) (*Signal, error) {
+	current, ok := ipPrefix(attempt.IPAddress)
+	if !ok {
+		return nil, nil
+	}
+
+	ips, err := r.events.GetRecentSuccessfulIPs(attempt.CredentialID, r.history)
+	if err != nil || len(ips) == 0 {
+		return nil, err
+	}
+
+	for _, ip := range ips {
+		if prefix, ok := ipPrefix(ip); ok && prefix == current {
+			return nil, nil
+		}
+	}
+
+	return &Signal{Score: r.score, Reason: "login from a new network " + current.String()}, nil
+}
+
+func ipPrefix(ip string) (netip.Prefix, bool) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+
+	bits := 48
+	if addr.Is4() {
+		bits = 24
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	return prefix, true
+}
+
+// -----------------------
+// -- Impossible travel --
+// -----------------------
+
+type impossibleTravelRule struct {
+	events      repository.LoginEventRepository
+	maxSpeedKmh float64
+	score       int
+}
+
+// ImpossibleTravelRule flags logins that would require travelling faster than
+// maxSpeedKmh since the previous located login. Needs a GeoIP database.
+func ImpossibleTravelRule(events repository.LoginEventRepository, maxSpeedKmh float64, score int) Rule {
+	return &impossibleTravelRule{events: events, maxSpeedKmh: maxSpeedKmh, score: score}
+}
+
+func (r *impossibleTravelRule) Name() string {
+	return "impossible_travel"
+}
+
+func (r *impossibleTravelRule) Evaluate(attempt *Attempt) (*Signal, error) {
+	if attempt.Location == nil {
+		return nil, nil
+	}
+
+	last, err := r.events.GetLastSuccessfulWithLocation(attempt.CredentialID)
+	if err != nil || last == nil {
+		return nil, err
+	}
+
+	previous := geoip.Location{Latitude: *last.Latitude, Longitude: *last.Longitude}
+	distance := geoip.DistanceKm(previous, *attempt.Location)
+
+	// Nearby logins are normal no matter how quick
+	if distance < 100 {
+		return nil, nil
+	}
+
+	hours := attempt.Time.Sub(last.CreatedAt).Hours()
+	if hours > 0 && distance/hours <= r.maxSpeedKmh {
+		return nil, nil
+	}
+
+	return &Signal{
+		Score:  r.score,
+		Reason: fmt.Sprintf("login %.0f km from %s %s within %s", distance, last.City, last.Country, attempt.Time.Sub(last.CreatedAt).Round(time.Minute)),
+	}, nil
+}
+
+// -----------------------
+// -- Failure burst --
+// -----------------------
+
+type failureBurstRule struct {
+	events    repository.LoginEventRepository
+	threshold int64
+	window    time.Duration
+	score     int
+}
+
+// FailureBurstRule flags logins from an IP that recently failed logins
+// against many different accounts, the pattern of credential stuffing
+func FailureBurstRule(events repository.LoginEventRepository, threshold int, window time.Duration, score int) Rule {
+	return &failureBurstRule{events: events, threshold: int64(threshold), window: window, score: score}
+}
+
+func (r *failureBurstRule) Name() string {
+	return "failure_burst"
+}
+
+func (r *failureBurstRule) Evaluate(attempt *Attempt) (*Signal, error) {
+	accounts, err := r.events.CountDistinctFailedEmailsByIP(attempt.IPAddress, attempt.Time.Add(-r.window))
+	if err != nil || accounts < r.threshold {
+		return nil, err
+	}
+
+	return &Signal{
+		Score:  r.score,
+		Reason: fmt.Sprintf("%d accounts failed to log in from this IP in the last %s", accounts, r.window),
+	}, nil
+}
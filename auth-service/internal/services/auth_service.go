@@ -2,17 +2,30 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"strings"
 	"time"
 
 	"auth-service/internal/clients"
 	"auth-service/internal/dto"
+	"auth-service/internal/geoip"
 	"auth-service/internal/models"
 	"auth-service/internal/repository"
+	"auth-service/internal/risk"
 	"auth-service/pkg/config"
 
+	"shared/mail"
 	"shared/proto/user_service"
+	"shared/ratelimit"
+	"shared/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -21,32 +34,62 @@ import (
 )
 
 type AuthService interface {
-	Register(req *dto.AuthReq) (*dto.AuthRes, error)
-	Login(req *dto.AuthReq) (*dto.AuthRes, error)
-	RefreshToken(req *dto.RefreshTokenReq) (*dto.RefreshTokenRes, error)
+	Register(req *dto.AuthReq, client dto.ClientInfo) (*dto.AuthRes, error)
+	Login(req *dto.AuthReq, client dto.ClientInfo) (*dto.AuthRes, error)
+	VerifyStepUp(req *dto.StepUpVerifyReq, client dto.ClientInfo) (*dto.AuthRes, error)
+	RefreshToken(req *dto.RefreshTokenReq, client dto.ClientInfo) (*dto.RefreshTokenRes, error)
+	RequestMagicLink(req *dto.MagicLinkReq, clientIP string) (*dto.MagicLinkRes, string, error)
+	ConsumeMagicLink(token string, nonce string, client dto.ClientInfo) (*dto.AuthRes, error)
+	ExchangeToken(req *dto.TokenExchangeReq) (*dto.TokenExchangeRes, error)
 }
 
 type Claims struct {
 	Email  string `json:"email"`
 	UserID uint   `json:"user_id"`
+	Scope  string `json:"scope,omitempty"` // empty means unrestricted user access
+	// Locale and Timezone come from the user's preferences when the token is issued
+	Locale   string `json:"locale,omitempty"`
+	Timezone string `json:"tz,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type authService struct {
 	credentialRepo    repository.CredentialRepository
+	magicLinkRepo     repository.MagicLinkRepository
+	loginEventRepo    repository.LoginEventRepository
+	stepUpRepo        repository.StepUpRepository
 	userServiceClient *clients.UserServiceClient
+	mailer            mail.Sender
+	riskEngine        *risk.Engine
+	geo               geoip.Resolver // nil when no GeoIP database is configured
 	config            *config.Config
+
+	magicLinkLimiters MagicLinkLimiters
+}
+
+// MagicLinkLimiters throttle magic link requests per email and per client IP
+type MagicLinkLimiters struct {
+	Email ratelimit.Limiter
+	IP    ratelimit.Limiter
 }
 
-func NewAuthService(credentialRepo repository.CredentialRepository, userServiceClient *clients.UserServiceClient, config *config.Config) AuthService {
+func NewAuthService(credentialRepo repository.CredentialRepository, magicLinkRepo repository.MagicLinkRepository, loginEventRepo repository.LoginEventRepository, stepUpRepo repository.StepUpRepository, userServiceClient *clients.UserServiceClient, mailer mail.Sender, magicLinkLimiters MagicLinkLimiters, riskEngine *risk.Engine, geo geoip.Resolver, config *config.Config) AuthService {
 	return &authService{
 		credentialRepo:    credentialRepo,
+		magicLinkRepo:     magicLinkRepo,
+		loginEventRepo:    loginEventRepo,
+		stepUpRepo:        stepUpRepo,
 		userServiceClient: userServiceClient,
+		mailer:            mailer,
+		riskEngine:        riskEngine,
+		geo:               geo,
 		config:            config,
+
+		magicLinkLimiters: magicLinkLimiters,
 	}
 }
 
-func (s *authService) Register(req *dto.AuthReq) (*dto.AuthRes, error) {
+func (s *authService) Register(req *dto.AuthReq, client dto.ClientInfo) (*dto.AuthRes, error) {
 	existingCredential, err := s.credentialRepo.GetByEmail(req.Email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, fmt.Errorf("failed to check existing email: %v", err)
@@ -76,11 +119,14 @@ func (s *authService) Register(req *dto.AuthReq) (*dto.AuthRes, error) {
 	}
 
 	// Generate both tokens
-	accessToken, refreshToken, expiresAt, err := s.generateTokenPair(credential.Email, userID, credential.ID)
+	accessToken, refreshToken, expiresAt, err := s.generateTokenPair(credential.Email, userID, credential.ID, client, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %v", err)
 	}
 
+	// Start the login history so the first device isn't flagged as new
+	s.recordLoginEvent(&credential.ID, credential.Email, models.LoginEventSuccess, models.LoginMethodPassword, client, &risk.Assessment{})
+
 	return &dto.AuthRes{
 		ID:           credential.ID,
 		Email:        credential.Email,
@@ -91,42 +137,39 @@ func (s *authService) Register(req *dto.AuthReq) (*dto.AuthRes, error) {
 	}, nil
 }
 
-func (s *authService) Login(req *dto.AuthReq) (*dto.AuthRes, error) {
+func (s *authService) Login(req *dto.AuthReq, client dto.ClientInfo) (*dto.AuthRes, error) {
 	existingCredential, err := s.credentialRepo.GetByEmail(req.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get credential: %v", err)
-	}
-	if existingCredential == nil {
-		return nil, errors.New("invalid credentials")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.recordLoginEvent(nil, req.Email, models.LoginEventFailure, models.LoginMethodPassword, client, &risk.Assessment{})
+			return nil, utils.Unauthorized("Invalid credentials")
+		}
+		return nil, utils.InternalServerError("Failed to get credential")
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(existingCredential.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+		s.recordLoginEvent(&existingCredential.ID, existingCredential.Email, models.LoginEventFailure, models.LoginMethodPassword, client, &risk.Assessment{})
+		return nil, utils.Unauthorized("Invalid credentials")
+	}
+	if !existingCredential.IsActive {
+		return nil, utils.Unauthorized("Account is disabled")
 	}
 
-	// Get user ID from user-service
-	userID, err := s.getUserIDFromUserService(existingCredential.Email)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user ID: %v", err)
+	assessment := s.assessLogin(existingCredential, client)
+	if s.config.RiskStepUpEnabled && assessment.Score >= s.config.RiskStepUpThreshold {
+		return s.startStepUp(existingCredential, client, assessment)
 	}
 
-	// Generate both tokens
-	accessToken, refreshToken, expiresAt, err := s.generateTokenPair(existingCredential.Email, userID, existingCredential.ID)
+	// Get user ID from user-service
+	userID, err := s.getUserIDFromUserService(existingCredential.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate tokens: %v", err)
+		return nil, err
 	}
 
-	return &dto.AuthRes{
-		ID:           existingCredential.ID,
-		Email:        existingCredential.Email,
-		Message:      "Login successful",
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresAt:    expiresAt,
-	}, nil
+	return s.completeLogin(existingCredential, userID, models.LoginMethodPassword, client, assessment)
 }
 
-func (s *authService) RefreshToken(req *dto.RefreshTokenReq) (*dto.RefreshTokenRes, error) {
+func (s *authService) RefreshToken(req *dto.RefreshTokenReq, client dto.ClientInfo) (*dto.RefreshTokenRes, error) {
 	oldRefreshToken, err := s.credentialRepo.GetRefreshTokenByToken(req.RefreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get refresh token: %v", err)
@@ -149,11 +192,11 @@ func (s *authService) RefreshToken(req *dto.RefreshTokenReq) (*dto.RefreshTokenR
 	// Get user ID from user-service
 	userID, err := s.getUserIDFromUserService(credential.Email)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user ID: %v", err)
+		return nil, err
 	}
 
-	// Generate both tokens
-	accessToken, refreshToken, expiresAt, err := s.generateTokenPair(credential.Email, userID, credential.ID)
+	// Generate both tokens, the session keeps the risk score of its login
+	accessToken, refreshToken, expiresAt, err := s.generateTokenPair(credential.Email, userID, credential.ID, client, oldRefreshToken.RiskScore)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate tokens: %v", err)
 	}
@@ -165,6 +208,118 @@ func (s *authService) RefreshToken(req *dto.RefreshTokenReq) (*dto.RefreshTokenR
 	}, nil
 }
 
+// RequestMagicLink emails a single-use login link and returns the nonce that
+// must be stored in the requesting browser. The response is identical whether
+// or not the email is registered so it can't be used to enumerate accounts.
+func (s *authService) RequestMagicLink(req *dto.MagicLinkReq, clientIP string) (*dto.MagicLinkRes, string, error) {
+	ctx := context.Background()
+
+	if err := s.checkMagicLinkLimits(ctx, req.Email, clientIP); err != nil {
+		return nil, "", err
+	}
+
+	nonce, err := generateSecureToken()
+	if err != nil {
+		return nil, "", utils.InternalServerError("Failed to generate magic link")
+	}
+
+	expiresAt := time.Now().Add(s.config.GetMagicLinkTTL())
+	response := &dto.MagicLinkRes{
+		Message:   "If an account exists for this email, a login link has been sent",
+		ExpiresAt: expiresAt.Unix(),
+	}
+
+	credential, err := s.credentialRepo.GetByEmail(req.Email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response, nonce, nil
+		}
+		return nil, "", utils.InternalServerError("Failed to get credential")
+	}
+	if !credential.IsActive {
+		return response, nonce, nil
+	}
+
+	token, err := generateSecureToken()
+	if err != nil {
+		return nil, "", utils.InternalServerError("Failed to generate magic link")
+	}
+
+	link := &models.MagicLink{
+		CredentialID: credential.ID,
+		TokenHash:    hashToken(token),
+		NonceHash:    hashToken(nonce),
+		IPAddress:    clientIP,
+		ExpiresAt:    expiresAt,
+	}
+	if err := s.magicLinkRepo.Create(link); err != nil {
+		return nil, "", utils.InternalServerError("Failed to create magic link")
+	}
+
+	msg := mail.Message{
+		To:      credential.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf("Click the link below to log in. It expires in %d minutes and can only be used once, from the browser that requested it.\n\n%s\n\nIf you didn't request this, you can ignore this email.\n",
+			s.config.MagicLinkTTLMinutes, s.buildMagicLinkURL(token)),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send magic link email: %v", err)
+		return nil, "", utils.InternalServerError("Failed to send login link")
+	}
+
+	return response, nonce, nil
+}
+
+// ConsumeMagicLink exchanges a magic link token for a normal token pair.
+// The nonce is checked before the link is marked used so that mail scanners
+// prefetching the URL without the browser cookie can't burn the link.
+func (s *authService) ConsumeMagicLink(token string, nonce string, client dto.ClientInfo) (*dto.AuthRes, error) {
+	if token == "" {
+		return nil, utils.BadRequest("Login link token is required")
+	}
+
+	link, err := s.magicLinkRepo.GetByTokenHash(hashToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.Unauthorized("Invalid or expired login link")
+		}
+		return nil, utils.InternalServerError("Failed to get login link")
+	}
+	if link.UsedAt != nil || link.ExpiresAt.Before(time.Now()) {
+		return nil, utils.Unauthorized("Invalid or expired login link")
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(hashToken(nonce)), []byte(link.NonceHash)) != 1 {
+		return nil, utils.Unauthorized("Login link must be opened in the browser that requested it")
+	}
+
+	consumed, err := s.magicLinkRepo.MarkUsed(link.ID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to consume login link")
+	}
+	if !consumed {
+		return nil, utils.Unauthorized("Invalid or expired login link")
+	}
+
+	credential, err := s.credentialRepo.GetByID(link.CredentialID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get credential")
+	}
+	if !credential.IsActive {
+		return nil, utils.Unauthorized("Account is disabled")
+	}
+
+	userID, err := s.getUserIDFromUserService(credential.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	// Opening the emailed link already proves mailbox access, so risky magic
+	// link logins are reported but never need step-up verification
+	assessment := s.assessLogin(credential, client)
+
+	return s.completeLogin(credential, userID, models.LoginMethodMagicLink, client, assessment)
+}
+
 // -----------------------
 // -- Helper functions --
 // -----------------------
@@ -183,6 +338,8 @@ func (s *authService) createUserInUserService(email string) (uint, error) {
 	return uint(response.Id), nil
 }
 
+// getUserIDFromUserService also refuses users an admin suspended or banned,
+// so they can neither log in nor refresh their tokens
 func (s *authService) getUserIDFromUserService(email string) (uint, error) {
 	grpcReq := &user_service.GetUserByEmailRequest{
 		Email: email,
@@ -191,24 +348,49 @@ func (s *authService) getUserIDFromUserService(email string) (uint, error) {
 	ctx := context.Background()
 	response, err := s.userServiceClient.GetUserByEmail(ctx, grpcReq)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get user by email via gRPC: %v", err)
+		log.Printf("Failed to get user by email via gRPC: %v", err)
+		return 0, utils.InternalServerError("Failed to get user ID")
+	}
+
+	switch response.Status {
+	case "suspended":
+		return 0, utils.Forbidden("Account is suspended")
+	case "banned":
+		return 0, utils.Forbidden("Account is banned")
 	}
 
 	return uint(response.Id), nil
 }
 
-func (s *authService) generateTokenPair(email string, userID uint, credentialID uint) (string, string, int64, error) {
+// getUserLocale is best effort, a token without locale claims falls back
+// to Accept-Language and UTC in the other services
+func (s *authService) getUserLocale(userID uint) (string, string) {
+	response, err := s.userServiceClient.GetUserPreferences(context.Background(), &user_service.GetUserPreferencesRequest{
+		UserId: uint32(userID),
+	})
+	if err != nil {
+		log.Printf("Failed to get preferences for user %d: %v", userID, err)
+		return "", ""
+	}
+	return response.Language, response.Timezone
+}
+
+func (s *authService) generateTokenPair(email string, userID uint, credentialID uint, client dto.ClientInfo, riskScore int) (string, string, int64, error) {
 	// Generate access token
 	accessExpirationTime := time.Now().Add(time.Duration(s.config.AccessTokenExpiryHours) * time.Hour)
 
+	locale, timezone := s.getUserLocale(userID)
 	claims := &Claims{
-		Email:  email,
-		UserID: userID,
+		Email:    email,
+		UserID:   userID,
+		Locale:   locale,
+		Timezone: timezone,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(accessExpirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "auth-service",
 			Subject:   email,
+			Audience:  jwt.ClaimStrings(s.config.TokenAudiences),
 		},
 	}
 
@@ -223,10 +405,14 @@ func (s *authService) generateTokenPair(email string, userID uint, credentialID
 	refreshExpirationTime := time.Now().Add(time.Duration(s.config.RefreshTokenExpiryHours) * time.Hour)
 
 	refreshToken := &models.RefreshToken{
-		CredentialID: credentialID,
-		Token:        refreshTokenString,
-		ExpiresAt:    refreshExpirationTime,
-		IsRevoked:    false,
+		CredentialID:      credentialID,
+		Token:             refreshTokenString,
+		ExpiresAt:         refreshExpirationTime,
+		IsRevoked:         false,
+		IPAddress:         client.IPAddress,
+		UserAgent:         client.UserAgent,
+		DeviceFingerprint: client.DeviceFingerprint,
+		RiskScore:         riskScore,
 	}
 
 	if err := s.credentialRepo.CreateRefreshToken(refreshToken); err != nil {
@@ -235,3 +421,44 @@ func (s *authService) generateTokenPair(email string, userID uint, credentialID
 
 	return accessTokenString, refreshTokenString, accessExpirationTime.Unix(), nil
 }
+
+func (s *authService) checkMagicLinkLimits(ctx context.Context, email string, clientIP string) error {
+	emailResult, err := s.magicLinkLimiters.Email.Allow(ctx, strings.ToLower(email))
+	if err != nil {
+		return utils.InternalServerError("Failed to check rate limit")
+	}
+	if !emailResult.Allowed {
+		return utils.TooManyRequests("Too many login link requests for this email, try again later")
+	}
+
+	ipResult, err := s.magicLinkLimiters.IP.Allow(ctx, clientIP)
+	if err != nil {
+		return utils.InternalServerError("Failed to check rate limit")
+	}
+	if !ipResult.Allowed {
+		return utils.TooManyRequests("Too many login link requests, try again later")
+	}
+
+	return nil
+}
+
+func (s *authService) buildMagicLinkURL(token string) string {
+	separator := "?"
+	if strings.Contains(s.config.MagicLinkURL, "?") {
+		separator = "&"
+	}
+	return s.config.MagicLinkURL + separator + "token=" + url.QueryEscape(token)
+}
+
+func generateSecureToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
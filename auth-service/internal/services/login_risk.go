@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"auth-service/internal/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/risk"
+
+	"shared/mail"
+	"shared/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxStepUpAttempts is how many wrong codes a challenge tolerates
+const maxStepUpAttempts = 5
+
+// VerifyStepUp finishes a login that was held back for step-up verification
+func (s *authService) VerifyStepUp(req *dto.StepUpVerifyReq, client dto.ClientInfo) (*dto.AuthRes, error) {
+	challenge, err := s.stepUpRepo.GetByID(req.ChallengeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.Unauthorized("Invalid or expired verification code")
+		}
+		return nil, utils.InternalServerError("Failed to get verification challenge")
+	}
+	if challenge.CompletedAt != nil || challenge.ExpiresAt.Before(time.Now()) || challenge.Attempts >= maxStepUpAttempts {
+		return nil, utils.Unauthorized("Invalid or expired verification code")
+	}
+
+	credential, err := s.credentialRepo.GetByID(challenge.CredentialID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get credential")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashToken(req.Code)), []byte(challenge.CodeHash)) != 1 {
+		if err := s.stepUpRepo.IncrementAttempts(challenge.ID); err != nil {
+			log.Printf("Failed to count step-up attempt: %v", err)
+		}
+		s.recordLoginEvent(&credential.ID, credential.Email, models.LoginEventStepUpFailure, models.LoginMethodStepUp, client, &risk.Assessment{Score: challenge.RiskScore})
+		return nil, utils.Unauthorized("Invalid or expired verification code")
+	}
+
+	completed, err := s.stepUpRepo.MarkCompleted(challenge.ID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to complete verification")
+	}
+	if !completed {
+		return nil, utils.Unauthorized("Invalid or expired verification code")
+	}
+
+	userID, err := s.getUserIDFromUserService(credential.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(credential, userID, models.LoginMethodStepUp, client, &risk.Assessment{Score: challenge.RiskScore})
+}
+
+// -----------------------
+// -- Helper functions --
+// -----------------------
+
+func (s *authService) assessLogin(credential *models.Credential, client dto.ClientInfo) *risk.Assessment {
+	attempt := &risk.Attempt{
+		CredentialID:      credential.ID,
+		Email:             credential.Email,
+		IPAddress:         client.IPAddress,
+		UserAgent:         client.UserAgent,
+		DeviceFingerprint: client.DeviceFingerprint,
+		Time:              time.Now(),
+	}
+	if s.geo != nil {
+		attempt.Location, _ = s.geo.Lookup(client.IPAddress)
+	}
+
+	assessment := s.riskEngine.Assess(attempt)
+	if assessment.Score > 0 {
+		log.Printf("Login risk for credential %d: score=%d reasons=%q", credential.ID, assessment.Score, assessment.Reasons())
+	}
+	return assessment
+}
+
+// completeLogin issues the token pair, records the successful login and
+// tells the user about suspicious sign-ins
+func (s *authService) completeLogin(credential *models.Credential, userID uint, method models.LoginMethod, client dto.ClientInfo, assessment *risk.Assessment) (*dto.AuthRes, error) {
+	accessToken, refreshToken, expiresAt, err := s.generateTokenPair(credential.Email, userID, credential.ID, client, assessment.Score)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to generate tokens")
+	}
+
+	s.recordLoginEvent(&credential.ID, credential.Email, models.LoginEventSuccess, method, client, assessment)
+
+	now := time.Now()
+	credential.LastLogin = &now
+	if err := s.credentialRepo.Update(credential); err != nil {
+		log.Printf("Failed to update last login: %v", err)
+	}
+
+	// Step-up logins already emailed the user a code
+	if method != models.LoginMethodStepUp && assessment.Score >= s.config.RiskNotifyThreshold && len(assessment.Signals) > 0 {
+		s.notifyNewSignIn(credential.Email, client, assessment)
+	}
+
+	return &dto.AuthRes{
+		ID:           credential.ID,
+		Email:        credential.Email,
+		Message:      "Login successful",
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+func (s *authService) startStepUp(credential *models.Credential, client dto.ClientInfo, assessment *risk.Assessment) (*dto.AuthRes, error) {
+	code, err := generateVerificationCode()
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to generate verification code")
+	}
+
+	challenge := &models.StepUpChallenge{
+		ID:                uuid.New().String(),
+		CredentialID:      credential.ID,
+		CodeHash:          hashToken(code),
+		IPAddress:         client.IPAddress,
+		UserAgent:         client.UserAgent,
+		DeviceFingerprint: client.DeviceFingerprint,
+		RiskScore:         assessment.Score,
+		ExpiresAt:         time.Now().Add(s.config.GetStepUpCodeTTL()),
+	}
+	if err := s.stepUpRepo.Create(challenge); err != nil {
+		return nil, utils.InternalServerError("Failed to create verification challenge")
+	}
+
+	msg := mail.Message{
+		To:      credential.Email,
+		Subject: "Confirm it's you",
+		Body: fmt.Sprintf("We noticed an unusual sign-in to your account:\n\n%s\nEnter this code to finish signing in: %s\n\nThe code expires in %d minutes. If this wasn't you, change your password right away.\n",
+			describeSignIn(client, assessment), code, s.config.StepUpCodeTTLMinutes),
+	}
+	if err := s.mailer.Send(context.Background(), msg); err != nil {
+		log.Printf("Failed to send step-up email: %v", err)
+		return nil, utils.InternalServerError("Failed to send verification code")
+	}
+
+	s.recordLoginEvent(&credential.ID, credential.Email, models.LoginEventStepUpRequired, models.LoginMethodPassword, client, assessment)
+
+	return &dto.AuthRes{
+		ID:             credential.ID,
+		Email:          credential.Email,
+		Message:        "Additional verification required, a code has been sent to your email",
+		StepUpRequired: true,
+		ChallengeID:    challenge.ID,
+	}, nil
+}
+
+// recordLoginEvent never fails the login, a lost event only weakens detection
+func (s *authService) recordLoginEvent(credentialID *uint, email string, eventType models.LoginEventType, method models.LoginMethod, client dto.ClientInfo, assessment *risk.Assessment) {
+	event := &models.LoginEvent{
+		CredentialID:      credentialID,
+		Email:             email,
+		EventType:         eventType,
+		Method:            method,
+		IPAddress:         client.IPAddress,
+		UserAgent:         client.UserAgent,
+		DeviceFingerprint: client.DeviceFingerprint,
+		RiskScore:         assessment.Score,
+		RiskReasons:       assessment.Reasons(),
+	}
+
+	if s.geo != nil {
+		if location, ok := s.geo.Lookup(client.IPAddress); ok {
+			event.Country = location.Country
+			event.City = location.City
+			event.Latitude = &location.Latitude
+			event.Longitude = &location.Longitude
+		}
+	}
+
+	if err := s.loginEventRepo.Create(event); err != nil {
+		log.Printf("Failed to record login event: %v", err)
+	}
+}
+
+func (s *authService) notifyNewSignIn(email string, client dto.ClientInfo, assessment *risk.Assessment) {
+	msg := mail.Message{
+		To:      email,
+		Subject: "New sign-in to your account",
+		Body: fmt.Sprintf("Your account was just signed in to:\n\n%s\nIf this was you, there's nothing to do. If not, change your password right away.\n",
+			describeSignIn(client, assessment)),
+	}
+	if err := s.mailer.Send(context.Background(), msg); err != nil {
+		log.Printf("Failed to send new sign-in email: %v", err)
+	}
+}
+
+func describeSignIn(client dto.ClientInfo, assessment *risk.Assessment) string {
+	description := fmt.Sprintf("Time: %s\nIP address: %s\nDevice: %s\n", time.Now().UTC().Format(time.RFC1123), client.IPAddress, client.UserAgent)
+	if reasons := assessment.Reasons(); reasons != "" {
+		description += "Why we flagged it: " + reasons + "\n"
+	}
+	return description
+}
+
+func generateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"auth-service/internal/clients"
+	"auth-service/internal/dto"
+	"auth-service/internal/models"
+	"auth-service/internal/repository"
+	"auth-service/internal/risk"
+	"auth-service/pkg/config"
+
+	"shared/mail"
+	"shared/proto/user_service"
+	"shared/ratelimit"
+	"shared/utils"
+
+	"google.golang.org/grpc"
+	"gorm.io/gorm"
+)
+
+// -----------------------
+// -- Fakes --
+// -----------------------
+
+// fakeCredentialRepo keeps credentials in memory, the methods the magic link
+// flow doesn't use panic through the nil embedded interface
+type fakeCredentialRepo struct {
+	repository.CredentialRepository
+	mu          sync.Mutex
+	credentials map[uint]*models.Credential
+}
+
+func (r *fakeCredentialRepo) GetByEmail(email string) (*models.Credential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, credential := range r.credentials {
+		if strings.EqualFold(credential.Email, email) {
+			copied := *credential
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeCredentialRepo) GetByID(id uint) (*models.Credential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	credential, ok := r.credentials[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *credential
+	return &copied, nil
+}
+
+func (r *fakeCredentialRepo) Update(credential *models.Credential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *credential
+	r.credentials[credential.ID] = &copied
+	return nil
+}
+
+func (r *fakeCredentialRepo) CreateRefreshToken(refreshToken *models.RefreshToken) error {
+	return nil
+}
+
+type fakeMagicLinkRepo struct {
+	mu    sync.Mutex
+	links []*models.MagicLink
+}
+
+func (r *fakeMagicLinkRepo) Create(link *models.MagicLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link.ID = uint(len(r.links) + 1)
+	copied := *link
+	r.links = append(r.links, &copied)
+	return nil
+}
+
+func (r *fakeMagicLinkRepo) GetByTokenHash(tokenHash string) (*models.MagicLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, link := range r.links {
+		if link.TokenHash == tokenHash {
+			copied := *link
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeMagicLinkRepo) MarkUsed(id uint) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link := r.links[id-1]
+	if link.UsedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	link.UsedAt = &now
+	return true, nil
+}
+
+// expire moves every link's expiry into the past, as if the TTL had passed
+func (r *fakeMagicLinkRepo) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, link := range r.links {
+		link.ExpiresAt = time.Now().Add(-time.Second)
+	}
+}
+
+type fakeLoginEventRepo struct {
+	repository.LoginEventRepository
+	mu     sync.Mutex
+	events []models.LoginEvent
+}
+
+func (r *fakeLoginEventRepo) Create(event *models.LoginEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, *event)
+	return nil
+}
+
+// fakeUserService answers the lookups a login makes, every user has ID 42
+type fakeUserService struct {
+	user_service.UnimplementedUserServiceServer
+}
+
+func (fakeUserService) GetUserByEmail(ctx context.Context, req *user_service.GetUserByEmailRequest) (*user_service.GetUserByEmailResponse, error) {
+	return &user_service.GetUserByEmailResponse{Id: 42, Email: req.Email, Status: "active"}, nil
+}
+
+func (fakeUserService) GetUserPreferences(ctx context.Context, req *user_service.GetUserPreferencesRequest) (*user_service.GetUserPreferencesResponse, error) {
+	return &user_service.GetUserPreferencesResponse{UserId: req.UserId, Language: "en", Timezone: "UTC"}, nil
+}
+
+func newUserServiceClient(t *testing.T) *clients.UserServiceClient {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	user_service.RegisterUserServiceServer(server, fakeUserService{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	client, err := clients.NewUserServiceClient(listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+type magicLinkFixture struct {
+	service AuthService
+	mailer  *mail.MemorySender
+	links   *fakeMagicLinkRepo
+	events  *fakeLoginEventRepo
+}
+
+func newMagicLinkFixture(t *testing.T) *magicLinkFixture {
+	t.Helper()
+	credentials := &fakeCredentialRepo{credentials: map[uint]*models.Credential{
+		1: {ID: 1, Email: "reader@example.com", IsActive: true},
+		2: {ID: 2, Email: "disabled@example.com", IsActive: false},
+	}}
+	links := &fakeMagicLinkRepo{}
+	mailer := mail.NewMemorySender()
+	cfg := &config.Config{
+		JWTSecret:               "test-secret",
+		AccessTokenExpiryHours:  1,
+		RefreshTokenExpiryHours: 24,
+		TokenAudiences:          []string{"user-service"},
+		RiskNotifyThreshold:     100,
+		MagicLinkURL:            "http://localhost:8080/api/v1/auth/magic-link/consume",
+		MagicLinkTTLMinutes:     15,
+	}
+	limiters := MagicLinkLimiters{
+		Email: ratelimit.NewMemorySlidingWindow(100, time.Hour),
+		IP:    ratelimit.NewMemorySlidingWindow(100, time.Hour),
+	}
+
+	events := &fakeLoginEventRepo{}
+
+	service := NewAuthService(credentials, links, events, nil, newUserServiceClient(t), mailer, limiters, risk.NewEngine(), nil, cfg)
+	return &magicLinkFixture{service: service, mailer: mailer, links: links, events: events}
+}
+
+var linkToken = regexp.MustCompile(`token=(\S+)`)
+
+// request asks for a link for email and returns the token from the emailed
+// link and the nonce the browser would keep
+func (f *magicLinkFixture) request(t *testing.T, email string) (string, string) {
+	t.Helper()
+	_, nonce, err := f.service.RequestMagicLink(&dto.MagicLinkReq{Email: email}, "203.0.113.7")
+	if err != nil {
+		t.Fatalf("RequestMagicLink: %v", err)
+	}
+
+	msg, ok := f.mailer.LastTo(email)
+	if !ok {
+		t.Fatalf("no email sent to %s", email)
+	}
+	match := linkToken.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("no link in %q", msg.Body)
+	}
+	token, err := url.QueryUnescape(match[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token, nonce
+}
+
+func statusOf(err error) int {
+	var customErr *utils.CustomError
+	if errors.As(err, &customErr) {
+		return customErr.Code
+	}
+	return 0
+}
+
+var testClient = dto.ClientInfo{IPAddress: "203.0.113.7", UserAgent: "test"}
+
+// -----------------------
+// -- Tests --
+// -----------------------
+
+func TestMagicLinkRequestThenConsume(t *testing.T) {
+	f := newMagicLinkFixture(t)
+	token, nonce := f.request(t, "reader@example.com")
+
+	res, err := f.service.ConsumeMagicLink(token, nonce, testClient)
+	if err != nil {
+		t.Fatalf("ConsumeMagicLink: %v", err)
+	}
+	if res.Email != "reader@example.com" || res.AccessToken == "" || res.RefreshToken == "" {
+		t.Errorf("unexpected response %+v", res)
+	}
+	if len(f.events.events) != 1 || f.events.events[0].Method != models.LoginMethodMagicLink || f.events.events[0].EventType != models.LoginEventSuccess {
+		t.Errorf("want one successful magic link login recorded, got %+v", f.events.events)
+	}
+}
+
+func TestMagicLinkIsSingleUse(t *testing.T) {
+	f := newMagicLinkFixture(t)
+	token, nonce := f.request(t, "reader@example.com")
+
+	if _, err := f.service.ConsumeMagicLink(token, nonce, testClient); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	_, err := f.service.ConsumeMagicLink(token, nonce, testClient)
+	if statusOf(err) != http.StatusUnauthorized {
+		t.Errorf("second use: got %v, want 401", err)
+	}
+}
+
+func TestMagicLinkExpiresAfter15Minutes(t *testing.T) {
+	f := newMagicLinkFixture(t)
+	before := time.Now()
+	res, nonce, err := f.service.RequestMagicLink(&dto.MagicLinkReq{Email: "reader@example.com"}, "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Unix(res.ExpiresAt, 0)
+	if want := before.Add(15 * time.Minute); expiresAt.Before(want.Add(-time.Second)) || expiresAt.After(want.Add(time.Second)) {
+		t.Errorf("link expires at %v, want about %v", expiresAt, want)
+	}
+	msg, _ := f.mailer.LastTo("reader@example.com")
+	if !strings.Contains(msg.Body, "expires in 15 minutes") {
+		t.Errorf("email doesn't mention the expiry: %q", msg.Body)
+	}
+
+	token, err := url.QueryUnescape(linkToken.FindStringSubmatch(msg.Body)[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.links.expire()
+
+	_, err = f.service.ConsumeMagicLink(token, nonce, testClient)
+	if statusOf(err) != http.StatusUnauthorized {
+		t.Errorf("expired link: got %v, want 401", err)
+	}
+}
+
+func TestMagicLinkNonceMismatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		nonce func(nonce, otherNonce string) string
+	}{
+		{name: "no cookie", nonce: func(string, string) string { return "" }},
+		{name: "another browser's cookie", nonce: func(_, otherNonce string) string { return otherNonce }},
+		{name: "tampered cookie", nonce: func(nonce, _ string) string { return nonce + "x" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newMagicLinkFixture(t)
+			token, nonce := f.request(t, "reader@example.com")
+			_, otherNonce := f.request(t, "reader@example.com")
+
+			_, err := f.service.ConsumeMagicLink(token, tt.nonce(nonce, otherNonce), testClient)
+			if statusOf(err) != http.StatusUnauthorized {
+				t.Fatalf("got %v, want 401", err)
+			}
+
+			// A mismatch must not burn the link for the right browser
+			if _, err := f.service.ConsumeMagicLink(token, nonce, testClient); err != nil {
+				t.Errorf("the right nonce was refused after a mismatch: %v", err)
+			}
+		})
+	}
+}
+
+func TestMagicLinkUnknownAndDisabledAccounts(t *testing.T) {
+	f := newMagicLinkFixture(t)
+
+	for _, email := range []string{"nobody@example.com", "disabled@example.com"} {
+		res, nonce, err := f.service.RequestMagicLink(&dto.MagicLinkReq{Email: email}, "203.0.113.7")
+		if err != nil {
+			t.Fatalf("%s: %v", email, err)
+		}
+		if res.Message == "" || nonce == "" {
+			t.Errorf("%s: the response must look like any other", email)
+		}
+	}
+	if outbox := f.mailer.Outbox(); len(outbox) != 0 {
+		t.Errorf("sent %d emails, want none", len(outbox))
+	}
+}
+
+func TestMagicLinkInvalidToken(t *testing.T) {
+	f := newMagicLinkFixture(t)
+
+	tests := []struct {
+		token string
+		want  int
+	}{
+		{token: "", want: http.StatusBadRequest},
+		{token: "not-a-token", want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if _, err := f.service.ConsumeMagicLink(tt.token, "nonce", testClient); statusOf(err) != tt.want {
+			t.Errorf("ConsumeMagicLink(%q) = %v, want %d", tt.token, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import "net/http"
+
+// OAuthError is an error response in the RFC 6749 section 5.2 format, used by
+// the token exchange endpoint instead of utils.CustomError
+type OAuthError struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+func invalidRequest(description string) *OAuthError {
+	return &OAuthError{Status: http.StatusBadRequest, Code: "invalid_request", Description: description}
+}
+
+func invalidGrant(description string) *OAuthError {
+	return &OAuthError{Status: http.StatusBadRequest, Code: "invalid_grant", Description: description}
+}
+
+func invalidTarget(description string) *OAuthError {
+	return &OAuthError{Status: http.StatusBadRequest, Code: "invalid_target", Description: description}
+}
+
+func invalidScope(description string) *OAuthError {
+	return &OAuthError{Status: http.StatusBadRequest, Code: "invalid_scope", Description: description}
+}
+
+func unsupportedGrantType(description string) *OAuthError {
+	return &OAuthError{Status: http.StatusBadRequest, Code: "unsupported_grant_type", Description: description}
+}
+
+func serverError(description string) *OAuthError {
+	return &OAuthError{Status: http.StatusInternalServerError, Code: "server_error", Description: description}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"auth-service/internal/dto"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ExchangeToken implements RFC 8693 token exchange. It trades a user access
+// token for a shorter-lived token restricted to a single audience and a
+// subset of the subject token's scope, meant for background jobs.
+func (s *authService) ExchangeToken(req *dto.TokenExchangeReq) (*dto.TokenExchangeRes, error) {
+	if req.GrantType != dto.GrantTypeTokenExchange {
+		return nil, unsupportedGrantType("grant_type must be " + dto.GrantTypeTokenExchange)
+	}
+	if req.SubjectTokenType != dto.TokenTypeAccessToken {
+		return nil, invalidRequest("subject_token_type must be " + dto.TokenTypeAccessToken)
+	}
+	if req.RequestedTokenType != "" && req.RequestedTokenType != dto.TokenTypeAccessToken {
+		return nil, invalidRequest("requested_token_type must be " + dto.TokenTypeAccessToken)
+	}
+
+	subject, err := s.parseAccessToken(req.SubjectToken)
+	if err != nil {
+		return nil, invalidGrant("subject_token is invalid or expired")
+	}
+
+	// The new audience must be one the subject token was already valid for
+	if !slices.Contains(subject.Audience, req.Audience) {
+		return nil, invalidTarget("audience is not permitted by the subject token")
+	}
+
+	requestedScopes := strings.Fields(req.Scope)
+	if len(requestedScopes) == 0 {
+		return nil, invalidScope("scope is required")
+	}
+	subjectScopes := strings.Fields(subject.Scope)
+	for _, scope := range requestedScopes {
+		if !slices.Contains(s.config.TokenExchangeScopes, scope) {
+			return nil, invalidScope("unknown scope: " + scope)
+		}
+		// An empty subject scope is an unrestricted user token
+		if len(subjectScopes) > 0 && !slices.Contains(subjectScopes, scope) {
+			return nil, invalidScope("scope exceeds the subject token: " + scope)
+		}
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.config.GetTokenExchangeTTL())
+	if subject.ExpiresAt != nil && subject.ExpiresAt.Before(expiresAt) {
+		expiresAt = subject.ExpiresAt.Time
+	}
+
+	scope := strings.Join(requestedScopes, " ")
+	claims := &Claims{
+		Email:    subject.Email,
+		UserID:   subject.UserID,
+		Scope:    scope,
+		Locale:   subject.Locale,
+		Timezone: subject.Timezone,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "auth-service",
+			Subject:   subject.Subject,
+			Audience:  jwt.ClaimStrings{req.Audience},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(s.config.JWTSecret))
+	if err != nil {
+		return nil, serverError("failed to issue token")
+	}
+
+	return &dto.TokenExchangeRes{
+		AccessToken:     tokenString,
+		IssuedTokenType: dto.TokenTypeAccessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(time.Until(expiresAt).Seconds()),
+		Scope:           scope,
+	}, nil
+}
+
+func (s *authService) parseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrInvalidKeyType
+		}
+		return []byte(s.config.JWTSecret), nil
+	}, jwt.WithIssuer("auth-service"), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -4,6 +4,11 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"shared/mail"
+	"shared/ratelimit"
 )
 
 type Config struct {
@@ -20,7 +25,45 @@ type Config struct {
 	AccessTokenExpiryHours  int
 	RefreshTokenExpiryHours int
 
+	// Audiences stamped on user access tokens, and the limits for
+	// downscoped tokens issued through token exchange
+	TokenAudiences             []string
+	TokenExchangeScopes        []string
+	TokenExchangeExpiryMinutes int
+
 	UserServiceURL string
+	RedisURL       string
+
+	// Rate limiting
+	RateLimitEnabled  bool
+	RateLimitPolicies []ratelimit.Policy
+
+	// Mail configuration
+	MailDriver   string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailFrom     string
+
+	// Suspicious login detection
+	GeoIPDatabasePath         string
+	RiskNotifyThreshold       int
+	RiskStepUpEnabled         bool
+	RiskStepUpThreshold       int
+	StepUpCodeTTLMinutes      int
+	FailureBurstThreshold     int
+	FailureBurstWindowMinutes int
+	ImpossibleTravelKmh       int
+
+	// Magic link login
+	MagicLinkURL               string
+	MagicLinkTTLMinutes        int
+	MagicLinkEmailLimitPerHour int
+	MagicLinkIPLimitPerHour    int
+
+	// Sign-in page linked from bulk import invite emails
+	InviteLoginURL string
 }
 
 func LoadConfig() (*Config, error) {
@@ -36,7 +79,24 @@ func LoadConfig() (*Config, error) {
 
 		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-this-in-production"),
 
+		TokenAudiences:      getEnvList("TOKEN_AUDIENCES", "user-service,book-service"),
+		TokenExchangeScopes: getEnvList("TOKEN_EXCHANGE_SCOPES", "users:read,users:write,books:read,books:write"),
+
 		UserServiceURL: getEnv("USER_SERVICE_URL", "localhost:9081"),
+		RedisURL:       getEnv("REDIS_URL", "localhost:6379"),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnv("SMTP_PORT", "587"),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		MailFrom:     getEnv("MAIL_FROM", "no-reply@bookstore.local"),
+
+		GeoIPDatabasePath: getEnv("GEOIP_DATABASE_PATH", ""),
+
+		MagicLinkURL: getEnv("MAGIC_LINK_URL", "http://localhost:8080/api/v1/auth/magic-link/consume"),
+
+		InviteLoginURL: getEnv("INVITE_LOGIN_URL", "http://localhost:3000/login"),
 	}
 
 	// Access token expiry (default: 1 hour)
@@ -55,6 +115,49 @@ func LoadConfig() (*Config, error) {
 	}
 	config.RefreshTokenExpiryHours = refreshTokenExpiry
 
+	// Exchanged token expiry (default: 15 minutes)
+	if config.TokenExchangeExpiryMinutes, err = getEnvInt("TOKEN_EXCHANGE_EXPIRY_MINUTES", 15); err != nil {
+		return nil, err
+	}
+
+	// Rate limit policies, see ratelimit.ParsePolicies for the format
+	config.RateLimitEnabled, _ = strconv.ParseBool(getEnv("RATE_LIMIT_ENABLED", "true"))
+	if config.RateLimitPolicies, err = ratelimit.ParsePolicies(getEnv("RATE_LIMIT_POLICIES", "POST /api/v1/auth/login=sliding_window:10/1m:ip;POST /api/v1/auth/login/verify=sliding_window:10/1m:ip;POST /api/v1/auth/register=sliding_window:5/1m:ip;POST /api/v1/auth/refresh=token_bucket:30/1m:ip;POST /api/v1/auth/magic-link=sliding_window:10/1m:ip;POST /api/v1/auth/token/exchange=token_bucket:30/1m:ip")); err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_POLICIES: %v", err)
+	}
+
+	// Suspicious login detection (scores range 0-100)
+	config.RiskStepUpEnabled, _ = strconv.ParseBool(getEnv("RISK_STEP_UP_ENABLED", "false"))
+	if config.RiskNotifyThreshold, err = getEnvInt("RISK_NOTIFY_THRESHOLD", 30); err != nil {
+		return nil, err
+	}
+	if config.RiskStepUpThreshold, err = getEnvInt("RISK_STEP_UP_THRESHOLD", 70); err != nil {
+		return nil, err
+	}
+	if config.StepUpCodeTTLMinutes, err = getEnvInt("STEP_UP_CODE_TTL_MINUTES", 10); err != nil {
+		return nil, err
+	}
+	if config.FailureBurstThreshold, err = getEnvInt("FAILURE_BURST_THRESHOLD", 5); err != nil {
+		return nil, err
+	}
+	if config.FailureBurstWindowMinutes, err = getEnvInt("FAILURE_BURST_WINDOW_MINUTES", 15); err != nil {
+		return nil, err
+	}
+	if config.ImpossibleTravelKmh, err = getEnvInt("IMPOSSIBLE_TRAVEL_KMH", 900); err != nil {
+		return nil, err
+	}
+
+	// Magic link settings (default: 15 minute links, 5 per email and 20 per IP every hour)
+	if config.MagicLinkTTLMinutes, err = getEnvInt("MAGIC_LINK_TTL_MINUTES", 15); err != nil {
+		return nil, err
+	}
+	if config.MagicLinkEmailLimitPerHour, err = getEnvInt("MAGIC_LINK_EMAIL_LIMIT_PER_HOUR", 5); err != nil {
+		return nil, err
+	}
+	if config.MagicLinkIPLimitPerHour, err = getEnvInt("MAGIC_LINK_IP_LIMIT_PER_HOUR", 20); err != nil {
+		return nil, err
+	}
+
 	return config, nil
 }
 
@@ -65,7 +168,59 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %v", key, err)
+	}
+	return parsed, nil
+}
+
+func getEnvList(key, fallback string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, fallback), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func (c *Config) GetTokenExchangeTTL() time.Duration {
+	return time.Duration(c.TokenExchangeExpiryMinutes) * time.Minute
+}
+
+func (c *Config) GetStepUpCodeTTL() time.Duration {
+	return time.Duration(c.StepUpCodeTTLMinutes) * time.Minute
+}
+
+func (c *Config) GetFailureBurstWindow() time.Duration {
+	return time.Duration(c.FailureBurstWindowMinutes) * time.Minute
+}
+
+func (c *Config) GetMagicLinkTTL() time.Duration {
+	return time.Duration(c.MagicLinkTTLMinutes) * time.Minute
+}
+
+func (c *Config) GetMailConfig() mail.Config {
+	return mail.Config{
+		Driver:       c.MailDriver,
+		SMTPHost:     c.SMTPHost,
+		SMTPPort:     c.SMTPPort,
+		SMTPUsername: c.SMTPUsername,
+		SMTPPassword: c.SMTPPassword,
+		From:         c.MailFrom,
+	}
+}
+
+// GetDatabaseURL reads and writes DATETIME columns as UTC. Rows written
+// while the DSN had loc=Local hold the host's local time, see the readme
+// before upgrading a database written by a host not on UTC.
 func (c *Config) GetDatabaseURL() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
 		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
 }
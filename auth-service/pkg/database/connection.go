@@ -38,6 +38,9 @@ func AutoMigrate() error {
 	return DB.AutoMigrate(
 		&models.Credential{},
 		&models.RefreshToken{},
+		&models.MagicLink{},
+		&models.LoginEvent{},
+		&models.StepUpChallenge{},
 	)
 }
 
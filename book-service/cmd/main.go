@@ -1,17 +1,28 @@
 package main
 
 import (
+	"fmt"
 	"log"
+	"net"
+	"strconv"
 
 	"book-service/internal/clients"
+	bookGrpc "book-service/internal/grpc"
 	"book-service/internal/handlers"
 	"book-service/internal/middleware"
 	"book-service/internal/repository"
+	"book-service/internal/search"
 	"book-service/internal/services"
 	"book-service/pkg/config"
 	"book-service/pkg/database"
 
+	"shared/locale"
+	"shared/proto/data_export"
+	"shared/ratelimit"
+	"shared/userclient"
+
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -25,30 +36,85 @@ func main() {
 	}
 
 	// Initialize cached auth service client
-	authServiceClient, err := clients.NewCachedAuthClient(cfg.AuthServiceURL, cfg.RedisURL, cfg.GetL1CacheTTL(), cfg.GetL2CacheTTL())
+	authServiceClient, err := clients.NewCachedAuthClient(cfg.AuthServiceURL, cfg.RedisURL, cfg.TokenAudience, cfg.GetL1CacheTTL(), cfg.GetL2CacheTTL())
 	if err != nil {
 		log.Fatal("Failed to create auth service client:", err)
 	}
 	defer authServiceClient.Close()
 
+	userServiceClient, err := userclient.New(cfg.UserServiceURL, cfg.GetUserCacheTTL())
+	if err != nil {
+		log.Fatal("Failed to create user service client:", err)
+	}
+	defer userServiceClient.Close()
+
 	// Initialize repositories
 	authorRepo := repository.NewAuthorRepository(database.GetDB())
 	bookRepo := repository.NewBookRepository(database.GetDB())
 
+	searchIndex, err := newSearchIndex(cfg, bookRepo)
+	if err != nil {
+		log.Fatal("Failed to set up search index:", err)
+	}
+
 	// Initialize services
-	authorService := services.NewAuthorService(authorRepo, bookRepo)
-	bookService := services.NewBookService(bookRepo, authorRepo)
+	authorService := services.NewAuthorService(authorRepo, bookRepo, searchIndex)
+	bookService := services.NewBookService(bookRepo, authorRepo, userServiceClient, searchIndex, cfg.SearchMaxLimit)
 
 	// Initialize handlers
 	authorHandler := handlers.NewAuthorHandler(authorService)
 	bookHandler := handlers.NewBookHandler(bookService)
 	cacheHandler := handlers.NewCacheHandler(authServiceClient)
 
+	rateLimiter := ratelimit.NewMiddleware(authServiceClient.RedisClient(), "book-service", cfg.RateLimitPolicies)
+
+	exportServer := bookGrpc.NewExportServer(bookRepo)
+	go startGRPCServer(exportServer, cfg)
+
 	log.Printf("Starting HTTP server...")
-	startHTTPServer(cfg, authorHandler, bookHandler, cacheHandler, authServiceClient)
+	startHTTPServer(cfg, authorHandler, bookHandler, cacheHandler, authServiceClient, rateLimiter)
+}
+
+func newSearchIndex(cfg *config.Config, bookRepo repository.BookRepository) (search.SearchIndex, error) {
+	switch cfg.SearchIndex {
+	case search.KindMySQL:
+		return search.NewMySQLIndex(database.GetDB()), nil
+	case search.KindMemory:
+		index := search.NewMemoryIndex()
+		if err := services.LoadSearchIndex(index, bookRepo); err != nil {
+			return nil, err
+		}
+		return index, nil
+	default:
+		return nil, fmt.Errorf("unknown SEARCH_INDEX %q, expected %s or %s", cfg.SearchIndex, search.KindMySQL, search.KindMemory)
+	}
 }
 
-func startHTTPServer(cfg *config.Config, authorHandler *handlers.AuthorHandler, bookHandler *handlers.BookHandler, cacheHandler *handlers.CacheHandler, authServiceClient *clients.CachedAuthClient) {
+func startGRPCServer(exportServer *bookGrpc.ExportServer, cfg *config.Config) {
+	grpcPort, err := strconv.Atoi(cfg.Port)
+	if err != nil {
+		log.Printf("Invalid port configuration: %v", err)
+		return
+	}
+	grpcPort = grpcPort + 1000
+
+	lis, err := net.Listen("tcp", ":"+strconv.Itoa(grpcPort))
+	if err != nil {
+		log.Printf("Failed to listen on gRPC port: %v", err)
+		return
+	}
+
+	grpcServer := grpc.NewServer()
+	data_export.RegisterDataExportServer(grpcServer, exportServer)
+
+	log.Printf("gRPC server starting on port %d", grpcPort)
+	if err := grpcServer.Serve(lis); err != nil {
+		log.Printf("Failed to start gRPC server: %v", err)
+		return
+	}
+}
+
+func startHTTPServer(cfg *config.Config, authorHandler *handlers.AuthorHandler, bookHandler *handlers.BookHandler, cacheHandler *handlers.CacheHandler, authServiceClient *clients.CachedAuthClient, rateLimiter *ratelimit.Middleware) {
 	gin.SetMode(cfg.GinMode)
 
 	r := gin.Default()
@@ -56,9 +122,17 @@ func startHTTPServer(cfg *config.Config, authorHandler *handlers.AuthorHandler,
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
 
+	// Installed twice: here for IP-keyed policies and after the JWT
+	// middleware for user-keyed ones. Each request is only counted once.
+	rateLimit := func(c *gin.Context) { c.Next() }
+	if cfg.RateLimitEnabled {
+		rateLimit = rateLimiter.Handler()
+	}
+	r.Use(rateLimit)
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
+		locale.JSON(c, 200, gin.H{
 			"status":  "healthy",
 			"service": "book-service",
 			"port":    cfg.Port,
@@ -74,35 +148,40 @@ func startHTTPServer(cfg *config.Config, authorHandler *handlers.AuthorHandler,
 	}
 
 	jwtMiddleware := middleware.NewJWTMiddleware(authServiceClient)
+	canRead := jwtMiddleware.RequireScope("books:read")
+	canWrite := jwtMiddleware.RequireScope("books:write")
 
 	v1 := r.Group("/api/v1")
 	{
 		authors := v1.Group("/authors")
-		authors.Use(jwtMiddleware.ValidateToken())
+		authors.Use(jwtMiddleware.ValidateToken(), rateLimit)
 		{
-			authors.POST("/", authorHandler.CreateAuthor)
-			authors.GET("/", authorHandler.GetAuthors)
-			authors.GET("/:id", authorHandler.GetAuthor)
-			authors.PUT("/:id", authorHandler.UpdateAuthor)
-			authors.DELETE("/:id", authorHandler.DeleteAuthor)
+			authors.POST("/", canWrite, authorHandler.CreateAuthor)
+			authors.GET("/", canRead, authorHandler.GetAuthors)
+			authors.GET("/:id", canRead, authorHandler.GetAuthor)
+			authors.PUT("/:id", canWrite, authorHandler.UpdateAuthor)
+			authors.DELETE("/:id", canWrite, authorHandler.DeleteAuthor)
 		}
 
 		books := v1.Group("/books")
-		books.Use(jwtMiddleware.ValidateToken())
+		books.Use(jwtMiddleware.ValidateToken(), rateLimit)
 		{
-			books.POST("/", bookHandler.CreateBook)
-			books.GET("/", bookHandler.GetBooks)
-			books.GET("/:id", bookHandler.GetBook)
-			books.PUT("/:id", bookHandler.UpdateBook)
-			books.DELETE("/:id", bookHandler.DeleteBook)
-			books.GET("/author/:authorId", bookHandler.GetBooksByAuthor)
-			books.GET("/search", bookHandler.SearchBooks)
+			books.POST("/", canWrite, bookHandler.CreateBook)
+			books.GET("/", canRead, bookHandler.GetBooks)
+			books.GET("/:id", canRead, bookHandler.GetBook)
+			books.PUT("/:id", canWrite, bookHandler.UpdateBook)
+			books.DELETE("/:id", canWrite, bookHandler.DeleteBook)
+			books.GET("/author/:authorId", canRead, bookHandler.GetBooksByAuthor)
+			books.GET("/isbn/:isbn", canRead, bookHandler.GetBookByISBN)
+			books.GET("/search", canRead, bookHandler.SearchBooks)
+			books.GET("/feed", canRead, bookHandler.GetFeed)
 		}
 	}
 
 	log.Printf("HTTP server starting on port %s", cfg.Port)
 	log.Printf("Database URL: %s", cfg.GetDatabaseURL())
 	log.Printf("Auth Service URL: %s", cfg.AuthServiceURL)
+	log.Printf("User Service URL: %s", cfg.UserServiceURL)
 	log.Printf("Redis URL: %s", cfg.RedisURL)
 
 	if err := r.Run(":" + cfg.Port); err != nil {
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"shared/proto/auth_service"
@@ -16,6 +17,7 @@ import (
 
 type CachedAuthClient struct {
 	authClient   *AuthServiceClient
+	audience     string        // expected token audience for this service
 	l1Cache      *cache.Cache  // In-memory cache (L1)
 	l2Cache      *redis.Client // Redis cache (L2)
 	l1TTL        time.Duration
@@ -36,16 +38,18 @@ type CacheMetrics struct {
 }
 
 type CachedToken struct {
-	UserID    uint32 `json:"user_id"`
-	Email     string `json:"email"`
-	Issuer    string `json:"issuer"`
-	Subject   string `json:"subject"`
-	ExpiresAt int64  `json:"expires_at"`
-	IssuedAt  int64  `json:"issued_at"`
-	CachedAt  int64  `json:"cached_at"`
+	UserID    uint32   `json:"user_id"`
+	Email     string   `json:"email"`
+	Issuer    string   `json:"issuer"`
+	Subject   string   `json:"subject"`
+	ExpiresAt int64    `json:"expires_at"`
+	IssuedAt  int64    `json:"issued_at"`
+	Audience  []string `json:"audience"`
+	Scope     string   `json:"scope"`
+	CachedAt  int64    `json:"cached_at"`
 }
 
-func NewCachedAuthClient(authServiceAddr, redisAddr string, l1TTL, l2TTL time.Duration) (*CachedAuthClient, error) {
+func NewCachedAuthClient(authServiceAddr, redisAddr, audience string, l1TTL, l2TTL time.Duration) (*CachedAuthClient, error) {
 	// Initialize auth service client
 	authClient, err := NewAuthServiceClient(authServiceAddr)
 	if err != nil {
@@ -84,6 +88,7 @@ func NewCachedAuthClient(authServiceAddr, redisAddr string, l1TTL, l2TTL time.Du
 
 	return &CachedAuthClient{
 		authClient:   authClient,
+		audience:     audience,
 		l1Cache:      l1Cache,
 		l2Cache:      l2Cache,
 		l1TTL:        l1TTL,
@@ -99,7 +104,7 @@ func (h *CachedAuthClient) ValidateToken(ctx context.Context, token string) (*au
 
 	if !h.cacheEnabled {
 		h.metrics.GrpcCalls++
-		return h.authClient.ValidateToken(ctx, token)
+		return h.authClient.ValidateToken(ctx, token, h.audience)
 	}
 
 	cacheKey := h.generateCacheKey(token)
@@ -129,7 +134,7 @@ func (h *CachedAuthClient) ValidateToken(ctx context.Context, token string) (*au
 	log.Printf("CACHE MISS - calling auth service for token: %s", cacheKey[:12]+"...")
 	h.metrics.GrpcCalls++
 
-	response, err := h.authClient.ValidateToken(ctx, token)
+	response, err := h.authClient.ValidateToken(ctx, token, h.audience)
 	if err != nil {
 		return nil, err
 	}
@@ -245,11 +250,22 @@ func (h *CachedAuthClient) convertToCachedToken(claims *auth_service.UserClaims)
 		Subject:   claims.Subject,
 		ExpiresAt: claims.ExpiresAt,
 		IssuedAt:  claims.IssuedAt,
+		Audience:  claims.Audience,
+		Scope:     claims.Scope,
 		CachedAt:  time.Now().Unix(),
 	}
 }
 
 func (h *CachedAuthClient) buildResponse(cachedToken *CachedToken) *auth_service.ValidateTokenResponse {
+	// The L2 cache is shared between services, so an entry cached by another
+	// service may belong to a token that was never meant for this one
+	if h.audience != "" && !slices.Contains(cachedToken.Audience, h.audience) {
+		return &auth_service.ValidateTokenResponse{
+			IsValid:      false,
+			ErrorMessage: "Token is not valid for this service",
+		}
+	}
+
 	return &auth_service.ValidateTokenResponse{
 		IsValid:      true,
 		ErrorMessage: "",
@@ -260,6 +276,8 @@ func (h *CachedAuthClient) buildResponse(cachedToken *CachedToken) *auth_service
 			Subject:   cachedToken.Subject,
 			ExpiresAt: cachedToken.ExpiresAt,
 			IssuedAt:  cachedToken.IssuedAt,
+			Audience:  cachedToken.Audience,
+			Scope:     cachedToken.Scope,
 		},
 	}
 }
@@ -317,6 +335,15 @@ func (h *CachedAuthClient) ClearCache() {
 	log.Println("Multi-tier auth cache cleared (L1 + L2)")
 }
 
+// RedisClient exposes the L2 Redis connection for other features that share
+// the instance, or nil when Redis is unavailable
+func (h *CachedAuthClient) RedisClient() *redis.Client {
+	if !h.redisEnabled {
+		return nil
+	}
+	return h.l2Cache
+}
+
 func (h *CachedAuthClient) Close() error {
 	h.ClearCache()
 
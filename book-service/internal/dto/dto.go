@@ -2,72 +2,163 @@ package dto
 
 import (
 	"time"
+
+	"book-service/internal/models"
+
+	"shared/locale"
 )
 
 type CreateAuthorReq struct {
-	Name      string     `json:"name" binding:"required"`
-	Bio       string     `json:"bio"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	Country   string     `json:"country"`
+	Name      string       `json:"name" binding:"required"`
+	Bio       string       `json:"bio"`
+	BirthDate *locale.Date `json:"birth_date,omitempty"`
+	Country   string       `json:"country"`
 }
 
 type UpdateAuthorReq struct {
-	Name      *string    `json:"name,omitempty"`
-	Bio       *string    `json:"bio,omitempty"`
-	BirthDate *time.Time `json:"birth_date,omitempty"`
-	Country   *string    `json:"country,omitempty"`
+	Name      *string      `json:"name,omitempty"`
+	Bio       *string      `json:"bio,omitempty"`
+	BirthDate *locale.Date `json:"birth_date,omitempty"`
+	Country   *string      `json:"country,omitempty"`
 }
 
 type CreateBookReq struct {
 	Title       string  `json:"title" binding:"required"`
 	Description string  `json:"description"`
 	PublishYear int     `json:"publish_year" binding:"required"`
-	ISBN        string  `json:"isbn"`
+	ISBN        string  `json:"isbn" binding:"omitempty,max=17"` // ISBN-10 or ISBN-13, hyphens allowed
 	Genre       string  `json:"genre"`
 	Pages       int     `json:"pages"`
 	Price       float64 `json:"price"`
-	AuthorID    uint    `json:"author_id" binding:"required"`
+	// Contributors in the order they are credited
+	Contributors []ContributorReq `json:"contributors" binding:"required,min=1,max=50,dive"`
+}
+
+type ContributorReq struct {
+	AuthorID uint                   `json:"author_id" binding:"required"`
+	Role     models.ContributorRole `json:"role" binding:"required,oneof=author editor translator illustrator"`
 }
 
 type UpdateBookReq struct {
 	Title       *string  `json:"title,omitempty"`
 	Description *string  `json:"description,omitempty"`
 	PublishYear *int     `json:"publish_year,omitempty"`
-	ISBN        *string  `json:"isbn,omitempty"`
+	ISBN        *string  `json:"isbn,omitempty" binding:"omitempty,max=17"` // "" removes it
 	Genre       *string  `json:"genre,omitempty"`
 	Pages       *int     `json:"pages,omitempty"`
 	Price       *float64 `json:"price,omitempty"`
-	AuthorID    *uint    `json:"author_id,omitempty"`
+	// Replaces every contributor when set
+	Contributors *[]ContributorReq `json:"contributors,omitempty" binding:"omitempty,min=1,max=50,dive"`
 }
 
+// SearchBooksReq pages either by page or, for stable results while books
+// are added, by the next_cursor of the previous page. Cursor takes
+// precedence over Page and must be used with the same q, sort and order.
+// With Q and no Sort, books come best match first.
+//
+// Genres, AuthorIDs and Decades are multi-select (genre=a&genre=b) and
+// match any of their values. Ranges include their min_ and exclude their
+// max_ bound, as the price facet's bands do.
 type SearchBooksReq struct {
-	AuthorName  string `json:"author_name,omitempty" form:"author_name"`
-	BookTitle   string `json:"book_title,omitempty" form:"book_title"`
-	PublishYear int    `json:"publish_year,omitempty" form:"publish_year"`
-	Genre       string `json:"genre,omitempty" form:"genre"`
-	Page        int    `json:"page,omitempty" form:"page"`
-	Limit       int    `json:"limit,omitempty" form:"limit"`
+	Q             string   `json:"q,omitempty" form:"q" binding:"omitempty,max=200"`
+	AuthorName    string   `json:"author_name,omitempty" form:"author_name"`
+	BookTitle     string   `json:"book_title,omitempty" form:"book_title"`
+	PublishYear   int      `json:"publish_year,omitempty" form:"publish_year"`
+	Genres        []string `json:"genre,omitempty" form:"genre" binding:"omitempty,max=20"`
+	AuthorIDs     []uint   `json:"author_id,omitempty" form:"author_id" binding:"omitempty,max=20"`
+	Decades       []int    `json:"decade,omitempty" form:"decade" binding:"omitempty,max=20"`
+	MinPrice      *float64 `json:"min_price,omitempty" form:"min_price" binding:"omitempty,min=0"`
+	MaxPrice      *float64 `json:"max_price,omitempty" form:"max_price" binding:"omitempty,min=0"`
+	MinPages      *int     `json:"min_pages,omitempty" form:"min_pages" binding:"omitempty,min=0"`
+	MaxPages      *int     `json:"max_pages,omitempty" form:"max_pages" binding:"omitempty,min=0"`
+	Sort          string   `json:"sort,omitempty" form:"sort" binding:"omitempty,oneof=title publish_year price created_at"`
+	Order         string   `json:"order,omitempty" form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor        string   `json:"cursor,omitempty" form:"cursor"`
+	Page          int      `json:"page,omitempty" form:"page" binding:"omitempty,min=1"`
+	Limit         int      `json:"limit,omitempty" form:"limit" binding:"omitempty,min=1"`
+	IncludeTotal  bool     `json:"include_total,omitempty" form:"include_total"`
+	IncludeFacets bool     `json:"include_facets,omitempty" form:"include_facets"`
+
+	// BookIDs limits the search to the full-text matches for Q, set by the
+	// service
+	BookIDs []uint `json:"-" form:"-"`
 }
 
+// SearchBooksRes only has Total and TotalPages when include_total is set,
+// counting is the slow part of a search
 type SearchBooksRes struct {
-	Books      []BookWithAuthor `json:"books"`
-	Total      int64            `json:"total"`
-	Page       int              `json:"page"`
-	Limit      int              `json:"limit"`
-	TotalPages int              `json:"total_pages"`
+	Books      []BookWithContributors `json:"books"`
+	Total      *int64                 `json:"total,omitempty"`
+	Page       int                    `json:"page,omitempty"`
+	Limit      int                    `json:"limit"`
+	TotalPages *int                   `json:"total_pages,omitempty"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+	Facets     *SearchFacets          `json:"facets,omitempty"`
 }
 
-type BookWithAuthor struct {
+// SearchFacets counts the books each filter value would match. Each facet
+// applies every filter of the search except its own, so picking another
+// value of a multi-select filter shows what it would add.
+type SearchFacets struct {
+	Genres  []FacetCount `json:"genres"`
+	Decades []FacetCount `json:"decades"`
+	Prices  []PriceBand  `json:"prices"`
+	Authors []FacetCount `json:"authors"`
+}
+
+// FacetCount is a filter value, e.g. a genre or an author_id with the
+// author's name as Label
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int64  `json:"count"`
+}
+
+// PriceBand counts books priced from Min up to, not including, Max. The
+// last band has no Max.
+type PriceBand struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Count int64    `json:"count"`
+}
+
+// FeedReq pages through the following feed, newest first. Before is the
+// next_before of the previous page.
+type FeedReq struct {
+	Before uint `form:"before"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type FeedRes struct {
+	Books      []BookWithContributors `json:"books"`
+	NextBefore uint                   `json:"next_before,omitempty"`
+}
+
+type BookWithContributors struct {
 	ID          uint      `json:"id"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	PublishYear int       `json:"publish_year"`
-	ISBN        string    `json:"isbn"`
+	ISBN        *string   `json:"isbn,omitempty"`
+	ISBNDisplay *string   `json:"isbn_display,omitempty"`
 	Genre       string    `json:"genre"`
 	Pages       int       `json:"pages"`
 	Price       float64   `json:"price"`
-	AuthorID    uint      `json:"author_id"`
-	AuthorName  string    `json:"author_name"`
+	AddedBy     *uint     `json:"added_by,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	Contributors []Contributor `json:"contributors" gorm:"-"`
+
+	// Full-text search only: the relevance score and, per field, the text
+	// with matching words wrapped in <mark>
+	Score      float64           `json:"score,omitempty" gorm:"-"`
+	Highlights map[string]string `json:"highlights,omitempty" gorm:"-"`
+}
+
+// Contributor is an author credited on a book in a list of books
+type Contributor struct {
+	AuthorID uint                   `json:"author_id"`
+	Name     string                 `json:"name"`
+	Role     models.ContributorRole `json:"role"`
 }
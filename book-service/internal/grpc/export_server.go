@@ -0,0 +1,43 @@
+package grpc
+
+import (
+	"context"
+	"log"
+
+	"book-service/internal/repository"
+
+	"shared/dataexport"
+	"shared/proto/data_export"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExportServer contributes book-service's part of a user's data export:
+// the books they added. Authors are catalogue data not tied to users.
+type ExportServer struct {
+	data_export.UnimplementedDataExportServer
+	bookRepo repository.BookRepository
+}
+
+func NewExportServer(bookRepo repository.BookRepository) *ExportServer {
+	return &ExportServer{bookRepo: bookRepo}
+}
+
+func (s *ExportServer) ExportUserData(ctx context.Context, req *data_export.ExportUserDataRequest) (*data_export.ExportUserDataResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	books, err := s.bookRepo.GetByAddedBy(uint(req.UserId))
+	if err != nil {
+		log.Printf("Failed to get books for export: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get books")
+	}
+
+	file, err := dataexport.JSONFile("books_added", books)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &data_export.ExportUserDataResponse{Files: []*data_export.ExportFile{file}}, nil
+}
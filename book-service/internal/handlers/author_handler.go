@@ -8,6 +8,7 @@ import (
 	"book-service/internal/dto"
 	"book-service/internal/services"
 
+	"shared/locale"
 	"shared/utils"
 
 	"github.com/gin-gonic/gin"
@@ -35,7 +36,7 @@ func (h *AuthorHandler) CreateAuthor(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, author)
+	locale.JSON(c, http.StatusCreated, author)
 }
 
 func (h *AuthorHandler) GetAuthor(c *gin.Context) {
@@ -52,7 +53,7 @@ func (h *AuthorHandler) GetAuthor(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, author)
+	locale.JSON(c, http.StatusOK, author)
 }
 
 func (h *AuthorHandler) GetAuthors(c *gin.Context) {
@@ -62,7 +63,7 @@ func (h *AuthorHandler) GetAuthors(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"authors": authors})
+	locale.JSON(c, http.StatusOK, gin.H{"authors": authors})
 }
 
 func (h *AuthorHandler) UpdateAuthor(c *gin.Context) {
@@ -86,7 +87,7 @@ func (h *AuthorHandler) UpdateAuthor(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, author)
+	locale.JSON(c, http.StatusOK, author)
 }
 
 func (h *AuthorHandler) DeleteAuthor(c *gin.Context) {
@@ -103,5 +104,5 @@ func (h *AuthorHandler) DeleteAuthor(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Author deleted successfully"})
+	locale.JSON(c, http.StatusOK, gin.H{"message": "Author deleted successfully"})
 }
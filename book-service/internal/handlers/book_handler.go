@@ -8,6 +8,7 @@ import (
 	"book-service/internal/dto"
 	"book-service/internal/services"
 
+	"shared/locale"
 	"shared/utils"
 
 	"github.com/gin-gonic/gin"
@@ -29,13 +30,13 @@ func (h *BookHandler) CreateBook(c *gin.Context) {
 		return
 	}
 
-	book, err := h.bookService.CreateBook(req)
+	book, err := h.bookService.CreateBook(c.GetUint("user_id"), req)
 	if err != nil {
 		utils.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, book)
+	locale.JSON(c, http.StatusCreated, book)
 }
 
 func (h *BookHandler) GetBook(c *gin.Context) {
@@ -52,7 +53,19 @@ func (h *BookHandler) GetBook(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, book)
+	locale.JSON(c, http.StatusOK, book)
+}
+
+// GetBookByISBN looks a book up by its ISBN-10 or ISBN-13, with or
+// without hyphens
+func (h *BookHandler) GetBookByISBN(c *gin.Context) {
+	book, err := h.bookService.GetBookByISBN(c.Param("isbn"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, book)
 }
 
 func (h *BookHandler) GetBooks(c *gin.Context) {
@@ -62,7 +75,7 @@ func (h *BookHandler) GetBooks(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"books": books})
+	locale.JSON(c, http.StatusOK, gin.H{"books": books})
 }
 
 func (h *BookHandler) UpdateBook(c *gin.Context) {
@@ -86,7 +99,7 @@ func (h *BookHandler) UpdateBook(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, book)
+	locale.JSON(c, http.StatusOK, book)
 }
 
 func (h *BookHandler) DeleteBook(c *gin.Context) {
@@ -103,7 +116,7 @@ func (h *BookHandler) DeleteBook(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Book deleted successfully"})
+	locale.JSON(c, http.StatusOK, gin.H{"message": "Book deleted successfully"})
 }
 
 func (h *BookHandler) GetBooksByAuthor(c *gin.Context) {
@@ -120,7 +133,25 @@ func (h *BookHandler) GetBooksByAuthor(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"books": books})
+	locale.JSON(c, http.StatusOK, gin.H{"books": books})
+}
+
+// GetFeed lists books recently added by people the caller follows
+func (h *BookHandler) GetFeed(c *gin.Context) {
+	var req dto.FeedReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Println("Error binding query:", err)
+		utils.HandleError(c, utils.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	result, err := h.bookService.GetFollowingFeed(c.Request.Context(), c.GetUint("user_id"), req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, result)
 }
 
 func (h *BookHandler) SearchBooks(c *gin.Context) {
@@ -131,11 +162,11 @@ func (h *BookHandler) SearchBooks(c *gin.Context) {
 		return
 	}
 
-	result, err := h.bookService.SearchBooks(req)
+	result, err := h.bookService.SearchBooks(c.Request.Context(), c.GetUint("user_id"), req)
 	if err != nil {
 		utils.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, result)
+	locale.JSON(c, http.StatusOK, result)
 }
@@ -0,0 +1,88 @@
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalid = errors.New("invalid ISBN")
+
+// Normalize checks an ISBN-10 or ISBN-13, written with or without hyphens
+// or spaces, and returns it as 13 digits
+func Normalize(s string) (string, error) {
+	digits := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(s))
+	switch len(digits) {
+	case 10:
+		if !valid10(digits) {
+			return "", ErrInvalid
+		}
+		return To13(digits), nil
+	case 13:
+		if !valid13(digits) {
+			return "", ErrInvalid
+		}
+		return digits, nil
+	default:
+		return "", ErrInvalid
+	}
+}
+
+// To13 converts a valid ISBN-10 to ISBN-13
+func To13(isbn10 string) string {
+	body := "978" + isbn10[:9]
+	return body + string(checkDigit13(body))
+}
+
+// To10 converts a valid ISBN-13 to ISBN-10, which only exists for the 978
+// prefix
+func To10(isbn13 string) (string, bool) {
+	if len(isbn13) != 13 || !strings.HasPrefix(isbn13, "978") {
+		return "", false
+	}
+	body := isbn13[3:12]
+	return body + string(checkDigit10(body)), true
+}
+
+func valid10(digits string) bool {
+	for _, r := range digits[:9] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return digits[9] == checkDigit10(digits[:9])
+}
+
+func valid13(digits string) bool {
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return digits[12] == checkDigit13(digits[:12])
+}
+
+// checkDigit10 weighs the digits 10 down to 2, X stands for 10
+func checkDigit10(body string) byte {
+	sum := 0
+	for i := range 9 {
+		sum += int(body[i]-'0') * (10 - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// checkDigit13 weighs the digits alternately 1 and 3
+func checkDigit13(body string) byte {
+	sum := 0
+	for i := range 12 {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+		sum += int(body[i]-'0') * weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
@@ -0,0 +1,107 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "ISBN-13", in: "9780306406157", want: "9780306406157"},
+		{name: "ISBN-13 with hyphens", in: "978-0-306-40615-7", want: "9780306406157"},
+		{name: "ISBN-13 with spaces", in: "978 0 306 40615 7", want: "9780306406157"},
+		{name: "ISBN-13 with extra spaces", in: "978  0306 - 40615 7", want: "9780306406157"},
+		{name: "ISBN-10", in: "0306406152", want: "9780306406157"},
+		{name: "ISBN-10 with hyphens", in: "0-306-40615-2", want: "9780306406157"},
+		{name: "ISBN-10 with X check digit", in: "0-8044-2957-X", want: "9780804429573"},
+		{name: "lower case x", in: "080442957x", want: "9780804429573"},
+		{name: "979 prefix", in: "979-10-90636-07-1", want: "9791090636071"},
+		{name: "ISBN-13 wrong checksum", in: "978-0-306-40615-8", wantErr: true},
+		{name: "ISBN-10 wrong checksum", in: "0-306-40615-3", wantErr: true},
+		{name: "X where the check digit is a number", in: "030640615X", wantErr: true},
+		{name: "X in the body", in: "03064X6152", wantErr: true},
+		{name: "X in an ISBN-13", in: "978080442957X", wantErr: true},
+		{name: "letters", in: "978-0-306-4061A-7", wantErr: true},
+		{name: "too short", in: "978030640615", wantErr: true},
+		{name: "too long", in: "97803064061570", wantErr: true},
+		{name: "between lengths", in: "03064061521", wantErr: true},
+		{name: "other separators", in: "978.0.306.40615.7", wantErr: true},
+		{name: "empty", in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Errorf("Normalize(%q) = %q, %v, want ErrInvalid", tt.in, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTo13(t *testing.T) {
+	tests := []struct {
+		isbn10 string
+		want   string
+	}{
+		{isbn10: "0306406152", want: "9780306406157"},
+		{isbn10: "080442957X", want: "9780804429573"},
+		{isbn10: "0134190440", want: "9780134190440"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.isbn10, func(t *testing.T) {
+			if got := To13(tt.isbn10); got != tt.want {
+				t.Errorf("To13(%q) = %q, want %q", tt.isbn10, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTo10(t *testing.T) {
+	tests := []struct {
+		isbn13 string
+		want   string
+		wantOK bool
+	}{
+		{isbn13: "9780306406157", want: "0306406152", wantOK: true},
+		{isbn13: "9780804429573", want: "080442957X", wantOK: true},
+		{isbn13: "9780134190440", want: "0134190440", wantOK: true},
+		{isbn13: "9791090636071", wantOK: false}, // 979 has no ISBN-10
+		{isbn13: "978030640615", wantOK: false},
+		{isbn13: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.isbn13, func(t *testing.T) {
+			got, ok := To10(tt.isbn13)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("To10(%q) = %q, %v, want %q, %v", tt.isbn13, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// Converting back and forth must give the ISBN that went in
+func TestTo10To13RoundTrip(t *testing.T) {
+	for _, isbn10 := range []string{"0306406152", "080442957X", "0134190440"} {
+		isbn10Again, ok := To10(To13(isbn10))
+		if !ok || isbn10Again != isbn10 {
+			t.Errorf("To10(To13(%q)) = %q, %v", isbn10, isbn10Again, ok)
+		}
+	}
+}
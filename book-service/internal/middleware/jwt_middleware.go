@@ -4,10 +4,13 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
 
 	"book-service/internal/clients"
 
+	"shared/locale"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,7 +27,7 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 		log.Println("authHeader", authHeader)
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
+			locale.JSON(c, http.StatusUnauthorized, gin.H{
 				"error": "Authorization header required",
 			})
 			c.Abort()
@@ -32,7 +35,7 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		}
 
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{
+			locale.JSON(c, http.StatusUnauthorized, gin.H{
 				"error": "Invalid authorization format. Use 'Bearer <token>'",
 			})
 			c.Abort()
@@ -46,7 +49,7 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		response, err := m.authClient.ValidateToken(ctx, tokenString)
 		if err != nil {
 			log.Printf("Failed to call auth service: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
+			locale.JSON(c, http.StatusInternalServerError, gin.H{
 				"error": "Authentication service unavailable",
 			})
 			c.Abort()
@@ -54,7 +57,7 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		}
 
 		if !response.IsValid {
-			c.JSON(http.StatusUnauthorized, gin.H{
+			locale.JSON(c, http.StatusUnauthorized, gin.H{
 				"error": response.ErrorMessage,
 			})
 			c.Abort()
@@ -67,6 +70,24 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		c.Set("user_email", response.Claims.Email)
 		c.Set("user_id", uint(response.Claims.UserId))
 		c.Set("user_claims", response.Claims)
+		c.Set("token_scope", response.Claims.Scope)
+		c.Set(locale.UserLocaleKey, response.Claims.Locale)
+		c.Set(locale.UserTimezoneKey, response.Claims.Timezone)
+
+		c.Next()
+	}
+}
+
+// RequireScope rejects downscoped tokens that weren't granted scope.
+// Regular user tokens carry no scope and are always allowed.
+func (m *JWTMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenScope := c.GetString("token_scope")
+		if tokenScope != "" && !slices.Contains(strings.Fields(tokenScope), scope) {
+			locale.JSON(c, http.StatusForbidden, gin.H{"error": "Token is missing required scope: " + scope})
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -3,21 +3,20 @@ package models
 import (
 	"time"
 
+	"shared/locale"
+
 	"gorm.io/gorm"
 )
 
 type Author struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
-	Name      string         `gorm:"not null;size:255" json:"name"`
+	Name      string         `gorm:"not null;size:255;index:idx_authors_name_ft,class:FULLTEXT" json:"name"`
 	Bio       string         `gorm:"type:text" json:"bio"`
-	BirthDate *time.Time     `json:"birth_date,omitempty"`
+	BirthDate *locale.Date   `json:"birth_date,omitempty"`
 	Country   string         `gorm:"size:100" json:"country"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
-
-	// One-to-many relationship with Books
-	Books []Book `gorm:"foreignKey:AuthorID;constraint:OnDelete:CASCADE" json:"books,omitempty"`
 }
 
 func (Author) TableName() string {
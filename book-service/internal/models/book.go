@@ -3,25 +3,48 @@ package models
 import (
 	"time"
 
+	"book-service/internal/isbn"
+
 	"gorm.io/gorm"
 )
 
 type Book struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
-	Title       string         `gorm:"not null;size:255" json:"title"`
-	Description string         `gorm:"type:text" json:"description"`
-	PublishYear int            `gorm:"not null" json:"publish_year"`
-	ISBN        string         `gorm:"uniqueIndex;size:17" json:"isbn"`
+	Title       string         `gorm:"not null;size:255;index;index:idx_books_title_ft,class:FULLTEXT" json:"title"`
+	Description string         `gorm:"type:text;index:idx_books_description_ft,class:FULLTEXT" json:"description"`
+	PublishYear int            `gorm:"not null;index" json:"publish_year"`
+	ISBN        *string        `gorm:"uniqueIndex;size:17" json:"isbn,omitempty"` // ISBN-13 digits, see isbn.Normalize
+	ISBNDisplay *string        `gorm:"size:17" json:"isbn_display,omitempty"`     // as it was entered
 	Genre       string         `gorm:"size:100" json:"genre"`
 	Pages       int            `json:"pages"`
-	Price       float64        `gorm:"type:decimal(10,2)" json:"price"`
-	AuthorID    uint           `gorm:"not null" json:"author_id"`
-	CreatedAt   time.Time      `json:"created_at"`
+	Price       float64        `gorm:"type:decimal(10,2);index" json:"price"`
+	AddedBy     *uint          `gorm:"index" json:"added_by,omitempty"` // user-service user ID, unset for older books
+	CreatedAt   time.Time      `gorm:"index" json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
-	// Many-to-one relationship with Author
-	Author Author `gorm:"foreignKey:AuthorID" json:"author,omitempty"`
+	// Many-to-many relationship with Author, in credit order
+	Contributors []BookContributor `gorm:"foreignKey:BookID" json:"contributors"`
+
+	// ISBN10 is derived from ISBN when the book has one
+	ISBN10 string `gorm:"-" json:"isbn_10,omitempty"`
+}
+
+func (b *Book) AfterFind(tx *gorm.DB) error {
+	b.setISBN10()
+	return nil
+}
+
+func (b *Book) AfterSave(tx *gorm.DB) error {
+	b.setISBN10()
+	return nil
+}
+
+func (b *Book) setISBN10() {
+	b.ISBN10 = ""
+	if b.ISBN != nil {
+		b.ISBN10, _ = isbn.To10(*b.ISBN)
+	}
 }
 
 func (Book) TableName() string {
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type ContributorRole string
+
+const (
+	ContributorRoleAuthor      ContributorRole = "author"
+	ContributorRoleEditor      ContributorRole = "editor"
+	ContributorRoleTranslator  ContributorRole = "translator"
+	ContributorRoleIllustrator ContributorRole = "illustrator"
+)
+
+// BookContributor credits an author with a role on a book. Position orders
+// a book's contributors, starting at 0.
+type BookContributor struct {
+	ID        uint            `gorm:"primaryKey" json:"-"`
+	BookID    uint            `gorm:"not null;uniqueIndex:idx_book_contributors_book_author_role" json:"-"`
+	AuthorID  uint            `gorm:"not null;uniqueIndex:idx_book_contributors_book_author_role;index" json:"author_id"`
+	Role      ContributorRole `gorm:"not null;size:20;uniqueIndex:idx_book_contributors_book_author_role" json:"role"`
+	Position  int             `gorm:"not null" json:"position"`
+	CreatedAt time.Time       `json:"-"`
+
+	Author Author `gorm:"foreignKey:AuthorID" json:"author"`
+}
+
+func (BookContributor) TableName() string {
+	return "book_contributors"
+}
@@ -1,20 +1,46 @@
 package repository
 
 import (
+	"fmt"
+
 	"book-service/internal/dto"
 	"book-service/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookRepository interface {
 	Create(book *models.Book) error
 	GetByID(id uint) (*models.Book, error)
+	GetByISBN(isbn13 string) (*models.Book, error)
 	GetAll() ([]models.Book, error)
 	Update(book *models.Book) error
 	Delete(id uint) error
 	GetByAuthorID(authorID uint) ([]models.Book, error)
-	SearchBooks(req dto.SearchBooksReq) ([]dto.BookWithAuthor, int64, error)
+	SearchBooks(req dto.SearchBooksReq, after *SearchCursor, limit int) ([]dto.BookWithContributors, error)
+	CountBooks(req dto.SearchBooksReq) (int64, error)
+	Facets(req dto.SearchBooksReq) (*dto.SearchFacets, error)
+	GetAddedBy(userIDs []uint, beforeID uint, limit int) ([]dto.BookWithContributors, error)
+	GetByAddedBy(userID uint) ([]models.Book, error)
+}
+
+const bookSummaryColumns = "books.id, books.title, books.description, books.publish_year, books.isbn, books.isbn_display, books.genre, books.pages, books.price, books.added_by, books.created_at, books.updated_at"
+
+// SearchCursor is the last book of the previous page, Value is its sort
+// column and is unused when sorting by ID only
+type SearchCursor struct {
+	Value any
+	ID    uint
+}
+
+// searchSortColumns maps SearchBooksReq.Sort to columns, books.id breaks
+// ties so the order is total
+var searchSortColumns = map[string]string{
+	"title":        "books.title",
+	"publish_year": "books.publish_year",
+	"price":        "books.price",
+	"created_at":   "books.created_at",
 }
 
 type bookRepository struct {
@@ -25,13 +51,27 @@ func NewBookRepository(db *gorm.DB) BookRepository {
 	return &bookRepository{db: db}
 }
 
+// Create saves a book with its contributors
 func (r *bookRepository) Create(book *models.Book) error {
-	return r.db.Create(book).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(book).Error; err != nil {
+			return err
+		}
+		return saveContributors(tx, book)
+	})
 }
 
 func (r *bookRepository) GetByID(id uint) (*models.Book, error) {
 	var book models.Book
-	if err := r.db.Preload("Author").Where("id = ?", id).First(&book).Error; err != nil {
+	if err := preloadContributors(r.db).Where("id = ?", id).First(&book).Error; err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+func (r *bookRepository) GetByISBN(isbn13 string) (*models.Book, error) {
+	var book models.Book
+	if err := preloadContributors(r.db).Where("isbn = ?", isbn13).First(&book).Error; err != nil {
 		return nil, err
 	}
 	return &book, nil
@@ -39,39 +79,123 @@ func (r *bookRepository) GetByID(id uint) (*models.Book, error) {
 
 func (r *bookRepository) GetAll() ([]models.Book, error) {
 	var books []models.Book
-	if err := r.db.Preload("Author").Find(&books).Error; err != nil {
+	if err := preloadContributors(r.db).Find(&books).Error; err != nil {
 		return nil, err
 	}
 	return books, nil
 }
 
+// Update saves a book and replaces its contributors with book.Contributors
 func (r *bookRepository) Update(book *models.Book) error {
-	return r.db.Save(book).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(book).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("book_id = ?", book.ID).Delete(&models.BookContributor{}).Error; err != nil {
+			return err
+		}
+		return saveContributors(tx, book)
+	})
+}
+
+func saveContributors(tx *gorm.DB, book *models.Book) error {
+	if len(book.Contributors) == 0 {
+		return nil
+	}
+	for i := range book.Contributors {
+		book.Contributors[i].ID = 0
+		book.Contributors[i].BookID = book.ID
+		book.Contributors[i].Position = i
+	}
+	return tx.Omit("Author").Create(&book.Contributors).Error
+}
+
+// preloadContributors loads a book's contributors in credit order with
+// their authors
+func preloadContributors(db *gorm.DB) *gorm.DB {
+	return db.Preload("Contributors", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("position")
+	}).Preload("Contributors.Author")
 }
 
+// Delete soft deletes a book and frees its ISBN, which the unique index
+// would otherwise keep taken
 func (r *bookRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Book{}, id).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Book{}).Where("id = ?", id).Update("isbn", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Book{}, id).Error
+	})
 }
 
+// GetByAuthorID returns every book authorID contributed to, in any role
 func (r *bookRepository) GetByAuthorID(authorID uint) ([]models.Book, error) {
 	var books []models.Book
-	if err := r.db.Preload("Author").Where("author_id = ?", authorID).Find(&books).Error; err != nil {
+	err := preloadContributors(r.db).
+		Where("id IN (?)", r.db.Model(&models.BookContributor{}).Select("book_id").Where("author_id = ?", authorID)).
+		Find(&books).Error
+	if err != nil {
 		return nil, err
 	}
 	return books, nil
 }
 
-func (r *bookRepository) SearchBooks(req dto.SearchBooksReq) ([]dto.BookWithAuthor, int64, error) {
-	var books []dto.BookWithAuthor
+// SearchBooks returns up to limit books matching req in req.Sort order,
+// starting after the cursor when it is set and at req.Page otherwise
+func (r *bookRepository) SearchBooks(req dto.SearchBooksReq, after *SearchCursor, limit int) ([]dto.BookWithContributors, error) {
+	var books []dto.BookWithContributors
+
+	direction, compare := "ASC", ">"
+	if req.Order == "desc" {
+		direction, compare = "DESC", "<"
+	}
+	column := searchSortColumns[req.Sort]
+
+	query := r.searchQuery(req)
+	if after != nil {
+		// Keyset pagination, spelled out rather than as a row comparison
+		// so MySQL can use the sort column's index
+		if column == "" {
+			query = query.Where("books.id "+compare+" ?", after.ID)
+		} else {
+			query = query.Where("("+column+" "+compare+" ? OR ("+column+" = ? AND books.id "+compare+" ?))", after.Value, after.Value, after.ID)
+		}
+	} else if req.Page > 1 {
+		query = query.Offset((req.Page - 1) * req.Limit)
+	}
+
+	if column != "" {
+		query = query.Order(column + " " + direction)
+	}
+	query = query.Order("books.id " + direction)
+
+	if err := query.Limit(limit).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	if err := r.attachContributors(books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// CountBooks counts every book matching req's filters
+func (r *bookRepository) CountBooks(req dto.SearchBooksReq) (int64, error) {
 	var total int64
+	err := r.searchQuery(req).Count(&total).Error
+	return total, err
+}
 
+func (r *bookRepository) searchQuery(req dto.SearchBooksReq) *gorm.DB {
 	query := r.db.Model(&models.Book{}).
-		Select("books.id, books.title, books.description, books.publish_year, books.isbn, books.genre, books.pages, books.price, books.author_id, authors.name as author_name, books.created_at, books.updated_at").
-		Joins("JOIN authors ON books.author_id = authors.id")
+		Select(bookSummaryColumns)
 
 	//  Filters
+	if req.BookIDs != nil {
+		query = query.Where("books.id IN ?", req.BookIDs)
+	}
 	if req.AuthorName != "" {
-		query = query.Where("authors.name LIKE ?", "%"+req.AuthorName+"%")
+		query = query.Where("EXISTS (SELECT 1 FROM book_contributors bc JOIN authors a ON a.id = bc.author_id WHERE bc.book_id = books.id AND a.name LIKE ?)", "%"+req.AuthorName+"%")
 	}
 	if req.BookTitle != "" {
 		query = query.Where("books.title LIKE ?", "%"+req.BookTitle+"%")
@@ -79,23 +203,187 @@ func (r *bookRepository) SearchBooks(req dto.SearchBooksReq) ([]dto.BookWithAuth
 	if req.PublishYear > 0 {
 		query = query.Where("books.publish_year = ?", req.PublishYear)
 	}
-	if req.Genre != "" {
-		query = query.Where("books.genre LIKE ?", "%"+req.Genre+"%")
+	if len(req.Genres) > 0 {
+		query = query.Where("books.genre IN ?", req.Genres)
+	}
+	if len(req.AuthorIDs) > 0 {
+		query = query.Where("books.id IN (SELECT book_id FROM book_contributors WHERE author_id IN ?)", req.AuthorIDs)
+	}
+	if len(req.Decades) > 0 {
+		// Ranges rather than publish_year DIV 10 so the index is usable
+		decades := r.db
+		for _, decade := range req.Decades {
+			start := decade - decade%10
+			decades = decades.Or("books.publish_year BETWEEN ? AND ?", start, start+9)
+		}
+		query = query.Where(decades)
+	}
+	if req.MinPrice != nil {
+		query = query.Where("books.price >= ?", *req.MinPrice)
+	}
+	if req.MaxPrice != nil {
+		query = query.Where("books.price < ?", *req.MaxPrice)
+	}
+	if req.MinPages != nil {
+		query = query.Where("books.pages >= ?", *req.MinPages)
+	}
+	if req.MaxPages != nil {
+		query = query.Where("books.pages < ?", *req.MaxPages)
+	}
+	return query
+}
+
+// facetLimit caps the genres and authors a facet lists, most books first
+const facetLimit = 20
+
+// priceBandEdges split prices into the bands of the price facet
+var priceBandEdges = []float64{10, 25, 50, 100}
+
+// Facets counts req's matches per genre, decade, price band and author,
+// leaving each facet's own filter out of its count
+func (r *bookRepository) Facets(req dto.SearchBooksReq) (*dto.SearchFacets, error) {
+	facets := &dto.SearchFacets{
+		Genres:  []dto.FacetCount{},
+		Decades: []dto.FacetCount{},
+		Prices:  []dto.PriceBand{},
+		Authors: []dto.FacetCount{},
+	}
+
+	genreReq := req
+	genreReq.Genres = nil
+	err := r.searchQuery(genreReq).
+		Select("books.genre AS value, COUNT(*) AS count").
+		Where("books.genre <> ''").
+		Group("books.genre").
+		Order("count DESC, value").
+		Limit(facetLimit).
+		Scan(&facets.Genres).Error
+	if err != nil {
+		return nil, err
+	}
+
+	decadeReq := req
+	decadeReq.Decades = nil
+	err = r.searchQuery(decadeReq).
+		Select("books.publish_year - MOD(books.publish_year, 10) AS value, COUNT(*) AS count").
+		Group("value").
+		Order("value DESC").
+		Scan(&facets.Decades).Error
+	if err != nil {
+		return nil, err
 	}
 
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	authorReq := req
+	authorReq.AuthorIDs = nil
+	err = r.searchQuery(authorReq).
+		Select("book_contributors.author_id AS value, authors.name AS label, COUNT(DISTINCT books.id) AS count").
+		Joins("JOIN book_contributors ON book_contributors.book_id = books.id").
+		Joins("JOIN authors ON authors.id = book_contributors.author_id").
+		Group("book_contributors.author_id, authors.name").
+		Order("count DESC, label").
+		Limit(facetLimit).
+		Scan(&facets.Authors).Error
+	if err != nil {
+		return nil, err
 	}
 
-	// Pagination
-	if req.Page > 0 && req.Limit > 0 {
-		offset := (req.Page - 1) * req.Limit
-		query = query.Offset(offset).Limit(req.Limit)
+	priceReq := req
+	priceReq.MinPrice, priceReq.MaxPrice = nil, nil
+	band := "CASE"
+	for i, edge := range priceBandEdges {
+		band += fmt.Sprintf(" WHEN books.price < %g THEN %d", edge, i)
 	}
+	band += fmt.Sprintf(" ELSE %d END", len(priceBandEdges))
 
-	if err := query.Find(&books).Error; err != nil {
-		return nil, 0, err
+	var bands []struct {
+		Band  int
+		Count int64
+	}
+	err = r.searchQuery(priceReq).
+		Select(band + " AS band, COUNT(*) AS count").
+		Group("band").
+		Scan(&bands).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make([]int64, len(priceBandEdges)+1)
+	for _, b := range bands {
+		counts[b.Band] = b.Count
+	}
+	for i, count := range counts {
+		priceBand := dto.PriceBand{Count: count}
+		if i > 0 {
+			priceBand.Min = priceBandEdges[i-1]
+		}
+		if i < len(priceBandEdges) {
+			max := priceBandEdges[i]
+			priceBand.Max = &max
+		}
+		facets.Prices = append(facets.Prices, priceBand)
 	}
 
-	return books, total, nil
+	return facets, nil
+}
+
+// GetAddedBy returns the newest books added by any of userIDs, starting
+// below beforeID when it is set
+func (r *bookRepository) GetAddedBy(userIDs []uint, beforeID uint, limit int) ([]dto.BookWithContributors, error) {
+	var books []dto.BookWithContributors
+	query := r.db.Model(&models.Book{}).
+		Select(bookSummaryColumns).
+		Where("books.added_by IN ?", userIDs)
+	if beforeID > 0 {
+		query = query.Where("books.id < ?", beforeID)
+	}
+	if err := query.Order("books.id DESC").Limit(limit).Find(&books).Error; err != nil {
+		return nil, err
+	}
+	if err := r.attachContributors(books); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// attachContributors fills in each book's contributors in credit order
+func (r *bookRepository) attachContributors(books []dto.BookWithContributors) error {
+	if len(books) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(books))
+	for i, book := range books {
+		ids[i] = book.ID
+	}
+
+	var rows []struct {
+		BookID uint
+		dto.Contributor
+	}
+	err := r.db.Model(&models.BookContributor{}).
+		Select("book_contributors.book_id, book_contributors.author_id, authors.name, book_contributors.role").
+		Joins("JOIN authors ON authors.id = book_contributors.author_id").
+		Where("book_contributors.book_id IN ?", ids).
+		Order("book_contributors.book_id, book_contributors.position").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	byBook := map[uint][]dto.Contributor{}
+	for _, row := range rows {
+		byBook[row.BookID] = append(byBook[row.BookID], row.Contributor)
+	}
+	for i := range books {
+		books[i].Contributors = byBook[books[i].ID]
+		if books[i].Contributors == nil {
+			books[i].Contributors = []dto.Contributor{}
+		}
+	}
+	return nil
+}
+
+// GetByAddedBy returns every book userID added, oldest first
+func (r *bookRepository) GetByAddedBy(userID uint) ([]models.Book, error) {
+	var books []models.Book
+	err := r.db.Where("added_by = ?", userID).Order("id").Find(&books).Error
+	return books, err
 }
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+// Tokenize splits text into lower case words, dropping single characters
+func Tokenize(text string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(text, isSeparator) {
+		if len([]rune(word)) > 1 {
+			tokens = append(tokens, strings.ToLower(word))
+		}
+	}
+	return tokens
+}
+
+// Terms is the set of tokens in a query
+func Terms(query string) map[string]bool {
+	terms := map[string]bool{}
+	for _, token := range Tokenize(query) {
+		terms[token] = true
+	}
+	return terms
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+// Highlight HTML escapes text and wraps the words in terms in <mark>. When
+// text is longer than width runes only a window around the first match is
+// kept. It returns "" when no word matches.
+func Highlight(text string, terms map[string]bool, width int) string {
+	runes := []rune(text)
+
+	type span struct{ start, end int }
+	var matches []span
+	for start := 0; start < len(runes); {
+		if isSeparator(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && !isSeparator(runes[end]) {
+			end++
+		}
+		if terms[strings.ToLower(string(runes[start:end]))] {
+			matches = append(matches, span{start, end})
+		}
+		start = end
+	}
+	if len(matches) == 0 {
+		return ""
+	}
+
+	from, to := 0, len(runes)
+	if width > 0 && len(runes) > width {
+		from = max(0, matches[0].start-width/4)
+		to = min(len(runes), from+width)
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, match := range matches {
+		if match.start < from {
+			continue
+		}
+		if match.end > to {
+			break
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:match.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[match.start:match.end])))
+		b.WriteString("</mark>")
+		pos = match.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
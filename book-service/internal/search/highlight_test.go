@@ -0,0 +1,41 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	long := strings.Repeat("a ", 20) + "target" + strings.Repeat(" b", 20)
+
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		width int
+		want  string
+	}{
+		{name: "no match", text: "The Go Programming Language", query: "rust", want: ""},
+		{name: "empty text", text: "", query: "go", want: ""},
+		{name: "whole words only", text: "Gopher going", query: "go", want: ""},
+		{name: "case insensitive", text: "The Go Programming Language", query: "go", want: "The <mark>Go</mark> Programming Language"},
+		{name: "every match", text: "go to go", query: "go", want: "<mark>go</mark> to <mark>go</mark>"},
+		{name: "several terms", text: "Go and Rust", query: "rust go", want: "<mark>Go</mark> and <mark>Rust</mark>"},
+		{name: "html is escaped", text: "<b>Go</b> & Rust", query: "go", want: "&lt;b&gt;<mark>Go</mark>&lt;/b&gt; &amp; Rust"},
+		{name: "unicode", text: "Dế Mèn phiêu lưu ký", query: "mèn", want: "Dế <mark>Mèn</mark> phiêu lưu ký"},
+		{name: "short text is not trimmed", text: "Go and Rust", query: "rust", width: 50, want: "Go and <mark>Rust</mark>"},
+		{name: "no width keeps everything", text: long, query: "target", want: strings.Repeat("a ", 20) + "<mark>target</mark>" + strings.Repeat(" b", 20)},
+		{name: "window around the first match", text: long, query: "target", width: 20, want: "… a a <mark>target</mark> b b b b …"},
+		{name: "window at the start", text: "target" + strings.Repeat(" b", 20), query: "target", width: 10, want: "<mark>target</mark> b b…"},
+		{name: "window at the end", text: strings.Repeat("a ", 20) + "target", query: "target", width: 10, want: "…a <mark>target</mark>"},
+		{name: "matches cut by the window are not marked", text: long + " target", query: "target", width: 20, want: "… a a <mark>target</mark> b b b b …"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, Terms(tt.query), tt.width); got != tt.want {
+				t.Errorf("Highlight() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// BM25 parameters, the usual defaults
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// memoryIndex is an in-process inverted index ranked with BM25, for tests
+// and deployments small enough to hold every book in memory. It starts
+// empty, see Fill.
+type memoryIndex struct {
+	mu          sync.RWMutex
+	docs        map[uint]memoryDoc
+	postings    map[string]map[uint]struct{}
+	totalLength float64
+}
+
+type memoryDoc struct {
+	// Term frequencies weighted by the field the term is in
+	terms  map[string]float64
+	length float64
+}
+
+func NewMemoryIndex() SearchIndex {
+	return &memoryIndex{
+		docs:     map[uint]memoryDoc{},
+		postings: map[string]map[uint]struct{}{},
+	}
+}
+
+// Fill indexes every document, for loading an index at startup
+func Fill(index SearchIndex, docs []Document) error {
+	for _, doc := range docs {
+		if err := index.Index(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *memoryIndex) Search(ctx context.Context, query string, limit int) ([]Match, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	if len(i.docs) == 0 {
+		return nil, nil
+	}
+	avgLength := i.totalLength / float64(len(i.docs))
+
+	scores := map[uint]float64{}
+	for term := range Terms(query) {
+		postings := i.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		n := float64(len(postings))
+		idf := math.Log(1 + (float64(len(i.docs))-n+0.5)/(n+0.5))
+		for bookID := range postings {
+			doc := i.docs[bookID]
+			tf := doc.terms[term]
+			scores[bookID] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*doc.length/avgLength))
+		}
+	}
+
+	matches := make([]Match, 0, len(scores))
+	for bookID, score := range scores {
+		matches = append(matches, Match{BookID: bookID, Score: score})
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].BookID < matches[b].BookID
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches, nil
+}
+
+func (i *memoryIndex) Index(doc Document) error {
+	indexed := memoryDoc{terms: map[string]float64{}}
+	for _, field := range []struct {
+		text   string
+		weight float64
+	}{
+		{doc.Title, titleWeight},
+		{doc.Description, descriptionWeight},
+		{strings.Join(doc.AuthorNames, " "), authorNameWeight},
+	} {
+		for _, token := range Tokenize(field.text) {
+			indexed.terms[token] += field.weight
+			indexed.length++
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(doc.BookID)
+	i.docs[doc.BookID] = indexed
+	i.totalLength += indexed.length
+	for term := range indexed.terms {
+		if i.postings[term] == nil {
+			i.postings[term] = map[uint]struct{}{}
+		}
+		i.postings[term][doc.BookID] = struct{}{}
+	}
+	return nil
+}
+
+func (i *memoryIndex) Remove(bookID uint) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(bookID)
+	return nil
+}
+
+func (i *memoryIndex) remove(bookID uint) {
+	doc, ok := i.docs[bookID]
+	if !ok {
+		return
+	}
+	for term := range doc.terms {
+		delete(i.postings[term], bookID)
+		if len(i.postings[term]) == 0 {
+			delete(i.postings, term)
+		}
+	}
+	i.totalLength -= doc.length
+	delete(i.docs, bookID)
+}
@@ -0,0 +1,200 @@
+package search
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func bookIDs(matches []Match) []uint {
+	ids := []uint{}
+	for _, match := range matches {
+		ids = append(ids, match.BookID)
+	}
+	return ids
+}
+
+func search(t *testing.T, index SearchIndex, query string, limit int) []uint {
+	t.Helper()
+	matches, err := index.Search(context.Background(), query, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bookIDs(matches)
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "", want: nil},
+		{text: "The Go Programming Language", want: []string{"the", "go", "programming", "language"}},
+		{text: "C & a b++ x", want: nil},
+		{text: "don't panic!", want: []string{"don", "panic"}},
+		{text: "Dế Mèn phiêu lưu ký", want: []string{"dế", "mèn", "phiêu", "lưu", "ký"}},
+		{text: "ISBN 978-0134190440", want: []string{"isbn", "978", "0134190440"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  map[string]bool
+	}{
+		{query: "", want: map[string]bool{}},
+		{query: "Go go GO", want: map[string]bool{"go": true}},
+		{query: "  rust, Go ", want: map[string]bool{"rust": true, "go": true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := Terms(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Terms(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryIndexSearch(t *testing.T) {
+	// Every document is six tokens long, so only the field holding
+	// "golang" sets the order
+	index := NewMemoryIndex()
+	err := Fill(index, []Document{
+		{BookID: 1, Title: "cooking basics", Description: "golang words", AuthorNames: []string{"ann lee"}},
+		{BookID: 2, Title: "golang basics", Description: "simple words", AuthorNames: []string{"ann lee"}},
+		{BookID: 3, Title: "cooking basics", Description: "simple words", AuthorNames: []string{"golang", "lee"}},
+		{BookID: 4, Title: "cooking basics", Description: "simple words", AuthorNames: []string{"ann lee"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		limit int
+		want  []uint
+	}{
+		{name: "title beats author beats description", query: "golang", limit: 10, want: []uint{2, 3, 1}},
+		{name: "case and punctuation are ignored", query: "GoLang!", limit: 10, want: []uint{2, 3, 1}},
+		{name: "limit keeps the best", query: "golang", limit: 2, want: []uint{2, 3}},
+		{name: "more matching terms rank higher", query: "golang ann", limit: 10, want: []uint{2, 1, 3, 4}},
+		{name: "ties go to the lower ID", query: "cooking", limit: 10, want: []uint{1, 3, 4}},
+		{name: "no match", query: "rust", limit: 10, want: []uint{}},
+		{name: "only short words", query: "a b", limit: 10, want: []uint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := search(t, index, tt.query, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryIndexScoresAreWeighted(t *testing.T) {
+	index := NewMemoryIndex()
+	Fill(index, []Document{
+		{BookID: 1, Title: "golang basics"},
+		{BookID: 2, Title: "cooking basics", AuthorNames: []string{"golang"}},
+	})
+
+	matches, err := index.Search(context.Background(), "golang", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 2 || matches[0].BookID != 1 || matches[0].Score <= matches[1].Score {
+		t.Errorf("want book 1 scored above book 2, got %+v", matches)
+	}
+}
+
+func TestMemoryIndexChanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(index SearchIndex)
+		query  string
+		want   []uint
+	}{
+		{
+			name: "indexing again replaces the document",
+			change: func(index SearchIndex) {
+				index.Index(Document{BookID: 1, Title: "rust in action"})
+			},
+			query: "golang",
+			want:  []uint{2},
+		},
+		{
+			name: "the replacement is searchable",
+			change: func(index SearchIndex) {
+				index.Index(Document{BookID: 1, Title: "rust in action"})
+			},
+			query: "rust",
+			want:  []uint{1},
+		},
+		{
+			name: "remove",
+			change: func(index SearchIndex) {
+				index.Remove(2)
+			},
+			query: "golang",
+			want:  []uint{1},
+		},
+		{
+			name: "removing an unknown book does nothing",
+			change: func(index SearchIndex) {
+				index.Remove(99)
+			},
+			query: "golang",
+			want:  []uint{2, 1}, // the shorter title ranks first
+		},
+		{
+			name: "remove everything",
+			change: func(index SearchIndex) {
+				index.Remove(1)
+				index.Remove(2)
+			},
+			query: "golang",
+			want:  []uint{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewMemoryIndex()
+			Fill(index, []Document{
+				{BookID: 1, Title: "golang in action"},
+				{BookID: 2, Title: "learning golang"},
+			})
+
+			tt.change(index)
+
+			if got := search(t, index, tt.query, 10); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// Replacing a document must not leave its old terms or length behind
+func TestMemoryIndexReplaceKeepsStatsExact(t *testing.T) {
+	index := NewMemoryIndex().(*memoryIndex)
+	index.Index(Document{BookID: 1, Title: "golang in action", Description: "a long description of go"})
+	index.Index(Document{BookID: 1, Title: "rust"})
+
+	if index.totalLength != 1 {
+		t.Errorf("totalLength = %v, want 1", index.totalLength)
+	}
+	if len(index.postings) != 1 || index.postings["rust"] == nil {
+		t.Errorf("postings = %v, want only rust", index.postings)
+	}
+}
@@ -0,0 +1,44 @@
+package search
+
+import "context"
+
+// Matches in a title count double and in an author's name one and a half
+// times a match in a description, in both indexes
+const (
+	titleWeight       = 2.0
+	authorNameWeight  = 1.5
+	descriptionWeight = 1.0
+)
+
+// Document is what gets indexed for a book, AuthorNames are the names of
+// all its contributors
+type Document struct {
+	BookID      uint
+	Title       string
+	Description string
+	AuthorNames []string
+}
+
+// Match is a book found by a query, higher scores are better matches.
+// Scores are only comparable within one index.
+type Match struct {
+	BookID uint
+	Score  float64
+}
+
+// SearchIndex finds books by relevance to a free text query across titles,
+// descriptions and author names
+type SearchIndex interface {
+	// Search returns up to limit matches, best first
+	Search(ctx context.Context, query string, limit int) ([]Match, error)
+	// Index adds or replaces a book and Remove drops it. An index the
+	// database maintains itself ignores both.
+	Index(doc Document) error
+	Remove(bookID uint) error
+}
+
+// Kinds of SearchIndex, see SEARCH_INDEX
+const (
+	KindMySQL  = "mysql"
+	KindMemory = "memory"
+)
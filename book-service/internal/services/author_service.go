@@ -2,10 +2,12 @@ package services
 
 import (
 	"errors"
+	"log"
 
 	"book-service/internal/dto"
 	"book-service/internal/models"
 	"book-service/internal/repository"
+	"book-service/internal/search"
 
 	"shared/utils"
 
@@ -21,14 +23,16 @@ type AuthorService interface {
 }
 
 type authorService struct {
-	authorRepo repository.AuthorRepository
-	bookRepo   repository.BookRepository
+	authorRepo  repository.AuthorRepository
+	bookRepo    repository.BookRepository
+	searchIndex search.SearchIndex
 }
 
-func NewAuthorService(authorRepo repository.AuthorRepository, bookRepo repository.BookRepository) AuthorService {
+func NewAuthorService(authorRepo repository.AuthorRepository, bookRepo repository.BookRepository, searchIndex search.SearchIndex) AuthorService {
 	return &authorService{
-		authorRepo: authorRepo,
-		bookRepo:   bookRepo,
+		authorRepo:  authorRepo,
+		bookRepo:    bookRepo,
+		searchIndex: searchIndex,
 	}
 }
 
@@ -109,6 +113,17 @@ func (s *authorService) UpdateAuthor(id uint, req dto.UpdateAuthorReq) (*models.
 		return nil, utils.InternalServerError("Failed to update author")
 	}
 
+	// Books are found by their author's name too
+	if req.Name != nil {
+		books, err := s.bookRepo.GetByAuthorID(id)
+		if err != nil {
+			log.Printf("Failed to reindex books of author %d: %v", id, err)
+		}
+		for i := range books {
+			indexBook(s.searchIndex, &books[i])
+		}
+	}
+
 	return author, nil
 }
 
@@ -121,7 +136,7 @@ func (s *authorService) DeleteAuthor(id uint) error {
 		return utils.InternalServerError("Failed to get author")
 	}
 
-	// Check if author has books
+	// Check if author is credited on any book, in any role
 	books, err := s.bookRepo.GetByAuthorID(id)
 	if err != nil {
 		return utils.InternalServerError("Failed to check author's books")
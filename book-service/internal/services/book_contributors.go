@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"book-service/internal/dto"
+	"book-service/internal/models"
+
+	"shared/utils"
+
+	"gorm.io/gorm"
+)
+
+// buildContributors checks that every listed author exists and is credited
+// once per role, and returns the contributors in the order given
+func (s *bookService) buildContributors(reqs []dto.ContributorReq) ([]models.BookContributor, error) {
+	type credit struct {
+		authorID uint
+		role     models.ContributorRole
+	}
+	seen := map[credit]bool{}
+	contributors := make([]models.BookContributor, 0, len(reqs))
+	for i, req := range reqs {
+		key := credit{req.AuthorID, req.Role}
+		if seen[key] {
+			return nil, utils.ValidationFailed(map[string]string{
+				fmt.Sprintf("contributors.%d", i): "credits the same author in the same role twice",
+			})
+		}
+		seen[key] = true
+
+		author, err := s.authorRepo.GetByID(req.AuthorID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, utils.NotFound("Author not found").WithDetail("author_id", req.AuthorID)
+			}
+			return nil, utils.InternalServerError("Failed to check author")
+		}
+		contributors = append(contributors, models.BookContributor{
+			AuthorID: req.AuthorID,
+			Role:     req.Role,
+			Author:   *author,
+		})
+	}
+	return contributors, nil
+}
+
+// contributorNames lists the names of a book's contributors, for the
+// search index
+func contributorNames(contributors []models.BookContributor) []string {
+	names := make([]string, 0, len(contributors))
+	for _, contributor := range contributors {
+		names = append(names, contributor.Author.Name)
+	}
+	return names
+}
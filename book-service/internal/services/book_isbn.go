@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"book-service/internal/isbn"
+
+	"shared/utils"
+
+	"gorm.io/gorm"
+)
+
+// maxISBNDisplay is the size of books.isbn_display, enough for an ISBN-13
+// with four hyphens
+const maxISBNDisplay = 17
+
+// parseISBN returns the ISBN-13 to store and the form to display, both nil
+// for a blank value. The display form keeps the hyphens and spaces as
+// written, with runs of whitespace collapsed to one space.
+func parseISBN(value string) (*string, *string, error) {
+	display := strings.Join(strings.Fields(value), " ")
+	if display == "" {
+		return nil, nil, nil
+	}
+
+	isbn13, err := isbn.Normalize(display)
+	if err != nil {
+		return nil, nil, utils.ValidationFailed(map[string]string{
+			"isbn": "must be a valid ISBN-10 or ISBN-13",
+		})
+	}
+	if len(display) > maxISBNDisplay {
+		return nil, nil, utils.ValidationFailed(map[string]string{
+			"isbn": "must be at most 17 characters including hyphens and spaces",
+		})
+	}
+	return &isbn13, &display, nil
+}
+
+// checkISBNFree fails with a Conflict naming the book that already has
+// isbn13, bookID is the book being saved
+func (s *bookService) checkISBNFree(isbn13 *string, bookID uint) error {
+	if isbn13 == nil {
+		return nil
+	}
+
+	existing, err := s.bookRepo.GetByISBN(*isbn13)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return utils.InternalServerError("Failed to check ISBN")
+	}
+	if existing.ID == bookID {
+		return nil
+	}
+	return utils.Conflict("A book with this ISBN already exists").WithDetail("book_id", existing.ID)
+}
+
+// isbnConflict explains a duplicate key error on save, for a book saved
+// with the same ISBN after checkISBNFree passed
+func (s *bookService) isbnConflict(isbn13 *string, bookID uint, failure string) error {
+	if err := s.checkISBNFree(isbn13, bookID); err != nil {
+		return err
+	}
+	return utils.InternalServerError(failure)
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"shared/utils"
+)
+
+func TestParseISBN(t *testing.T) {
+	tests := []struct {
+		name        string
+		in          string
+		wantISBN    string
+		wantDisplay string
+		wantErr     string
+	}{
+		{name: "blank", in: "  "},
+		{name: "ISBN-13 with hyphens", in: "978-0-306-40615-7", wantISBN: "9780306406157", wantDisplay: "978-0-306-40615-7"},
+		{name: "ISBN-10", in: "0-8044-2957-X", wantISBN: "9780804429573", wantDisplay: "0-8044-2957-X"},
+		{name: "outer whitespace is trimmed", in: " \t978 0 306 40615 7\n", wantISBN: "9780306406157", wantDisplay: "978 0 306 40615 7"},
+		{name: "inner runs of whitespace collapse", in: "978   0306  40615\t7", wantISBN: "9780306406157", wantDisplay: "978 0306 40615 7"},
+		{name: "longest display", in: "979-10-90636-07-1", wantISBN: "9791090636071", wantDisplay: "979-10-90636-07-1"},
+		{name: "too long for the column", in: "978 - 0306 - 40615 - 7", wantErr: "must be at most 17 characters including hyphens and spaces"},
+		{name: "spaced out ISBN-13", in: "9 7 8 0 3 0 6 4 0 6 1 5 7", wantErr: "must be at most 17 characters including hyphens and spaces"},
+		{name: "wrong checksum", in: "978-0-306-40615-8", wantErr: "must be a valid ISBN-10 or ISBN-13"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isbn13, display, err := parseISBN(tt.in)
+			if tt.wantErr != "" {
+				var customErr *utils.CustomError
+				if !errors.As(err, &customErr) || customErr.Code != http.StatusBadRequest || customErr.Fields["isbn"] != tt.wantErr {
+					t.Fatalf("got %v, want a 400 for isbn: %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := deref(isbn13); got != tt.wantISBN {
+				t.Errorf("isbn = %q, want %q", got, tt.wantISBN)
+			}
+			if got := deref(display); got != tt.wantDisplay {
+				t.Errorf("display = %q, want %q", got, tt.wantDisplay)
+			}
+			if tt.wantISBN == "" && (isbn13 != nil || display != nil) {
+				t.Error("a blank ISBN must give nil")
+			}
+		})
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"log"
+	"math"
+	"strings"
+
+	"book-service/internal/dto"
+	"book-service/internal/models"
+	"book-service/internal/repository"
+	"book-service/internal/search"
+
+	"shared/utils"
+)
+
+// maxSearchMatches caps how many full-text matches a search considers,
+// relevance ordered pages are cut from this many books at most
+const maxSearchMatches = 1000
+
+// descriptionSnippetLength is how much of a description a highlight shows
+const descriptionSnippetLength = 200
+
+// withTotal fills in the totals when req asks for them
+func withTotal(res *dto.SearchBooksRes, req dto.SearchBooksReq, total int64) *dto.SearchBooksRes {
+	if !req.IncludeTotal {
+		return res
+	}
+	totalPages := int(math.Ceil(float64(total) / float64(req.Limit)))
+	res.Total = &total
+	res.TotalPages = &totalPages
+	return res
+}
+
+// withFacets adds the facets when req asks for them
+func (s *bookService) withFacets(res *dto.SearchBooksRes, req dto.SearchBooksReq) (*dto.SearchBooksRes, error) {
+	if !req.IncludeFacets {
+		return res, nil
+	}
+	facets, err := s.bookRepo.Facets(req)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get search facets")
+	}
+	res.Facets = facets
+	return res, nil
+}
+
+// highlights marks where terms appear in the fields a book is searched by
+func highlights(book *dto.BookWithContributors, terms map[string]bool) map[string]string {
+	names := make([]string, 0, len(book.Contributors))
+	for _, contributor := range book.Contributors {
+		names = append(names, contributor.Name)
+	}
+
+	fields := map[string]string{}
+	for name, text := range map[string]string{
+		"title":        search.Highlight(book.Title, terms, 0),
+		"description":  search.Highlight(book.Description, terms, descriptionSnippetLength),
+		"contributors": search.Highlight(strings.Join(names, ", "), terms, 0),
+	} {
+		if text != "" {
+			fields[name] = text
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+func searchDocument(book *models.Book) search.Document {
+	return search.Document{
+		BookID:      book.ID,
+		Title:       book.Title,
+		Description: book.Description,
+		AuthorNames: contributorNames(book.Contributors),
+	}
+}
+
+// indexBook keeps the search index in step with a saved book. A failure
+// only makes search stale, so it is logged rather than failing the write.
+func indexBook(index search.SearchIndex, book *models.Book) {
+	if err := index.Index(searchDocument(book)); err != nil {
+		log.Printf("Failed to index book %d: %v", book.ID, err)
+	}
+}
+
+// LoadSearchIndex indexes every book, for indexes that start empty
+func LoadSearchIndex(index search.SearchIndex, bookRepo repository.BookRepository) error {
+	books, err := bookRepo.GetAll()
+	if err != nil {
+		return err
+	}
+	docs := make([]search.Document, 0, len(books))
+	for i := range books {
+		docs = append(docs, searchDocument(&books[i]))
+	}
+	return search.Fill(index, docs)
+}
@@ -1,64 +1,94 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"math"
+	"log"
+	"sort"
+	"strings"
 
 	"book-service/internal/dto"
+	"book-service/internal/isbn"
 	"book-service/internal/models"
 	"book-service/internal/repository"
+	"book-service/internal/search"
 
+	"shared/userclient"
 	"shared/utils"
 
 	"gorm.io/gorm"
 )
 
 type BookService interface {
-	CreateBook(req dto.CreateBookReq) (*models.Book, error)
+	CreateBook(userID uint, req dto.CreateBookReq) (*models.Book, error)
 	GetBookByID(id uint) (*models.Book, error)
+	GetBookByISBN(value string) (*models.Book, error)
 	GetAllBooks() ([]models.Book, error)
 	UpdateBook(id uint, req dto.UpdateBookReq) (*models.Book, error)
 	DeleteBook(id uint) error
 	GetBooksByAuthorID(authorID uint) ([]models.Book, error)
-	SearchBooks(req dto.SearchBooksReq) (*dto.SearchBooksRes, error)
+	SearchBooks(ctx context.Context, userID uint, req dto.SearchBooksReq) (*dto.SearchBooksRes, error)
+	GetFollowingFeed(ctx context.Context, userID uint, req dto.FeedReq) (*dto.FeedRes, error)
 }
 
+// defaultPageSize applies when neither the request nor the user's
+// preferences set a page size
+const defaultPageSize = 10
+
 type bookService struct {
-	bookRepo   repository.BookRepository
-	authorRepo repository.AuthorRepository
+	bookRepo          repository.BookRepository
+	authorRepo        repository.AuthorRepository
+	userServiceClient *userclient.Client
+	searchIndex       search.SearchIndex
+	maxSearchLimit    int
 }
 
-func NewBookService(bookRepo repository.BookRepository, authorRepo repository.AuthorRepository) BookService {
+func NewBookService(bookRepo repository.BookRepository, authorRepo repository.AuthorRepository, userServiceClient *userclient.Client, searchIndex search.SearchIndex, maxSearchLimit int) BookService {
 	return &bookService{
-		bookRepo:   bookRepo,
-		authorRepo: authorRepo,
+		bookRepo:          bookRepo,
+		authorRepo:        authorRepo,
+		userServiceClient: userServiceClient,
+		searchIndex:       searchIndex,
+		maxSearchLimit:    maxSearchLimit,
 	}
 }
 
-func (s *bookService) CreateBook(req dto.CreateBookReq) (*models.Book, error) {
-	// Check if author exists
-	_, err := s.authorRepo.GetByID(req.AuthorID)
+// CreateBook records userID as the one who added the book, for followers'
+// feeds
+func (s *bookService) CreateBook(userID uint, req dto.CreateBookReq) (*models.Book, error) {
+	contributors, err := s.buildContributors(req.Contributors)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, utils.NotFound("Author not found")
-		}
-		return nil, utils.InternalServerError("Failed to check author")
+		return nil, err
+	}
+
+	isbn13, display, err := parseISBN(req.ISBN)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkISBNFree(isbn13, 0); err != nil {
+		return nil, err
 	}
 
 	book := &models.Book{
-		Title:       req.Title,
-		Description: req.Description,
-		PublishYear: req.PublishYear,
-		ISBN:        req.ISBN,
-		Genre:       req.Genre,
-		Pages:       req.Pages,
-		Price:       req.Price,
-		AuthorID:    req.AuthorID,
+		Title:        req.Title,
+		Description:  req.Description,
+		PublishYear:  req.PublishYear,
+		ISBN:         isbn13,
+		ISBNDisplay:  display,
+		Genre:        req.Genre,
+		Pages:        req.Pages,
+		Price:        req.Price,
+		AddedBy:      &userID,
+		Contributors: contributors,
 	}
 
 	if err := s.bookRepo.Create(book); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, s.isbnConflict(isbn13, 0, "Failed to create book")
+		}
 		return nil, utils.InternalServerError("Failed to create book")
 	}
+	indexBook(s.searchIndex, book)
 
 	return book, nil
 }
@@ -74,6 +104,23 @@ func (s *bookService) GetBookByID(id uint) (*models.Book, error) {
 	return book, nil
 }
 
+// GetBookByISBN finds a book by its ISBN-10 or ISBN-13
+func (s *bookService) GetBookByISBN(value string) (*models.Book, error) {
+	isbn13, err := isbn.Normalize(value)
+	if err != nil {
+		return nil, utils.BadRequest("Invalid ISBN")
+	}
+
+	book, err := s.bookRepo.GetByISBN(isbn13)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NotFound("Book not found")
+		}
+		return nil, utils.InternalServerError("Failed to get book")
+	}
+	return book, nil
+}
+
 func (s *bookService) GetAllBooks() ([]models.Book, error) {
 	books, err := s.bookRepo.GetAll()
 	if err != nil {
@@ -102,7 +149,15 @@ func (s *bookService) UpdateBook(id uint, req dto.UpdateBookReq) (*models.Book,
 		book.PublishYear = *req.PublishYear
 	}
 	if req.ISBN != nil {
-		book.ISBN = *req.ISBN
+		isbn13, display, err := parseISBN(*req.ISBN)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkISBNFree(isbn13, book.ID); err != nil {
+			return nil, err
+		}
+		book.ISBN = isbn13
+		book.ISBNDisplay = display
 	}
 	if req.Genre != nil {
 		book.Genre = *req.Genre
@@ -113,21 +168,21 @@ func (s *bookService) UpdateBook(id uint, req dto.UpdateBookReq) (*models.Book,
 	if req.Price != nil {
 		book.Price = *req.Price
 	}
-	if req.AuthorID != nil {
-		// Check if author exists
-		_, err := s.authorRepo.GetByID(*req.AuthorID)
+	if req.Contributors != nil {
+		contributors, err := s.buildContributors(*req.Contributors)
 		if err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return nil, utils.NotFound("Author not found")
-			}
-			return nil, utils.InternalServerError("Failed to check author")
+			return nil, err
 		}
-		book.AuthorID = *req.AuthorID
+		book.Contributors = contributors
 	}
 
 	if err := s.bookRepo.Update(book); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) && book.ISBN != nil {
+			return nil, s.isbnConflict(book.ISBN, book.ID, "Failed to update book")
+		}
 		return nil, utils.InternalServerError("Failed to update book")
 	}
+	indexBook(s.searchIndex, book)
 
 	return book, nil
 }
@@ -144,6 +199,9 @@ func (s *bookService) DeleteBook(id uint) error {
 	if err := s.bookRepo.Delete(id); err != nil {
 		return utils.InternalServerError("Failed to delete book")
 	}
+	if err := s.searchIndex.Remove(id); err != nil {
+		log.Printf("Failed to remove book %d from the search index: %v", id, err)
+	}
 
 	return nil
 }
@@ -166,28 +224,203 @@ func (s *bookService) GetBooksByAuthorID(authorID uint) ([]models.Book, error) {
 	return books, nil
 }
 
-func (s *bookService) SearchBooks(req dto.SearchBooksReq) (*dto.SearchBooksRes, error) {
+// SearchBooks pages by cursor when req.Cursor is set and by page
+// otherwise. Both return next_cursor, so a client can start with the first
+// page and follow cursors from there. With req.Q only books matching it in
+// the search index are returned, best match first unless req.Sort is set.
+func (s *bookService) SearchBooks(ctx context.Context, userID uint, req dto.SearchBooksReq) (*dto.SearchBooksRes, error) {
 	// Set default pagination values
 	if req.Page <= 0 {
 		req.Page = 1
 	}
 	if req.Limit <= 0 {
-		req.Limit = 10
+		req.Limit = s.preferredPageSize(ctx, userID)
+	}
+	req.Limit = min(req.Limit, s.maxSearchLimit)
+	if req.Order == "" {
+		req.Order = "asc"
+	}
+	req.Q = strings.TrimSpace(req.Q)
+
+	var cursor *searchCursor
+	if req.Cursor != "" {
+		var err error
+		if cursor, err = decodeSearchCursor(req, req.Cursor); err != nil {
+			return nil, utils.BadRequest("Invalid cursor")
+		}
+	}
+
+	if req.Q == "" {
+		res, err := s.searchSorted(req, cursor)
+		if err != nil {
+			return nil, err
+		}
+		return s.withFacets(res, req)
 	}
 
-	books, total, err := s.bookRepo.SearchBooks(req)
+	matches, err := s.searchIndex.Search(ctx, req.Q, maxSearchMatches)
 	if err != nil {
+		log.Printf("Full-text search for %q failed: %v", req.Q, err)
 		return nil, utils.InternalServerError("Failed to search books")
 	}
+	scores := make(map[uint]float64, len(matches))
+	req.BookIDs = make([]uint, 0, len(matches))
+	for _, match := range matches {
+		scores[match.BookID] = match.Score
+		req.BookIDs = append(req.BookIDs, match.BookID)
+	}
 
-	// Calculate total pages
-	totalPages := int(math.Ceil(float64(total) / float64(req.Limit)))
+	var res *dto.SearchBooksRes
+	if req.Sort == "" {
+		res, err = s.searchByRelevance(req, cursor, scores)
+	} else {
+		res, err = s.searchSorted(req, cursor)
+	}
+	if err != nil {
+		return nil, err
+	}
 
-	return &dto.SearchBooksRes{
-		Books:      books,
-		Total:      total,
-		Page:       req.Page,
-		Limit:      req.Limit,
-		TotalPages: totalPages,
-	}, nil
+	terms := search.Terms(req.Q)
+	for i := range res.Books {
+		book := &res.Books[i]
+		book.Score = scores[book.ID]
+		book.Highlights = highlights(book, terms)
+	}
+	return s.withFacets(res, req)
+}
+
+// searchSorted pages through req's matches in req.Sort order with keyset
+// cursors
+func (s *bookService) searchSorted(req dto.SearchBooksReq, cursor *searchCursor) (*dto.SearchBooksRes, error) {
+	var after *repository.SearchCursor
+	if cursor != nil {
+		var err error
+		if after, err = cursor.keyset(); err != nil {
+			return nil, utils.BadRequest("Invalid cursor")
+		}
+	}
+
+	res := &dto.SearchBooksRes{
+		Books: []dto.BookWithContributors{},
+		Limit: req.Limit,
+	}
+	if after == nil {
+		res.Page = req.Page
+	}
+	if req.BookIDs != nil && len(req.BookIDs) == 0 {
+		return withTotal(res, req, 0), nil
+	}
+
+	// One extra row tells whether there is a next page
+	books, err := s.bookRepo.SearchBooks(req, after, req.Limit+1)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to search books")
+	}
+	if len(books) > req.Limit {
+		books = books[:req.Limit]
+		res.NextCursor = encodeSearchCursor(req, books[len(books)-1])
+	}
+	if books != nil {
+		res.Books = books
+	}
+
+	if req.IncludeTotal {
+		total, err := s.bookRepo.CountBooks(req)
+		if err != nil {
+			return nil, utils.InternalServerError("Failed to search books")
+		}
+		withTotal(res, req, total)
+	}
+	return res, nil
+}
+
+// searchByRelevance ranks every book matching req, at most
+// maxSearchMatches of them, and cuts the page out of that list
+func (s *bookService) searchByRelevance(req dto.SearchBooksReq, cursor *searchCursor, scores map[uint]float64) (*dto.SearchBooksRes, error) {
+	offset := (req.Page - 1) * req.Limit
+	res := &dto.SearchBooksRes{
+		Books: []dto.BookWithContributors{},
+		Limit: req.Limit,
+	}
+	if cursor != nil {
+		if cursor.Offset <= 0 {
+			return nil, utils.BadRequest("Invalid cursor")
+		}
+		offset = cursor.Offset
+	} else {
+		res.Page = req.Page
+	}
+	if len(req.BookIDs) == 0 {
+		return withTotal(res, req, 0), nil
+	}
+
+	// The remaining filters may drop some of the matches
+	all := req
+	all.Page = 1
+	books, err := s.bookRepo.SearchBooks(all, nil, len(req.BookIDs))
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to search books")
+	}
+	sort.SliceStable(books, func(a, b int) bool {
+		return scores[books[a].ID] > scores[books[b].ID]
+	})
+
+	if offset < len(books) {
+		end := min(offset+req.Limit, len(books))
+		res.Books = books[offset:end]
+		if end < len(books) {
+			res.NextCursor = encodeOffsetCursor(req, end)
+		}
+	}
+	return withTotal(res, req, int64(len(books))), nil
+}
+
+// GetFollowingFeed lists books recently added by people the user follows.
+// Muted users are already left out by user-service, and blocked users can't
+// be followed.
+func (s *bookService) GetFollowingFeed(ctx context.Context, userID uint, req dto.FeedReq) (*dto.FeedRes, error) {
+	if req.Limit <= 0 {
+		req.Limit = s.preferredPageSize(ctx, userID)
+	}
+
+	following, err := s.userServiceClient.GetFollowing(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get following for user %d: %v", userID, err)
+		return nil, utils.InternalServerError("Failed to get following feed")
+	}
+
+	res := &dto.FeedRes{Books: []dto.BookWithContributors{}}
+	if len(following) == 0 {
+		return res, nil
+	}
+
+	books, err := s.bookRepo.GetAddedBy(following, req.Before, req.Limit)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get following feed")
+	}
+	if books != nil {
+		res.Books = books
+	}
+	if len(books) == req.Limit {
+		res.NextBefore = books[len(books)-1].ID
+	}
+	return res, nil
+}
+
+// preferredPageSize reads the user's items per page from user-service,
+// falling back to the default so search keeps working without it
+func (s *bookService) preferredPageSize(ctx context.Context, userID uint) int {
+	if userID == 0 {
+		return defaultPageSize
+	}
+
+	preferences, err := s.userServiceClient.GetUserPreferences(ctx, userID)
+	if err != nil {
+		log.Printf("Using default page size: %v", err)
+		return defaultPageSize
+	}
+	if preferences.ItemsPerPage <= 0 {
+		return defaultPageSize
+	}
+	return int(preferences.ItemsPerPage)
 }
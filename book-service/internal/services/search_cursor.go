@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"book-service/internal/dto"
+	"book-service/internal/repository"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// searchCursor is what next_cursor holds, base64 encoded JSON so clients
+// treat it as opaque. Q, Sort and Order are kept to reject a cursor used
+// with a different search than the one it came from. Relevance ordered
+// pages are cut from a ranked list, so their cursor is an Offset into it.
+type searchCursor struct {
+	Q      string `json:"q,omitempty"`
+	Sort   string `json:"s,omitempty"`
+	Order  string `json:"o,omitempty"`
+	Value  string `json:"v,omitempty"`
+	ID     uint   `json:"id,omitempty"`
+	Offset int    `json:"off,omitempty"`
+}
+
+func encodeSearchCursor(req dto.SearchBooksReq, last dto.BookWithContributors) string {
+	cursor := searchCursor{Q: req.Q, Sort: req.Sort, Order: req.Order, ID: last.ID}
+	switch req.Sort {
+	case "title":
+		cursor.Value = last.Title
+	case "publish_year":
+		cursor.Value = strconv.Itoa(last.PublishYear)
+	case "price":
+		cursor.Value = strconv.FormatFloat(last.Price, 'f', -1, 64)
+	case "created_at":
+		cursor.Value = last.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+	return cursor.encode()
+}
+
+func encodeOffsetCursor(req dto.SearchBooksReq, offset int) string {
+	cursor := searchCursor{Q: req.Q, Sort: req.Sort, Order: req.Order, Offset: offset}
+	return cursor.encode()
+}
+
+func (c searchCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeSearchCursor(req dto.SearchBooksReq, encoded string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	var cursor searchCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || (cursor.ID == 0 && cursor.Offset <= 0) {
+		return nil, errInvalidCursor
+	}
+	if cursor.Q != req.Q || cursor.Sort != req.Sort || cursor.Order != req.Order {
+		return nil, errInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// keyset returns where the next page starts in req.Sort order
+func (c *searchCursor) keyset() (*repository.SearchCursor, error) {
+	if c.ID == 0 {
+		return nil, errInvalidCursor
+	}
+
+	var err error
+	after := &repository.SearchCursor{ID: c.ID}
+	switch c.Sort {
+	case "title":
+		after.Value = c.Value
+	case "publish_year":
+		after.Value, err = strconv.Atoi(c.Value)
+	case "price":
+		after.Value, err = strconv.ParseFloat(c.Value, 64)
+	case "created_at":
+		after.Value, err = time.Parse(time.RFC3339Nano, c.Value)
+	}
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	return after, nil
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"shared/ratelimit"
 )
 
 type Config struct {
@@ -18,14 +20,30 @@ type Config struct {
 	DBName     string
 
 	AuthServiceURL string
+	UserServiceURL string
 	RedisURL       string
 
+	// How long lookups from user-service are reused
+	UserCacheTTLSeconds int
+
+	// Largest page size book search returns
+	SearchMaxLimit int
+	// Full-text search implementation, see search.KindMySQL and search.KindMemory
+	SearchIndex string
+
+	// Audience this service expects in access tokens
+	TokenAudience string
+
 	// Cache configuration
 	CacheEnabled      bool
 	L1CacheTTLMinutes int
 	L2CacheTTLMinutes int
 
 	JWTSecret string
+
+	// Rate limiting
+	RateLimitEnabled  bool
+	RateLimitPolicies []ratelimit.Policy
 }
 
 func LoadConfig() (*Config, error) {
@@ -33,6 +51,18 @@ func LoadConfig() (*Config, error) {
 	cacheEnabled, _ := strconv.ParseBool(getEnv("CACHE_ENABLED", "true"))
 	l1CacheTTL, _ := strconv.Atoi(getEnv("L1_CACHE_TTL_MINUTES", "5"))
 	l2CacheTTL, _ := strconv.Atoi(getEnv("L2_CACHE_TTL_MINUTES", "15"))
+	userCacheTTL, _ := strconv.Atoi(getEnv("USER_CACHE_TTL_SECONDS", "60"))
+	searchMaxLimit, _ := strconv.Atoi(getEnv("SEARCH_MAX_LIMIT", "100"))
+	if searchMaxLimit <= 0 {
+		searchMaxLimit = 100
+	}
+
+	// Parse rate limit configuration, see ratelimit.ParsePolicies for the format
+	rateLimitEnabled, _ := strconv.ParseBool(getEnv("RATE_LIMIT_ENABLED", "true"))
+	rateLimitPolicies, err := ratelimit.ParsePolicies(getEnv("RATE_LIMIT_POLICIES", "GET /api/v1/books/search=token_bucket:60/1m:user;POST /cache/clear=sliding_window:2/1m:ip;*=token_bucket:120/1m:user"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_POLICIES: %v", err)
+	}
 
 	config := &Config{
 		Port:    getEnv("PORT", "8082"),
@@ -45,13 +75,24 @@ func LoadConfig() (*Config, error) {
 		DBName:     getEnv("DB_NAME", "book_db"),
 
 		AuthServiceURL: getEnv("AUTH_SERVICE_URL", "localhost:9080"),
+		UserServiceURL: getEnv("USER_SERVICE_URL", "localhost:9081"),
 		RedisURL:       getEnv("REDIS_URL", "localhost:6379"),
 
+		UserCacheTTLSeconds: userCacheTTL,
+
+		SearchMaxLimit: searchMaxLimit,
+		SearchIndex:    getEnv("SEARCH_INDEX", "mysql"),
+
+		TokenAudience: getEnv("TOKEN_AUDIENCE", "book-service"),
+
 		CacheEnabled:      cacheEnabled,
 		L1CacheTTLMinutes: l1CacheTTL,
 		L2CacheTTLMinutes: l2CacheTTL,
 
 		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-this-in-production"),
+
+		RateLimitEnabled:  rateLimitEnabled,
+		RateLimitPolicies: rateLimitPolicies,
 	}
 
 	return config, nil
@@ -65,6 +106,10 @@ func (c *Config) GetL2CacheTTL() time.Duration {
 	return time.Duration(c.L2CacheTTLMinutes) * time.Minute
 }
 
+func (c *Config) GetUserCacheTTL() time.Duration {
+	return time.Duration(c.UserCacheTTLSeconds) * time.Second
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -72,7 +117,10 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// GetDatabaseURL reads and writes DATETIME columns as UTC. Rows written
+// while the DSN had loc=Local hold the host's local time, see the readme
+// before upgrading a database written by a host not on UTC.
 func (c *Config) GetDatabaseURL() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
 		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
 }
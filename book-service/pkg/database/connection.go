@@ -16,7 +16,8 @@ func InitDB(cfg *config.Config) error {
 	var err error
 
 	dsn := cfg.GetDatabaseURL()
-	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+	// TranslateError turns unique index violations into gorm.ErrDuplicatedKey
+	db, err = gorm.Open(mysql.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		return err
 	}
@@ -24,7 +25,13 @@ func InitDB(cfg *config.Config) error {
 	log.Println("Connected to database successfully")
 
 	// Auto-migrate the schema
-	if err := db.AutoMigrate(&models.Author{}, &models.Book{}); err != nil {
+	if err := db.AutoMigrate(&models.Author{}, &models.Book{}, &models.BookContributor{}); err != nil {
+		return err
+	}
+	if err := migrateISBNs(db); err != nil {
+		return err
+	}
+	if err := migrateBookContributors(db); err != nil {
 		return err
 	}
 
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"book-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// migrateBookContributors credits each book's author_id, from before books
+// had contributors, as its author and then drops the column. Safe to run
+// again, it does nothing once the column is gone.
+func migrateBookContributors(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasColumn("books", "author_id") {
+		return nil
+	}
+
+	result := db.Exec(`INSERT INTO book_contributors (book_id, author_id, role, position, created_at)
+		SELECT books.id, books.author_id, ?, 0, NOW(3) FROM books
+		WHERE books.author_id IS NOT NULL
+		AND NOT EXISTS (SELECT 1 FROM book_contributors WHERE book_contributors.book_id = books.id)`,
+		models.ContributorRoleAuthor)
+	if result.Error != nil {
+		return fmt.Errorf("failed to copy book authors to contributors: %v", result.Error)
+	}
+
+	// The foreign key Author.Books used to create
+	if migrator.HasConstraint("books", "fk_authors_books") {
+		if err := migrator.DropConstraint("books", "fk_authors_books"); err != nil {
+			return fmt.Errorf("failed to drop the books author foreign key: %v", err)
+		}
+	}
+	if err := migrator.DropColumn("books", "author_id"); err != nil {
+		return fmt.Errorf("failed to drop books.author_id: %v", err)
+	}
+
+	log.Printf("Moved the authors of %d books to contributors", result.RowsAffected)
+	return nil
+}
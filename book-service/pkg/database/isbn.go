@@ -0,0 +1,72 @@
+package database
+
+import (
+	"fmt"
+	"log"
+
+	"book-service/internal/isbn"
+	"book-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// migrateISBNs moves books saved before ISBNs were normalized to the
+// ISBN-13 form, keeping what was entered as the display form. An invalid
+// ISBN, or one a book with a lower ID already has, is only kept for
+// display. Safe to run again, normalized books have a display form.
+func migrateISBNs(db *gorm.DB) error {
+	// Empty strings and deleted books would hold on to the unique index
+	err := db.Exec("UPDATE books SET isbn = NULL WHERE isbn = '' OR deleted_at IS NOT NULL").Error
+	if err != nil {
+		return fmt.Errorf("failed to clear unused ISBNs: %v", err)
+	}
+
+	var books []models.Book
+	err = db.Select("id", "isbn").
+		Where("isbn IS NOT NULL AND isbn_display IS NULL").
+		Order("id").
+		Find(&books).Error
+	if err != nil {
+		return fmt.Errorf("failed to find ISBNs to normalize: %v", err)
+	}
+	if len(books) == 0 {
+		return nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Free every old value first, so a book gets its normalized ISBN
+		// even when a later book still holds it in the old form
+		err := tx.Exec("UPDATE books SET isbn_display = isbn, isbn = NULL WHERE isbn IS NOT NULL AND isbn_display IS NULL").Error
+		if err != nil {
+			return err
+		}
+
+		for _, book := range books {
+			normalized, err := isbn.Normalize(*book.ISBN)
+			if err != nil {
+				log.Printf("Keeping invalid ISBN %q of book %d for display only", *book.ISBN, book.ID)
+				continue
+			}
+
+			var taken int64
+			if err := tx.Model(&models.Book{}).Where("isbn = ?", normalized).Count(&taken).Error; err != nil {
+				return err
+			}
+			if taken > 0 {
+				log.Printf("Keeping ISBN %q of book %d for display only, another book has it", *book.ISBN, book.ID)
+				continue
+			}
+
+			if err := tx.Model(&models.Book{}).Where("id = ?", book.ID).Update("isbn", normalized).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to normalize ISBNs: %v", err)
+	}
+
+	log.Printf("Normalized the ISBNs of %d books", len(books))
+	return nil
+}
@@ -0,0 +1,19 @@
+// Package dataexport holds helpers for services implementing the
+// data_export.DataExport gRPC contract
+package dataexport
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"shared/proto/data_export"
+)
+
+// JSONFile renders v as an indented JSON export file named name.json
+func JSONFile(name string, v any) (*data_export.ExportFile, error) {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %v", name, err)
+	}
+	return &data_export.ExportFile{Name: name + ".json", Content: content}, nil
+}
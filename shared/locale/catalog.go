@@ -0,0 +1,73 @@
+package locale
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Catalogs map a message key such as user.not_found to a template, {name}
+// placeholders are filled from the message params
+//
+//go:embed catalogs/*.json
+var catalogFiles embed.FS
+
+var (
+	catalogs = map[string]map[string]string{}
+	// keysByText finds the key of an English message so call sites that
+	// build errors from English text are translated without changes
+	keysByText = map[string]string{}
+)
+
+func init() {
+	for _, lang := range Supported {
+		data, err := catalogFiles.ReadFile("catalogs/" + lang + ".json")
+		if err != nil {
+			panic(fmt.Sprintf("locale: missing catalog for %s: %v", lang, err))
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("locale: invalid catalog for %s: %v", lang, err))
+		}
+		catalogs[lang] = catalog
+	}
+
+	for key, text := range catalogs[English] {
+		keysByText[text] = key
+	}
+}
+
+// Text is a message key with its params, rendered per language with In
+type Text struct {
+	Key    string
+	Params map[string]string
+}
+
+// In renders the text in lang, see Translate
+func (t Text) In(lang string) string {
+	return Translate(lang, t.Key, t.Params)
+}
+
+// Translate renders the message for key in lang, falling back to English
+// and then to the key itself when no catalog has it
+func Translate(lang, key string, params map[string]string) string {
+	template, ok := catalogs[lang][key]
+	if !ok {
+		template, ok = catalogs[English][key]
+	}
+	if !ok {
+		template = key
+	}
+
+	for name, value := range params {
+		template = strings.ReplaceAll(template, "{"+name+"}", value)
+	}
+	return template
+}
+
+// KeyFor returns the key of an English catalog message, or "" when the
+// text isn't in the catalog
+func KeyFor(text string) string {
+	return keysByText[text]
+}
@@ -0,0 +1,81 @@
+package locale
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidDate = errors.New("invalid date, want YYYY-MM-DD")
+
+// Date is a calendar date such as a date of birth. It is written as
+// YYYY-MM-DD and, unlike a timestamp, never moved to another time zone.
+// The embedded Time is midnight UTC of the date.
+type Date struct {
+	time.Time
+}
+
+// NewDate returns the date t falls on in its own zone
+func NewDate(t time.Time) Date {
+	return Date{time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)}
+}
+
+// ParseDate reads YYYY-MM-DD, or an RFC 3339 timestamp as older clients
+// and stored values have it, taking the date as written
+func ParseDate(s string) (Date, error) {
+	for _, layout := range []string{time.DateOnly, time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return NewDate(t), nil
+		}
+	}
+	return Date{}, ErrInvalidDate
+}
+
+func (d Date) String() string {
+	return d.Format(time.DateOnly)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return ErrInvalidDate
+	}
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.Time, nil
+}
+
+func (d *Date) Scan(value any) error {
+	switch v := value.(type) {
+	case time.Time:
+		*d = NewDate(v)
+		return nil
+	case []byte:
+		date, err := ParseDate(string(v))
+		*d = date
+		return err
+	case string:
+		date, err := ParseDate(v)
+		*d = date
+		return err
+	default:
+		return fmt.Errorf("cannot scan %T into Date", value)
+	}
+}
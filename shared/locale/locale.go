@@ -0,0 +1,75 @@
+package locale
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+const (
+	English    = "en"
+	Vietnamese = "vi"
+)
+
+// Supported lists the languages with a catalog, the first one is the default
+var Supported = []string{English, Vietnamese}
+
+// Context keys the JWT middleware fills from the token's locale claims
+const (
+	UserLocaleKey   = "user_locale"
+	UserTimezoneKey = "user_timezone"
+)
+
+// TimezoneHeader picks the zone timestamps are rendered in: an IANA name,
+// or "user" for the zone saved in the user's preferences
+const TimezoneHeader = "X-Timezone"
+
+var matcher = language.NewMatcher([]language.Tag{
+	language.English,
+	language.Vietnamese,
+})
+
+// Language picks the response language: the user's saved locale when the
+// request is authenticated, then Accept-Language, then English
+func Language(c *gin.Context) string {
+	if userLocale := c.GetString(UserLocaleKey); userLocale != "" {
+		if tag, err := language.Parse(userLocale); err == nil {
+			return match(tag)
+		}
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return English
+	}
+	return match(tags...)
+}
+
+func match(tags ...language.Tag) string {
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return English
+	}
+	return Supported[index]
+}
+
+// Location picks the zone timestamps are rendered in, UTC unless the
+// request asks for another one through TimezoneHeader
+func Location(c *gin.Context) *time.Location {
+	name := strings.TrimSpace(c.GetHeader(TimezoneHeader))
+	if strings.EqualFold(name, "user") {
+		name = c.GetString(UserTimezoneKey)
+	}
+	// "Local" would be the server's zone, which is what this replaces
+	if name == "" || name == "Local" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
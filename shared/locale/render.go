@@ -0,0 +1,138 @@
+package locale
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSON renders obj like c.JSON with its timestamps in UTC, or in the zone
+// asked for with TimezoneHeader, and sets Content-Language. Only time.Time
+// values are moved, a Date or a string that looks like a timestamp is
+// written as it is.
+func JSON(c *gin.Context, code int, obj any) {
+	c.Header("Content-Language", Language(c))
+	c.JSON(code, InZone(obj, Location(c)))
+}
+
+// InZone returns a copy of v with every time.Time in it, however deeply
+// nested, moved to loc. Parts of v that hold no timestamps are shared
+// rather than copied.
+func InZone(v any, loc *time.Location) any {
+	if v == nil {
+		return nil
+	}
+	return inZone(reflect.ValueOf(v), loc).Interface()
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	dateType = reflect.TypeOf(Date{})
+)
+
+func inZone(v reflect.Value, loc *time.Location) reflect.Value {
+	if !mayHoldTimes(v.Type()) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(inZone(v.Elem(), loc))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(inZone(v.Elem(), loc))
+		return out
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return reflect.ValueOf(v.Interface().(time.Time).In(loc))
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				out.Field(i).Set(inZone(v.Field(i), loc))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(inZone(v.Index(i), loc))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(inZone(v.Index(i), loc))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), inZone(iter.Value(), loc))
+		}
+		return out
+	}
+	return v
+}
+
+// holdsTimes caches mayHoldTimes per type
+var holdsTimes sync.Map
+
+// mayHoldTimes reports whether a value of type t can contain a time.Time
+// that InZone would move. Interfaces can hold anything.
+func mayHoldTimes(t reflect.Type) bool {
+	if cached, ok := holdsTimes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := typeHoldsTimes(t, map[reflect.Type]bool{})
+	holdsTimes.Store(t, result)
+	return result
+}
+
+func typeHoldsTimes(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch {
+	case t == timeType:
+		return true
+	case t == dateType:
+		return false
+	case visiting[t]:
+		// A recursive type holds times only through its other fields
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return typeHoldsTimes(t.Elem(), visiting)
+	case reflect.Map:
+		return typeHoldsTimes(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if t.Field(i).IsExported() && typeHoldsTimes(t.Field(i).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
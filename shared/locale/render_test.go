@@ -0,0 +1,114 @@
+package locale
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type profile struct {
+	Name        string     `json:"name"`
+	Note        string     `json:"note"`
+	DateOfBirth *Date      `json:"date_of_birth"`
+	CreatedAt   time.Time  `json:"created_at"`
+	DeletedAt   *time.Time `json:"deleted_at"`
+	Tags        []string   `json:"tags"`
+}
+
+func TestInZone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no time zone database")
+	}
+
+	created := time.Date(2024, 3, 1, 2, 30, 0, 0, time.UTC)
+	dob := NewDate(time.Date(1990, 5, 31, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name string
+		obj  any
+		loc  *time.Location
+		want string
+	}{
+		{
+			name: "struct in UTC",
+			obj:  profile{Name: "An", CreatedAt: created, DateOfBirth: &dob},
+			loc:  time.UTC,
+			want: `{"name":"An","note":"","date_of_birth":"1990-05-31","created_at":"2024-03-01T02:30:00Z","deleted_at":null,"tags":null}`,
+		},
+		{
+			name: "timestamps move, dates and text do not",
+			obj:  &profile{Name: "An", Note: "2024-03-01T02:30:00Z", CreatedAt: created, DeletedAt: &created, DateOfBirth: &dob, Tags: []string{"a"}},
+			loc:  newYork,
+			want: `{"name":"An","note":"2024-03-01T02:30:00Z","date_of_birth":"1990-05-31","created_at":"2024-02-29T21:30:00-05:00","deleted_at":"2024-02-29T21:30:00-05:00","tags":["a"]}`,
+		},
+		{
+			name: "maps and slices of interfaces",
+			obj:  map[string]any{"items": []any{created, "2024-03-01T02:30:00Z", dob}},
+			loc:  newYork,
+			want: `{"items":["2024-02-29T21:30:00-05:00","2024-03-01T02:30:00Z","1990-05-31"]}`,
+		},
+		{
+			name: "nil",
+			obj:  nil,
+			loc:  newYork,
+			want: `null`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(InZone(tt.obj, tt.loc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInZoneLeavesInputAlone(t *testing.T) {
+	created := time.Date(2024, 3, 1, 2, 30, 0, 0, time.UTC)
+	p := &profile{CreatedAt: created}
+
+	InZone(p, time.FixedZone("UTC-5", -5*60*60))
+
+	if p.CreatedAt.Location() != time.UTC {
+		t.Errorf("input was changed to %v", p.CreatedAt.Location())
+	}
+}
+
+func TestDateJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: `"1990-05-31"`, want: "1990-05-31"},
+		{in: `"1990-05-31T00:00:00Z"`, want: "1990-05-31"},
+		{in: `"1990-05-31T23:00:00-05:00"`, want: "1990-05-31"},
+		{in: `"31/05/1990"`, wantErr: true},
+		{in: `19900531`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var d Date
+			err := json.Unmarshal([]byte(tt.in), &d)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want an error, got %v", d)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.String() != tt.want {
+				t.Errorf("got %s, want %s", d, tt.want)
+			}
+		})
+	}
+}
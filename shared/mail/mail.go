@@ -0,0 +1,155 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+	"sync"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails. Implementations are swapped through config so
+// services never depend on a concrete mail provider.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects and configures a Sender
+type Config struct {
+	Driver string // smtp, log or memory
+
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+}
+
+// NewSender builds the Sender selected by cfg.Driver
+func NewSender(cfg Config) (Sender, error) {
+	switch cfg.Driver {
+	case "smtp":
+		return NewSMTPSender(cfg), nil
+	case "log", "":
+		return NewLogSender(), nil
+	case "memory":
+		return NewMemorySender(), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver: %s", cfg.Driver)
+	}
+}
+
+// -----------------------
+// -- SMTP sender --
+// -----------------------
+
+type SMTPSender struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+func NewSMTPSender(cfg Config) *SMTPSender {
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &SMTPSender{
+		addr: cfg.SMTPHost + ":" + cfg.SMTPPort,
+		auth: auth,
+		from: cfg.From,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	var b strings.Builder
+	b.WriteString("From: " + s.from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+	return nil
+}
+
+// -----------------------
+// -- Log sender --
+// -----------------------
+
+// LogSender writes emails to the service log, useful in local development
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	log.Printf("EMAIL to=%s subject=%q\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// -----------------------
+// -- Memory sender --
+// -----------------------
+
+// MemorySender keeps every sent email in an in-memory outbox so tests can
+// inspect what would have been delivered
+type MemorySender struct {
+	mu     sync.Mutex
+	outbox []Message
+}
+
+func NewMemorySender() *MemorySender {
+	return &MemorySender{}
+}
+
+func (s *MemorySender) Send(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outbox = append(s.outbox, msg)
+	return nil
+}
+
+// Outbox returns a copy of all sent emails
+func (s *MemorySender) Outbox() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Message(nil), s.outbox...)
+}
+
+// LastTo returns the most recent email sent to the given address
+func (s *MemorySender) LastTo(to string) (Message, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.outbox) - 1; i >= 0; i-- {
+		if s.outbox[i].To == to {
+			return s.outbox[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// Reset empties the outbox
+func (s *MemorySender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.outbox = nil
+}
@@ -24,6 +24,7 @@ const (
 type ValidateTokenRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Token         string                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	Audience      string                 `protobuf:"bytes,2,opt,name=audience,proto3" json:"audience,omitempty"` // expected audience, usually the calling service name
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,6 +66,13 @@ func (x *ValidateTokenRequest) GetToken() string {
 	return ""
 }
 
+func (x *ValidateTokenRequest) GetAudience() string {
+	if x != nil {
+		return x.Audience
+	}
+	return ""
+}
+
 type ValidateTokenResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	IsValid       bool                   `protobuf:"varint,1,opt,name=is_valid,json=isValid,proto3" json:"is_valid,omitempty"`
@@ -133,6 +141,10 @@ type UserClaims struct {
 	Subject       string                 `protobuf:"bytes,4,opt,name=subject,proto3" json:"subject,omitempty"`
 	ExpiresAt     int64                  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	IssuedAt      int64                  `protobuf:"varint,6,opt,name=issued_at,json=issuedAt,proto3" json:"issued_at,omitempty"`
+	Audience      []string               `protobuf:"bytes,7,rep,name=audience,proto3" json:"audience,omitempty"`
+	Scope         string                 `protobuf:"bytes,8,opt,name=scope,proto3" json:"scope,omitempty"`
+	Locale        string                 `protobuf:"bytes,9,opt,name=locale,proto3" json:"locale,omitempty"`
+	Timezone      string                 `protobuf:"bytes,10,opt,name=timezone,proto3" json:"timezone,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -209,17 +221,46 @@ func (x *UserClaims) GetIssuedAt() int64 {
 	return 0
 }
 
+func (x *UserClaims) GetAudience() []string {
+	if x != nil {
+		return x.Audience
+	}
+	return nil
+}
+
+func (x *UserClaims) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *UserClaims) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *UserClaims) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
 var File_proto_auth_service_proto protoreflect.FileDescriptor
 
 const file_proto_auth_service_proto_rawDesc = "" +
 	"\n" +
-	"\x18proto/auth_service.proto\x12\fauth_service\",\n" +
+	"\x18proto/auth_service.proto\x12\fauth_service\"H\n" +
 	"\x14ValidateTokenRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"\x89\x01\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1a\n" +
+	"\baudience\x18\x02 \x01(\tR\baudience\"\x89\x01\n" +
 	"\x15ValidateTokenResponse\x12\x19\n" +
 	"\bis_valid\x18\x01 \x01(\bR\aisValid\x12#\n" +
 	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\x120\n" +
-	"\x06claims\x18\x03 \x01(\v2\x18.auth_service.UserClaimsR\x06claims\"\xa9\x01\n" +
+	"\x06claims\x18\x03 \x01(\v2\x18.auth_service.UserClaimsR\x06claims\"\x8f\x02\n" +
 	"\n" +
 	"UserClaims\x12\x14\n" +
 	"\x05email\x18\x01 \x01(\tR\x05email\x12\x17\n" +
@@ -228,7 +269,12 @@ const file_proto_auth_service_proto_rawDesc = "" +
 	"\asubject\x18\x04 \x01(\tR\asubject\x12\x1d\n" +
 	"\n" +
 	"expires_at\x18\x05 \x01(\x03R\texpiresAt\x12\x1b\n" +
-	"\tissued_at\x18\x06 \x01(\x03R\bissuedAt2g\n" +
+	"\tissued_at\x18\x06 \x01(\x03R\bissuedAt\x12\x1a\n" +
+	"\baudience\x18\a \x03(\tR\baudience\x12\x14\n" +
+	"\x05scope\x18\b \x01(\tR\x05scope\x12\x16\n" +
+	"\x06locale\x18\t \x01(\tR\x06locale\x12\x1a\n" +
+	"\btimezone\x18\n" +
+	" \x01(\tR\btimezone2g\n" +
 	"\vAuthService\x12X\n" +
 	"\rValidateToken\x12\".auth_service.ValidateTokenRequest\x1a#.auth_service.ValidateTokenResponseB\x1bZ\x19shared/proto/auth_serviceb\x06proto3"
 
@@ -0,0 +1,129 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: proto/data_export.proto
+
+package data_export
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DataExport_ExportUserData_FullMethodName = "/data_export.DataExport/ExportUserData"
+)
+
+// DataExportClient is the client API for DataExport service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DataExport is implemented by every service that stores user data.
+// user-service calls each one to assemble a user's GDPR data export, so a
+// new service only has to implement this and be listed in EXPORT_SOURCES.
+type DataExportClient interface {
+	ExportUserData(ctx context.Context, in *ExportUserDataRequest, opts ...grpc.CallOption) (*ExportUserDataResponse, error)
+}
+
+type dataExportClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDataExportClient(cc grpc.ClientConnInterface) DataExportClient {
+	return &dataExportClient{cc}
+}
+
+func (c *dataExportClient) ExportUserData(ctx context.Context, in *ExportUserDataRequest, opts ...grpc.CallOption) (*ExportUserDataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExportUserDataResponse)
+	err := c.cc.Invoke(ctx, DataExport_ExportUserData_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DataExportServer is the server API for DataExport service.
+// All implementations must embed UnimplementedDataExportServer
+// for forward compatibility.
+//
+// DataExport is implemented by every service that stores user data.
+// user-service calls each one to assemble a user's GDPR data export, so a
+// new service only has to implement this and be listed in EXPORT_SOURCES.
+type DataExportServer interface {
+	ExportUserData(context.Context, *ExportUserDataRequest) (*ExportUserDataResponse, error)
+	mustEmbedUnimplementedDataExportServer()
+}
+
+// UnimplementedDataExportServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDataExportServer struct{}
+
+func (UnimplementedDataExportServer) ExportUserData(context.Context, *ExportUserDataRequest) (*ExportUserDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExportUserData not implemented")
+}
+func (UnimplementedDataExportServer) mustEmbedUnimplementedDataExportServer() {}
+func (UnimplementedDataExportServer) testEmbeddedByValue()                    {}
+
+// UnsafeDataExportServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DataExportServer will
+// result in compilation errors.
+type UnsafeDataExportServer interface {
+	mustEmbedUnimplementedDataExportServer()
+}
+
+func RegisterDataExportServer(s grpc.ServiceRegistrar, srv DataExportServer) {
+	// If the following call pancis, it indicates UnimplementedDataExportServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DataExport_ServiceDesc, srv)
+}
+
+func _DataExport_ExportUserData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExportUserDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DataExportServer).ExportUserData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DataExport_ExportUserData_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DataExportServer).ExportUserData(ctx, req.(*ExportUserDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DataExport_ServiceDesc is the grpc.ServiceDesc for DataExport service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DataExport_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "data_export.DataExport",
+	HandlerType: (*DataExportServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ExportUserData",
+			Handler:    _DataExport_ExportUserData_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/data_export.proto",
+}
@@ -0,0 +1,120 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// appliedKey marks a request that has already been counted, so the
+// middleware can be installed both globally and after the JWT middleware
+const appliedKey = "ratelimit_applied"
+
+type routeLimiter struct {
+	policy  Policy
+	limiter Limiter
+}
+
+type Middleware struct {
+	routes map[string]*routeLimiter
+}
+
+// NewMiddleware builds one limiter per policy. service namespaces the Redis
+// keys so services sharing a Redis instance never share counters.
+func NewMiddleware(client *redis.Client, service string, policies []Policy) *Middleware {
+	routes := make(map[string]*routeLimiter, len(policies))
+	for _, policy := range policies {
+		prefix := fmt.Sprintf("ratelimit:%s:%s:%s:", service, policy.Route, policy.KeyBy)
+		routes[policy.Route] = &routeLimiter{
+			policy:  policy,
+			limiter: New(client, prefix, policy),
+		}
+	}
+
+	return &Middleware{routes: routes}
+}
+
+// Handler enforces the policy for the matched route and sets the
+// X-RateLimit-* headers. Install it on the router for IP and API key
+// policies, and again after the JWT middleware for user policies; each
+// request is only counted once.
+func (m *Middleware) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetBool(appliedKey) {
+			c.Next()
+			return
+		}
+
+		route, ok := m.routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			route, ok = m.routes[DefaultRoute]
+		}
+		if !ok {
+			c.Next()
+			return
+		}
+
+		key, ok := requestKey(c, route.policy.KeyBy)
+		if !ok {
+			// User not authenticated yet, a later instance will handle it
+			c.Next()
+			return
+		}
+		c.Set(appliedKey, true)
+
+		result, err := route.limiter.Allow(c.Request.Context(), key)
+		if err != nil {
+			// Never fail a request because the limiter is broken
+			log.Printf("Rate limit check failed: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			utils.HandleError(c, utils.TooManyRequests("Rate limit exceeded, try again later"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func requestKey(c *gin.Context, keyBy KeyBy) (string, bool) {
+	switch keyBy {
+	case KeyByUser:
+		userID := c.GetUint("user_id")
+		if userID == 0 {
+			return "", false
+		}
+		return "user:" + strconv.FormatUint(uint64(userID), 10), true
+	case KeyByAPIKey:
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			// Never keep raw API keys in Redis
+			return fmt.Sprintf("key:%x", sha256.Sum256([]byte(apiKey))), true
+		}
+		return "ip:" + c.ClientIP(), true
+	default:
+		return "ip:" + c.ClientIP(), true
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,135 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newRouter installs the middleware globally and again after a stand-in for
+// the JWT middleware, the way the services do
+func newRouter(policies []Policy) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	m := NewMiddleware(nil, "test", policies)
+
+	r := gin.New()
+	r.Use(m.Handler())
+
+	authenticate := func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			c.Set("user_id", uint(7))
+		}
+		c.Next()
+	}
+	api := r.Group("/api", authenticate, m.Handler())
+	api.GET("/books/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	api.GET("/authors", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func get(r *gin.Engine, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandlerCountsEachRequestOnce(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		headers map[string]string
+	}{
+		{
+			name:   "ip policy, counted by the first install",
+			policy: Policy{Route: "GET /api/books/:id", Algorithm: SlidingWindow, Limit: 2, Window: time.Minute, KeyBy: KeyByIP},
+		},
+		{
+			name:    "user policy, counted by the second install",
+			policy:  Policy{Route: "GET /api/books/:id", Algorithm: SlidingWindow, Limit: 2, Window: time.Minute, KeyBy: KeyByUser},
+			headers: map[string]string{"Authorization": "Bearer token"},
+		},
+		{
+			name:    "api key policy",
+			policy:  Policy{Route: "GET /api/books/:id", Algorithm: TokenBucket, Limit: 2, Window: time.Minute, KeyBy: KeyByAPIKey},
+			headers: map[string]string{"X-API-Key": "secret"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRouter([]Policy{tt.policy})
+
+			// Counted twice, the first request would already show 0 remaining
+			// and the second would be refused
+			for _, want := range []string{"1", "0"} {
+				w := get(r, "/api/books/1", tt.headers)
+				if w.Code != http.StatusOK {
+					t.Fatalf("status %d, want 200", w.Code)
+				}
+				if got := w.Header().Get("X-RateLimit-Remaining"); got != want {
+					t.Errorf("X-RateLimit-Remaining = %q, want %q", got, want)
+				}
+			}
+
+			w := get(r, "/api/books/1", tt.headers)
+			if w.Code != http.StatusTooManyRequests {
+				t.Errorf("status %d, want 429", w.Code)
+			}
+			if w.Header().Get("Retry-After") == "" {
+				t.Error("429 without Retry-After")
+			}
+		})
+	}
+}
+
+func TestHandlerRoutes(t *testing.T) {
+	r := newRouter([]Policy{
+		{Route: "GET /api/books/:id", Algorithm: SlidingWindow, Limit: 1, Window: time.Minute, KeyBy: KeyByIP},
+		{Route: DefaultRoute, Algorithm: SlidingWindow, Limit: 5, Window: time.Minute, KeyBy: KeyByIP},
+	})
+
+	// Route patterns cover every book
+	get(r, "/api/books/1", nil)
+	if w := get(r, "/api/books/2", nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second book: status %d, want 429", w.Code)
+	}
+
+	// Routes without a policy of their own use the default
+	if got := get(r, "/api/authors", nil).Header().Get("X-RateLimit-Limit"); got != "5" {
+		t.Errorf("default route: X-RateLimit-Limit = %q, want 5", got)
+	}
+}
+
+func TestHandlerWithoutPolicy(t *testing.T) {
+	r := newRouter([]Policy{
+		{Route: "GET /api/books/:id", Algorithm: SlidingWindow, Limit: 1, Window: time.Minute, KeyBy: KeyByIP},
+	})
+
+	for i := 0; i < 3; i++ {
+		w := get(r, "/api/authors", nil)
+		if w.Code != http.StatusOK || w.Header().Get("X-RateLimit-Limit") != "" {
+			t.Fatalf("unlimited route: status %d, headers %v", w.Code, w.Header())
+		}
+	}
+}
+
+// A user policy on a route reached without a login is not enforced, there
+// is no user to count against
+func TestHandlerUserPolicyWithoutUser(t *testing.T) {
+	r := newRouter([]Policy{
+		{Route: "GET /api/books/:id", Algorithm: SlidingWindow, Limit: 1, Window: time.Minute, KeyBy: KeyByUser},
+	})
+
+	for i := 0; i < 3; i++ {
+		if w := get(r, "/api/books/1", nil); w.Code != http.StatusOK {
+			t.Fatalf("status %d, want 200", w.Code)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Algorithm string
+
+const (
+	TokenBucket   Algorithm = "token_bucket"
+	SlidingWindow Algorithm = "sliding_window"
+)
+
+// KeyBy selects what a policy counts requests against
+type KeyBy string
+
+const (
+	KeyByIP     KeyBy = "ip"
+	KeyByUser   KeyBy = "user"    // user_id set by the JWT middleware
+	KeyByAPIKey KeyBy = "api_key" // X-API-Key header
+)
+
+// DefaultRoute is the policy route that applies to every route without its own policy
+const DefaultRoute = "*"
+
+// Policy is the rate limit for one route, e.g. "POST /api/v1/auth/login".
+// Routes use gin's route patterns, so "/api/v1/books/:id" covers every book.
+type Policy struct {
+	Route     string
+	Algorithm Algorithm
+	Limit     int
+	Window    time.Duration
+	KeyBy     KeyBy
+}
+
+// ParsePolicies reads policies from a config string of the form
+//
+//	POST /api/v1/auth/login=sliding_window:10/1m:ip;GET /api/v1/books/search=token_bucket:60/1m:user
+//
+// where each entry is route=algorithm:limit/window:key
+func ParsePolicies(spec string) ([]Policy, error) {
+	var policies []Policy
+
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		route, rule, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit policy %q: missing '='", entry)
+		}
+
+		parts := strings.Split(rule, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid rate limit policy %q: expected algorithm:limit/window:key", entry)
+		}
+
+		policy := Policy{
+			Route:     strings.Join(strings.Fields(route), " "),
+			Algorithm: Algorithm(parts[0]),
+			KeyBy:     KeyBy(parts[2]),
+		}
+
+		if policy.Algorithm != TokenBucket && policy.Algorithm != SlidingWindow {
+			return nil, fmt.Errorf("invalid rate limit policy %q: unknown algorithm %s", entry, parts[0])
+		}
+		if policy.KeyBy != KeyByIP && policy.KeyBy != KeyByUser && policy.KeyBy != KeyByAPIKey {
+			return nil, fmt.Errorf("invalid rate limit policy %q: unknown key %s", entry, parts[2])
+		}
+
+		limitStr, windowStr, ok := strings.Cut(parts[1], "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit policy %q: expected limit/window", entry)
+		}
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("invalid rate limit policy %q: bad limit %s", entry, limitStr)
+		}
+		window, err := time.ParseDuration(windowStr)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("invalid rate limit policy %q: bad window %s", entry, windowStr)
+		}
+		policy.Limit = limit
+		policy.Window = window
+
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// New builds the limiter for a policy. With a Redis client the limit is
+// shared across instances and falls back to memory if Redis fails; with a nil
+// client it is in-memory only. prefix namespaces the Redis keys.
+func New(client *redis.Client, prefix string, policy Policy) Limiter {
+	var memory Limiter
+	switch policy.Algorithm {
+	case TokenBucket:
+		memory = NewMemoryTokenBucket(policy.Limit, policy.Window)
+	default:
+		memory = NewMemorySlidingWindow(policy.Limit, policy.Window)
+	}
+
+	if client == nil {
+		return memory
+	}
+
+	var distributed Limiter
+	switch policy.Algorithm {
+	case TokenBucket:
+		distributed = NewRedisTokenBucket(client, prefix, policy.Limit, policy.Window)
+	default:
+		distributed = NewRedisSlidingWindow(client, prefix, policy.Limit, policy.Window)
+	}
+
+	return WithFallback(distributed, memory)
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []Policy
+		wantErr bool
+	}{
+		{name: "empty", spec: "", want: nil},
+		{name: "only separators", spec: " ; ;", want: nil},
+		{
+			name: "one policy",
+			spec: "POST /api/v1/auth/login=sliding_window:10/1m:ip",
+			want: []Policy{{Route: "POST /api/v1/auth/login", Algorithm: SlidingWindow, Limit: 10, Window: time.Minute, KeyBy: KeyByIP}},
+		},
+		{
+			name: "several policies with spaces",
+			spec: " POST   /api/v1/auth/login=sliding_window:10/1m:ip ; GET /api/v1/books/:id=token_bucket:60/30s:user;*=token_bucket:100/1h:api_key ",
+			want: []Policy{
+				{Route: "POST /api/v1/auth/login", Algorithm: SlidingWindow, Limit: 10, Window: time.Minute, KeyBy: KeyByIP},
+				{Route: "GET /api/v1/books/:id", Algorithm: TokenBucket, Limit: 60, Window: 30 * time.Second, KeyBy: KeyByUser},
+				{Route: DefaultRoute, Algorithm: TokenBucket, Limit: 100, Window: time.Hour, KeyBy: KeyByAPIKey},
+			},
+		},
+		{name: "missing =", spec: "POST /login sliding_window:10/1m:ip", wantErr: true},
+		{name: "missing key", spec: "POST /login=sliding_window:10/1m", wantErr: true},
+		{name: "unknown algorithm", spec: "POST /login=leaky_bucket:10/1m:ip", wantErr: true},
+		{name: "unknown key", spec: "POST /login=sliding_window:10/1m:email", wantErr: true},
+		{name: "missing window", spec: "POST /login=sliding_window:10:ip", wantErr: true},
+		{name: "zero limit", spec: "POST /login=sliding_window:0/1m:ip", wantErr: true},
+		{name: "negative limit", spec: "POST /login=sliding_window:-1/1m:ip", wantErr: true},
+		{name: "limit not a number", spec: "POST /login=sliding_window:ten/1m:ip", wantErr: true},
+		{name: "window without unit", spec: "POST /login=sliding_window:10/60:ip", wantErr: true},
+		{name: "zero window", spec: "POST /login=sliding_window:10/0s:ip", wantErr: true},
+		{name: "one bad entry fails all", spec: "POST /login=sliding_window:10/1m:ip;GET /books=token_bucket:x/1m:ip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePolicies(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want an error, got %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Result describes the outcome of a single rate limit check
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration // time until the window is fully replenished
+	RetryAfter time.Duration // zero when allowed
+}
+
+// Limiter decides whether another request for key is allowed
+type Limiter interface {
+	Allow(ctx context.Context, key string) (*Result, error)
+}
+
+// -----------------------
+// -- In-memory sliding window --
+// -----------------------
+
+type memorySlidingWindow struct {
+	limit  int
+	window time.Duration
+
+	mu    sync.Mutex
+	hits  map[string][]time.Time
+	calls int
+}
+
+// sweepEvery controls how often idle keys are purged from memory
+const sweepEvery = 1000
+
+// NewMemorySlidingWindow allows at most limit requests per key within any
+// rolling window. State lives in process memory only.
+func NewMemorySlidingWindow(limit int, window time.Duration) Limiter {
+	return &memorySlidingWindow{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+	}
+}
+
+func (l *memorySlidingWindow) Allow(ctx context.Context, key string) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-l.window)
+
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(cutoff)
+	}
+
+	// Drop hits that fell out of the window
+	hits := l.hits[key]
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	hits = hits[i:]
+
+	result := &Result{Limit: l.limit}
+
+	if len(hits) >= l.limit {
+		result.Allowed = false
+		result.RetryAfter = hits[0].Add(l.window).Sub(now)
+		result.ResetAfter = hits[len(hits)-1].Add(l.window).Sub(now)
+		l.hits[key] = hits
+		return result, nil
+	}
+
+	hits = append(hits, now)
+	l.hits[key] = hits
+
+	result.Allowed = true
+	result.Remaining = l.limit - len(hits)
+	result.ResetAfter = l.window
+	return result, nil
+}
+
+func (l *memorySlidingWindow) sweep(cutoff time.Time) {
+	for key, hits := range l.hits {
+		if len(hits) == 0 || !hits[len(hits)-1].After(cutoff) {
+			delete(l.hits, key)
+		}
+	}
+}
+
+// -----------------------
+// -- In-memory token bucket --
+// -----------------------
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type memoryTokenBucket struct {
+	capacity float64
+	rate     float64 // tokens per second
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+}
+
+// NewMemoryTokenBucket allows bursts of up to limit requests per key,
+// refilling at limit tokens per window. State lives in process memory only.
+func NewMemoryTokenBucket(limit int, window time.Duration) Limiter {
+	return &memoryTokenBucket{
+		capacity: float64(limit),
+		rate:     float64(limit) / window.Seconds(),
+		buckets:  make(map[string]*bucket),
+	}
+}
+
+func (l *memoryTokenBucket) Allow(ctx context.Context, key string) (*Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+
+	l.calls++
+	if l.calls%sweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.capacity, last: now}
+		l.buckets[key] = b
+	}
+
+	// Refill for the time elapsed since the last request
+	b.tokens = math.Min(l.capacity, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+
+	result := &Result{Limit: int(l.capacity)}
+
+	if b.tokens < 1 {
+		result.Allowed = false
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / l.rate)
+		result.ResetAfter = secondsToDuration((l.capacity - b.tokens) / l.rate)
+		return result, nil
+	}
+
+	b.tokens--
+
+	result.Allowed = true
+	result.Remaining = int(b.tokens)
+	result.ResetAfter = secondsToDuration((l.capacity - b.tokens) / l.rate)
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, they are equivalent to
+// a missing bucket
+func (l *memoryTokenBucket) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.capacity {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
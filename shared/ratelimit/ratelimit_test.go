@@ -0,0 +1,122 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// allowN runs n checks for key and returns which were allowed
+func allowN(t *testing.T, limiter Limiter, key string, n int) []bool {
+	t.Helper()
+	allowed := make([]bool, n)
+	for i := range allowed {
+		result, err := limiter.Allow(context.Background(), key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allowed[i] = result.Allowed
+	}
+	return allowed
+}
+
+func TestMemoryLimiters(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter func(limit int, window time.Duration) Limiter
+	}{
+		{name: "sliding window", limiter: NewMemorySlidingWindow},
+		{name: "token bucket", limiter: NewMemoryTokenBucket},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := tt.limiter(3, time.Minute)
+			ctx := context.Background()
+
+			// Remaining counts down, then the limit holds
+			for want := 2; want >= 0; want-- {
+				result, err := limiter.Allow(ctx, "a")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !result.Allowed || result.Remaining != want || result.Limit != 3 {
+					t.Fatalf("got %+v, want allowed with %d remaining", result, want)
+				}
+			}
+
+			result, err := limiter.Allow(ctx, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Allowed || result.Remaining != 0 {
+				t.Errorf("over the limit: got %+v", result)
+			}
+			if result.RetryAfter <= 0 || result.RetryAfter > time.Minute {
+				t.Errorf("RetryAfter = %v, want within the window", result.RetryAfter)
+			}
+			if result.ResetAfter < result.RetryAfter || result.ResetAfter > time.Minute {
+				t.Errorf("ResetAfter = %v, want between RetryAfter and the window", result.ResetAfter)
+			}
+
+			// Keys are counted separately
+			if got := allowN(t, limiter, "b", 1); !got[0] {
+				t.Error("another key was limited")
+			}
+		})
+	}
+}
+
+func TestMemorySlidingWindowSlides(t *testing.T) {
+	window := 100 * time.Millisecond
+	limiter := NewMemorySlidingWindow(2, window)
+
+	if got := allowN(t, limiter, "a", 3); got[0] != true || got[1] != true || got[2] != false {
+		t.Fatalf("first window: got %v", got)
+	}
+
+	time.Sleep(window + 20*time.Millisecond)
+
+	// Both hits left the window, so the full limit is back
+	if got := allowN(t, limiter, "a", 3); got[0] != true || got[1] != true || got[2] != false {
+		t.Errorf("after the window: got %v", got)
+	}
+}
+
+func TestMemoryTokenBucketRefills(t *testing.T) {
+	// Two tokens refilled per 200ms, one every 100ms
+	limiter := NewMemoryTokenBucket(2, 200*time.Millisecond)
+
+	if got := allowN(t, limiter, "a", 3); got[0] != true || got[1] != true || got[2] != false {
+		t.Fatalf("burst: got %v", got)
+	}
+
+	result, _ := limiter.Allow(context.Background(), "a")
+	if result.RetryAfter <= 0 || result.RetryAfter > 100*time.Millisecond {
+		t.Errorf("RetryAfter = %v, want at most one token's refill time", result.RetryAfter)
+	}
+
+	time.Sleep(120 * time.Millisecond)
+
+	// One token came back, not the whole burst
+	if got := allowN(t, limiter, "a", 2); got[0] != true || got[1] != false {
+		t.Errorf("after one refill: got %v", got)
+	}
+}
+
+func TestNewFallsBackToMemory(t *testing.T) {
+	// Nothing listens on port 1, so every Redis call fails
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	for _, algorithm := range []Algorithm{SlidingWindow, TokenBucket} {
+		t.Run(string(algorithm), func(t *testing.T) {
+			limiter := New(client, "ratelimit:test:", Policy{Algorithm: algorithm, Limit: 2, Window: time.Minute})
+			if got := allowN(t, limiter, "a", 3); got[0] != true || got[1] != true || got[2] != false {
+				t.Errorf("got %v, want the in-memory limit", got)
+			}
+		})
+	}
+}
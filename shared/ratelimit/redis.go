@@ -0,0 +1,210 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Both scripts read the clock from Redis so every service instance shares
+// the same notion of time. They return {allowed, remaining, retry_ms, reset_ms}.
+
+var slidingWindowScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+
+if count >= limit then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	local newest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+	return {0, 0, tonumber(oldest[2]) + window - now, tonumber(newest[2]) + window - now}
+end
+
+redis.call('ZADD', KEYS[1], now, now .. '-' .. ARGV[3])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, limit - count - 1, 0, window}
+`)
+
+var tokenBucketScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + (now - ts) * rate)
+
+local allowed = 0
+local retry = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate))
+return {allowed, math.floor(tokens), retry, math.ceil((capacity - tokens) / rate)}
+`)
+
+// -----------------------
+// -- Redis sliding window --
+// -----------------------
+
+type redisSlidingWindow struct {
+	client *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewRedisSlidingWindow is the distributed counterpart of
+// NewMemorySlidingWindow, shared by every instance using the same Redis
+func NewRedisSlidingWindow(client *redis.Client, prefix string, limit int, window time.Duration) Limiter {
+	return &redisSlidingWindow{
+		client: client,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+	}
+}
+
+func (l *redisSlidingWindow) Allow(ctx context.Context, key string) (*Result, error) {
+	member, err := randomMember()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := slidingWindowScript.Run(ctx, l.client, []string{l.prefix + key}, l.limit, l.window.Milliseconds(), member).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run sliding window script: %v", err)
+	}
+
+	return buildResult(l.limit, values)
+}
+
+// -----------------------
+// -- Redis token bucket --
+// -----------------------
+
+type redisTokenBucket struct {
+	client *redis.Client
+	prefix string
+	limit  int
+	window time.Duration
+}
+
+// NewRedisTokenBucket is the distributed counterpart of NewMemoryTokenBucket
+func NewRedisTokenBucket(client *redis.Client, prefix string, limit int, window time.Duration) Limiter {
+	return &redisTokenBucket{
+		client: client,
+		prefix: prefix,
+		limit:  limit,
+		window: window,
+	}
+}
+
+func (l *redisTokenBucket) Allow(ctx context.Context, key string) (*Result, error) {
+	ratePerMs := float64(l.limit) / float64(l.window.Milliseconds())
+
+	values, err := tokenBucketScript.Run(ctx, l.client, []string{l.prefix + key}, l.limit, ratePerMs).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run token bucket script: %v", err)
+	}
+
+	return buildResult(l.limit, values)
+}
+
+// -----------------------
+// -- Fallback --
+// -----------------------
+
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+}
+
+// WithFallback uses fallback whenever primary fails, so a Redis outage
+// degrades to per-instance limits instead of rejecting or allowing everything
+func WithFallback(primary, fallback Limiter) Limiter {
+	return &fallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string) (*Result, error) {
+	result, err := l.primary.Allow(ctx, key)
+	if err != nil {
+		log.Printf("Rate limiter falling back to in-memory store: %v", err)
+		return l.fallback.Allow(ctx, key)
+	}
+	return result, nil
+}
+
+// ConnectRedis returns a client for addr, or nil when Redis is unreachable
+// so callers can run with in-memory limits only
+func ConnectRedis(addr string) *redis.Client {
+	if addr == "" {
+		return nil
+	}
+
+	client := redis.NewClient(&redis.Options{
+		Addr:         addr,
+		PoolSize:     10,
+		MinIdleConns: 2,
+		MaxRetries:   3,
+		ReadTimeout:  100 * time.Millisecond,
+		WriteTimeout: 100 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		log.Printf("Redis connection failed, rate limiting will be in-memory only: %v", err)
+		client.Close()
+		return nil
+	}
+
+	return client
+}
+
+func buildResult(limit int, values []int64) (*Result, error) {
+	if len(values) != 4 {
+		return nil, fmt.Errorf("unexpected rate limit script reply: %v", values)
+	}
+
+	return &Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		ResetAfter: time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+func randomMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,191 @@
+// Package userclient is a cached gRPC client for user-service. Lookups are
+// kept in memory for a short TTL so callers can resolve users on every
+// request without a round trip each time.
+package userclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"shared/proto/user_service"
+
+	"github.com/patrickmn/go-cache"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	callTimeout = 2 * time.Second
+
+	// maxBatchSize matches the server side limit of BatchGetUsers
+	maxBatchSize = 100
+)
+
+// Client errors wrap the gRPC status, use status.Code(err) to tell
+// codes.NotFound apart from other failures
+type Client struct {
+	conn   *grpc.ClientConn
+	client user_service.UserServiceClient
+	cache  *cache.Cache
+}
+
+func New(address string, ttl time.Duration) (*Client, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to user service: %v", err)
+	}
+
+	return &Client{
+		conn:   conn,
+		client: user_service.NewUserServiceClient(conn),
+		cache:  cache.New(ttl, ttl*2),
+	}, nil
+}
+
+func (c *Client) GetUserByID(ctx context.Context, userID uint) (*user_service.User, error) {
+	key := userKey(userID)
+	if cached, found := c.cache.Get(key); found {
+		return cached.(*user_service.User), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	response, err := c.client.GetUserByID(ctx, &user_service.GetUserByIDRequest{UserId: uint32(userID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	c.cache.SetDefault(key, response.User)
+	return response.User, nil
+}
+
+// BatchGetUsers resolves many users at once. Only cache misses are sent to
+// user-service, split into calls of at most 100 IDs. Users that don't exist
+// are left out of the result.
+func (c *Client) BatchGetUsers(ctx context.Context, userIDs []uint) (map[uint]*user_service.User, error) {
+	users := make(map[uint]*user_service.User, len(userIDs))
+	var missing []uint32
+	for _, id := range userIDs {
+		if _, done := users[id]; done {
+			continue
+		}
+		if cached, found := c.cache.Get(userKey(id)); found {
+			users[id] = cached.(*user_service.User)
+			continue
+		}
+		users[id] = nil
+		missing = append(missing, uint32(id))
+	}
+
+	for start := 0; start < len(missing); start += maxBatchSize {
+		end := min(start+maxBatchSize, len(missing))
+		if err := c.fetchUsers(ctx, missing[start:end], users); err != nil {
+			return nil, err
+		}
+	}
+
+	for id, user := range users {
+		if user == nil {
+			delete(users, id)
+		}
+	}
+	return users, nil
+}
+
+func (c *Client) fetchUsers(ctx context.Context, ids []uint32, users map[uint]*user_service.User) error {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	response, err := c.client.BatchGetUsers(ctx, &user_service.BatchGetUsersRequest{UserIds: ids})
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	for _, user := range response.Users {
+		users[uint(user.Id)] = user
+		c.cache.SetDefault(userKey(uint(user.Id)), user)
+	}
+	return nil
+}
+
+func (c *Client) GetPublicProfile(ctx context.Context, userID uint) (*user_service.GetPublicProfileResponse, error) {
+	key := fmt.Sprintf("profile:%d", userID)
+	if cached, found := c.cache.Get(key); found {
+		return cached.(*user_service.GetPublicProfileResponse), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	response, err := c.client.GetPublicProfile(ctx, &user_service.GetPublicProfileRequest{UserId: uint32(userID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get public profile: %w", err)
+	}
+
+	c.cache.SetDefault(key, response)
+	return response, nil
+}
+
+func (c *Client) GetUserPreferences(ctx context.Context, userID uint) (*user_service.GetUserPreferencesResponse, error) {
+	key := fmt.Sprintf("preferences:%d", userID)
+	if cached, found := c.cache.Get(key); found {
+		return cached.(*user_service.GetUserPreferencesResponse), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	response, err := c.client.GetUserPreferences(ctx, &user_service.GetUserPreferencesRequest{UserId: uint32(userID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user preferences: %w", err)
+	}
+
+	c.cache.SetDefault(key, response)
+	return response, nil
+}
+
+// GetFollowing returns the IDs of everyone userID follows, minus muted users
+func (c *Client) GetFollowing(ctx context.Context, userID uint) ([]uint, error) {
+	key := fmt.Sprintf("following:%d", userID)
+	if cached, found := c.cache.Get(key); found {
+		return cached.([]uint), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	response, err := c.client.GetFollowing(ctx, &user_service.GetFollowingRequest{UserId: uint32(userID)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get following: %w", err)
+	}
+
+	following := make([]uint, len(response.UserIds))
+	for i, id := range response.UserIds {
+		following[i] = uint(id)
+	}
+	c.cache.SetDefault(key, following)
+	return following, nil
+}
+
+// CreateNotification puts a notification in the user's inbox, created is
+// false when the user opted out of the type. It is never cached.
+func (c *Client) CreateNotification(ctx context.Context, req *user_service.CreateNotificationRequest) (created bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, callTimeout)
+	defer cancel()
+
+	response, err := c.client.CreateNotification(ctx, req)
+	if err != nil {
+		return false, fmt.Errorf("failed to create notification: %w", err)
+	}
+	return response.Created, nil
+}
+
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+func userKey(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
@@ -3,12 +3,22 @@ package utils
 import (
 	"fmt"
 	"net/http"
+
+	"shared/locale"
 )
 
-// CustomError represents a custom error with HTTP status code and message
+// CustomError represents a custom error with HTTP status code and message.
+// Message is English, HandleError translates it through the catalog entry
+// named by Key, which is looked up from Message when empty.
 type CustomError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"` // per-field validation messages
+	Details map[string]any    `json:"details,omitempty"`
+	Key     string            `json:"-"`
+	Params  map[string]string `json:"-"`
+
+	fieldTexts map[string]locale.Text
 }
 
 // Error implements the error interface
@@ -31,6 +41,27 @@ func BadRequest(message string) *CustomError {
 	}
 }
 
+// ValidationFailed creates a 400 Bad Request error listing what is wrong
+// with each field, keyed by the field's JSON name
+func ValidationFailed(fields map[string]string) *CustomError {
+	return &CustomError{
+		Code:    http.StatusBadRequest,
+		Message: "Validation failed",
+		Fields:  fields,
+	}
+}
+
+// validationFailedTexts is ValidationFailed with translatable field messages
+func validationFailedTexts(texts map[string]locale.Text) *CustomError {
+	fields := make(map[string]string, len(texts))
+	for field, text := range texts {
+		fields[field] = text.In(locale.English)
+	}
+	err := ValidationFailed(fields)
+	err.fieldTexts = texts
+	return err
+}
+
 // Unauthorized creates a 401 Unauthorized error
 func Unauthorized(message string) *CustomError {
 	return &CustomError{
@@ -63,6 +94,14 @@ func Conflict(message string) *CustomError {
 	}
 }
 
+// TooManyRequests creates a 429 Too Many Requests error
+func TooManyRequests(message string) *CustomError {
+	return &CustomError{
+		Code:    http.StatusTooManyRequests,
+		Message: message,
+	}
+}
+
 // InternalServerError creates a 500 Internal Server Error
 func InternalServerError(message string) *CustomError {
 	return &CustomError{
@@ -79,6 +118,55 @@ func NewCustomError(code int, message string) *CustomError {
 	}
 }
 
+// WithDetail adds a machine readable value to the response, such as the
+// ID of the record a Conflict is about
+func (e *CustomError) WithDetail(key string, value any) *CustomError {
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// NewLocalizedError creates an error from a catalog key, for messages
+// with params such as {max}
+func NewLocalizedError(code int, key string, params map[string]string) *CustomError {
+	return &CustomError{
+		Code:    code,
+		Message: locale.Translate(locale.English, key, params),
+		Key:     key,
+		Params:  params,
+	}
+}
+
+// Localize returns the message and field messages in lang, messages
+// without a catalog entry stay in English
+func (e *CustomError) Localize(lang string) (string, map[string]string) {
+	key := e.Key
+	if key == "" {
+		key = locale.KeyFor(e.Message)
+	}
+	message := e.Message
+	if key != "" {
+		message = locale.Translate(lang, key, e.Params)
+	}
+
+	if len(e.Fields) == 0 {
+		return message, e.Fields
+	}
+	fields := make(map[string]string, len(e.Fields))
+	for field, text := range e.Fields {
+		if fieldText, ok := e.fieldTexts[field]; ok {
+			fields[field] = fieldText.In(lang)
+		} else if key := locale.KeyFor(text); key != "" {
+			fields[field] = locale.Translate(lang, key, nil)
+		} else {
+			fields[field] = text
+		}
+	}
+	return message, fields
+}
+
 // Helper function to create formatted error messages
 func NewCustomErrorf(code int, format string, args ...interface{}) *CustomError {
 	return &CustomError{
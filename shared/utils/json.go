@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"shared/locale"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report validation errors under the JSON field names clients send
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+		// Dates are checked as times, so lt and gt compare them with now
+		v.RegisterCustomTypeFunc(func(field reflect.Value) any {
+			return field.Interface().(locale.Date).Time
+		}, locale.Date{})
+	}
+}
+
+// BindJSON reads the request body into dst and checks its binding tags,
+// see DecodeJSON and Validate
+func BindJSON(c *gin.Context, dst any) error {
+	data, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return BadRequest("Invalid request body")
+	}
+	if err := DecodeJSON(data, dst); err != nil {
+		return err
+	}
+	return Validate(dst)
+}
+
+// Validate checks the binding tags of a struct and returns a
+// ValidationFailed error keyed by dotted JSON paths such as address.country
+func Validate(obj any) error {
+	err := binding.Validator.ValidateStruct(obj)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return BadRequest(err.Error())
+	}
+
+	fields := make(map[string]locale.Text, len(validationErrs))
+	for _, fieldErr := range validationErrs {
+		// Drop the struct name the namespace starts with
+		path := fieldErr.Namespace()
+		if i := strings.Index(path, "."); i >= 0 {
+			path = path[i+1:]
+		}
+		fields[path] = validationMessage(fieldErr)
+	}
+	return validationFailedTexts(fields)
+}
+
+// DecodeJSON unmarshals a JSON object into dst. When that fails, each
+// top-level field is decoded on its own so the returned error names the
+// fields at fault instead of only the first one.
+func DecodeJSON(data []byte, dst any) error {
+	err := json.Unmarshal(data, dst)
+	if err == nil {
+		return nil
+	}
+
+	var doc map[string]json.RawMessage
+	if json.Unmarshal(data, &doc) != nil {
+		return BadRequest("Request body must be a JSON object")
+	}
+
+	targetType := reflect.TypeOf(dst).Elem()
+	fields := map[string]locale.Text{}
+	for key, value := range doc {
+		single, _ := json.Marshal(map[string]json.RawMessage{key: value})
+		if fieldErr := json.Unmarshal(single, reflect.New(targetType).Interface()); fieldErr != nil {
+			fields[key] = fieldErrorMessage(fieldErr)
+		}
+	}
+
+	if len(fields) == 0 {
+		return BadRequest("Invalid request body")
+	}
+	return validationFailedTexts(fields)
+}
+
+func fieldErrorMessage(err error) locale.Text {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return text("validation.type_" + jsonTypeName(typeErr.Type))
+	}
+
+	if errors.Is(err, locale.ErrInvalidDate) {
+		return text("validation.date")
+	}
+
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return text("validation.timestamp")
+	}
+
+	// Not in the catalogs, Translate falls back to the text itself
+	return text(err.Error())
+}
+
+func validationMessage(fieldErr validator.FieldError) locale.Text {
+	switch fieldErr.Tag() {
+	case "required":
+		return text("validation.required")
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return text("validation.max_chars", "max", fieldErr.Param())
+		}
+		return text("validation.max", "max", fieldErr.Param())
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return text("validation.min_chars", "min", fieldErr.Param())
+		}
+		return text("validation.min", "min", fieldErr.Param())
+	case "len":
+		return text("validation.len", "len", fieldErr.Param())
+	case "email":
+		return text("validation.email")
+	case "e164":
+		return text("validation.e164")
+	case "iso3166_1_alpha2":
+		return text("validation.country")
+	case "postcode_iso3166_alpha2_field":
+		return text("validation.postcode")
+	case "timezone":
+		return text("validation.timezone")
+	case "bcp47_language_tag":
+		return text("validation.language")
+	case "oneof":
+		return text("validation.oneof", "values", fieldErr.Param())
+	case "lt":
+		if fieldErr.Kind() == reflect.Struct {
+			return text("validation.past")
+		}
+		return text("validation.lt", "value", fieldErr.Param())
+	default:
+		return text("validation.invalid")
+	}
+}
+
+// text builds a locale.Text from a key and name, value pairs
+func text(key string, params ...string) locale.Text {
+	t := locale.Text{Key: key}
+	if len(params) > 0 {
+		t.Params = make(map[string]string, len(params)/2)
+		for i := 0; i+1 < len(params); i += 2 {
+			t.Params[params[i]] = params[i+1]
+		}
+	}
+	return t
+}
+
+func jsonTypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	default:
+		return "object"
+	}
+}
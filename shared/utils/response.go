@@ -4,13 +4,17 @@ import (
 	"errors"
 	"net/http"
 
+	"shared/locale"
+
 	"github.com/gin-gonic/gin"
 )
 
 // ErrorResponse represents a standardized error response
 type ErrorResponse struct {
-	Error string `json:"error"`
-	Code  int    `json:"code"`
+	Error   string            `json:"error"`
+	Code    int               `json:"code"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Details map[string]any    `json:"details,omitempty"`
 }
 
 // GetErrorResponse converts an error to a standardized response format
@@ -23,8 +27,10 @@ func GetErrorResponse(err error) (int, ErrorResponse) {
 	var customErr *CustomError
 	if errors.As(err, &customErr) {
 		return customErr.StatusCode(), ErrorResponse{
-			Error: customErr.Message,
-			Code:  customErr.Code,
+			Error:   customErr.Message,
+			Code:    customErr.Code,
+			Fields:  customErr.Fields,
+			Details: customErr.Details,
 		}
 	}
 
@@ -44,8 +50,18 @@ func GetErrorResponse(err error) (int, ErrorResponse) {
 	}
 }
 
-// HandleError handles errors in Gin handlers consistently
+// HandleError handles errors in Gin handlers consistently, the message is
+// translated to the request's language (see locale.Language)
 func HandleError(c *gin.Context, err error) {
 	statusCode, response := GetErrorResponse(err)
-	c.JSON(statusCode, response)
+
+	lang := locale.Language(c)
+	var customErr *CustomError
+	if errors.As(err, &customErr) {
+		response.Error, response.Fields = customErr.Localize(lang)
+	} else if key := locale.KeyFor(response.Error); key != "" {
+		response.Error = locale.Translate(lang, key, nil)
+	}
+
+	locale.JSON(c, statusCode, response)
 }
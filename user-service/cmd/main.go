@@ -1,20 +1,30 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net"
 	"strconv"
 
+	"user-service/internal/avatar"
 	"user-service/internal/clients"
+	"user-service/internal/export"
 	userGrpc "user-service/internal/grpc"
 	"user-service/internal/handlers"
 	"user-service/internal/middleware"
+	"user-service/internal/notify"
+	"user-service/internal/pii"
 	"user-service/internal/repository"
 	"user-service/internal/services"
+	"user-service/internal/storage"
 	"user-service/pkg/config"
 	"user-service/pkg/database"
 
+	"shared/locale"
+	"shared/mail"
+	"shared/proto/data_export"
 	"shared/proto/user_service"
+	"shared/ratelimit"
 
 	"github.com/gin-gonic/gin"
 	"google.golang.org/grpc"
@@ -26,12 +36,20 @@ func main() {
 		log.Fatal("Failed to load configuration:", err)
 	}
 
+	// Must be in place before the database is used, see pii.Use
+	keyring, err := pii.LoadKeyring(cfg.PIIKeyringPath)
+	if err != nil {
+		log.Fatal("Failed to load PII keyring:", err)
+	}
+	pii.Use(keyring)
+
 	if err := database.InitDB(cfg); err != nil {
 		log.Fatal("Failed to initialize database:", err)
 	}
+	go database.RunPIIRewrap(context.Background(), keyring)
 
 	// Initialize cached auth service client
-	authServiceClient, err := clients.NewCachedAuthClient(cfg.AuthServiceURL, cfg.RedisURL, cfg.GetL1CacheTTL(), cfg.GetL2CacheTTL())
+	authServiceClient, err := clients.NewCachedAuthClient(cfg.AuthServiceURL, cfg.RedisURL, cfg.TokenAudience, cfg.GetL1CacheTTL(), cfg.GetL2CacheTTL())
 	if err != nil {
 		log.Fatal("Failed to create auth service client:", err)
 	}
@@ -39,22 +57,87 @@ func main() {
 
 	userRepo := repository.NewUserRepository(database.GetDB())
 	userProfileRepo := repository.NewUserProfileRepository(database.GetDB())
+	preferencesRepo := repository.NewUserPreferencesRepository(database.GetDB())
+	addressRepo := repository.NewUserAddressRepository(database.GetDB())
+	historyRepo := repository.NewChangeHistoryRepository(database.GetDB())
+	followRepo := repository.NewFollowRepository(database.GetDB())
+	notificationRepo := repository.NewNotificationRepository(database.GetDB())
+
+	defaultPreferences, err := cfg.GetDefaultPreferences()
+	if err != nil {
+		log.Fatal("Invalid preference defaults:", err)
+	}
+
+	blobStore, err := storage.NewBlobStore(context.Background(), cfg.GetBlobStoreConfig())
+	if err != nil {
+		log.Fatal("Failed to create blob store:", err)
+	}
+	avatarProcessor := avatar.NewProcessor(cfg.AvatarMaxBytes, cfg.AvatarMinDimension, cfg.AvatarMaxDimension)
+
+	historyService := services.NewHistoryService(historyRepo)
+
+	userService := services.NewUserService(userRepo, userProfileRepo, addressRepo, historyService, blobStore, avatarProcessor)
+
+	preferencesService := services.NewPreferencesService(preferencesRepo, defaultPreferences)
+
+	addressService := services.NewAddressService(addressRepo, historyService)
+
+	followService := services.NewFollowService(followRepo, userRepo)
+
+	mailer, err := mail.NewSender(cfg.GetMailConfig())
+	if err != nil {
+		log.Fatal("Failed to create mail sender:", err)
+	}
+	notificationChannels := []notify.Channel{notify.NewEmailChannel(mailer)}
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, preferencesService, notificationChannels, cfg.GetNotificationRetention())
+	go notificationService.Run(context.Background())
 
-	userService := services.NewUserService(userRepo, userProfileRepo)
+	// GDPR data exports, collected from this service and every EXPORT_SOURCES entry
+	exportStore, err := storage.NewBlobStore(context.Background(), cfg.GetExportBlobStoreConfig())
+	if err != nil {
+		log.Fatal("Failed to create export store:", err)
+	}
+	userDataSource := services.NewUserDataSource(userRepo, userProfileRepo, addressRepo, preferencesService, notificationRepo)
+	exportSources := []export.Source{userDataSource}
+	for _, source := range cfg.ExportSources {
+		grpcSource, err := export.NewGRPCSource(source.Name, source.Address)
+		if err != nil {
+			log.Fatal("Failed to create export source:", err)
+		}
+		defer grpcSource.Close()
+		exportSources = append(exportSources, grpcSource)
+	}
+	exportService := services.NewExportService(repository.NewDataExportRepository(database.GetDB()), userRepo, exportStore,
+		export.NewSigner(cfg.ExportSigningKey), exportSources, services.ExportOptions{
+			LinkTTL:     cfg.GetExportLinkTTL(),
+			Retention:   cfg.GetExportRetention(),
+			DownloadURL: cfg.ExportDownloadURL,
+		})
+	go exportService.Run(context.Background())
 
-	userServer := userGrpc.NewUserServer(userService) // gRPC server
+	userServer := userGrpc.NewUserServer(userService, preferencesService, followService, notificationService) // gRPC server
+	exportServer := userGrpc.NewExportServer(userDataSource)
 
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, cfg.AvatarMaxBytes)
+	preferencesHandler := handlers.NewPreferencesHandler(preferencesService)
+	addressHandler := handlers.NewAddressHandler(addressService)
+	adminHandler := handlers.NewAdminHandler(userService)
+	exportHandler := handlers.NewExportHandler(exportService)
+	historyHandler := handlers.NewHistoryHandler(historyService)
+	followHandler := handlers.NewFollowHandler(followService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
 	cacheHandler := handlers.NewCacheHandler(authServiceClient)
 
 	log.Printf("Starting gRPC server in goroutine...")
-	go startGRPCServer(userServer, cfg)
+	go startGRPCServer(userServer, exportServer, cfg)
+
+	rateLimiter := ratelimit.NewMiddleware(authServiceClient.RedisClient(), "user-service", cfg.RateLimitPolicies)
 
 	log.Printf("Starting HTTP server...")
-	startHTTPServer(cfg, userHandler, cacheHandler, authServiceClient)
+	startHTTPServer(cfg, userHandler, preferencesHandler, addressHandler, adminHandler, exportHandler, historyHandler, followHandler, notificationHandler, cacheHandler, authServiceClient, rateLimiter, blobStore)
 }
 
-func startGRPCServer(userServer *userGrpc.UserServer, cfg *config.Config) {
+func startGRPCServer(userServer *userGrpc.UserServer, exportServer *userGrpc.ExportServer, cfg *config.Config) {
 	log.Printf("Starting gRPC server setup...")
 
 	grpcPort, err := strconv.Atoi(cfg.Port)
@@ -73,6 +156,7 @@ func startGRPCServer(userServer *userGrpc.UserServer, cfg *config.Config) {
 
 	grpcServer := grpc.NewServer()
 	user_service.RegisterUserServiceServer(grpcServer, userServer)
+	data_export.RegisterDataExportServer(grpcServer, exportServer)
 
 	log.Printf("gRPC server starting on port %d", grpcPort)
 	if err := grpcServer.Serve(lis); err != nil {
@@ -81,22 +165,36 @@ func startGRPCServer(userServer *userGrpc.UserServer, cfg *config.Config) {
 	}
 }
 
-func startHTTPServer(cfg *config.Config, userHandler *handlers.UserHandler, cacheHandler *handlers.CacheHandler, authServiceClient *clients.CachedAuthClient) {
+func startHTTPServer(cfg *config.Config, userHandler *handlers.UserHandler, preferencesHandler *handlers.PreferencesHandler, addressHandler *handlers.AddressHandler, adminHandler *handlers.AdminHandler, exportHandler *handlers.ExportHandler, historyHandler *handlers.HistoryHandler, followHandler *handlers.FollowHandler, notificationHandler *handlers.NotificationHandler, cacheHandler *handlers.CacheHandler, authServiceClient *clients.CachedAuthClient, rateLimiter *ratelimit.Middleware, blobStore storage.BlobStore) {
 	gin.SetMode(cfg.GinMode)
 
 	r := gin.Default()
 
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
+	r.Use(middleware.RequestID())
+
+	// Installed twice: here for IP-keyed policies and after the JWT
+	// middleware for user-keyed ones. Each request is only counted once.
+	rateLimit := func(c *gin.Context) { c.Next() }
+	if cfg.RateLimitEnabled {
+		rateLimit = rateLimiter.Handler()
+	}
+	r.Use(rateLimit)
 
 	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
+		locale.JSON(c, 200, gin.H{
 			"status":  "healthy",
 			"service": "user-service",
 			"port":    cfg.Port,
 		})
 	})
 
+	// Uploaded avatars, when they are not served from S3
+	if localStore, ok := blobStore.(*storage.LocalBlobStore); ok {
+		r.Static("/media", localStore.Dir())
+	}
+
 	// Cache monitoring endpoints
 	cacheGroup := r.Group("/cache")
 	{
@@ -106,13 +204,57 @@ func startHTTPServer(cfg *config.Config, userHandler *handlers.UserHandler, cach
 	}
 
 	jwtMiddleware := middleware.NewJWTMiddleware(authServiceClient)
+	canRead := jwtMiddleware.RequireScope("users:read")
+	canWrite := jwtMiddleware.RequireScope("users:write")
 
 	userGroup := r.Group("/api/v1/users")
-	userGroup.Use(jwtMiddleware.ValidateToken())
+	userGroup.Use(jwtMiddleware.ValidateToken(), rateLimit)
+	{
+		userGroup.GET("/", canRead, userHandler.GetUser)
+		userGroup.GET("/profile", canRead, userHandler.GetUserProfile)
+		userGroup.POST("/profile", canWrite, userHandler.CreateUserProfile)
+		userGroup.PUT("/profile", canWrite, userHandler.ReplaceUserProfile)
+		userGroup.PATCH("/profile", canWrite, userHandler.PatchUserProfile)
+		userGroup.DELETE("/profile", canWrite, userHandler.DeleteUserProfile)
+		userGroup.PUT("/profile/avatar", canWrite, userHandler.UploadAvatar)
+		userGroup.GET("/:id/public", canRead, userHandler.GetPublicProfile)
+		userGroup.POST("/me/export", canRead, exportHandler.RequestExport)
+		userGroup.GET("/me/export/:id", canRead, exportHandler.GetExport)
+		userGroup.GET("/me/history", canRead, historyHandler.GetMyHistory)
+		userGroup.GET("/me/blocks", canRead, followHandler.ListBlocked)
+		userGroup.GET("/me/mutes", canRead, followHandler.ListMuted)
+		userGroup.GET("/:id/followers", canRead, followHandler.ListFollowers)
+		userGroup.GET("/:id/following", canRead, followHandler.ListFollowing)
+		userGroup.POST("/:id/follow", canWrite, followHandler.Follow)
+		userGroup.DELETE("/:id/follow", canWrite, followHandler.Unfollow)
+		userGroup.POST("/:id/block", canWrite, followHandler.Block)
+		userGroup.DELETE("/:id/block", canWrite, followHandler.Unblock)
+		userGroup.POST("/:id/mute", canWrite, followHandler.Mute)
+		userGroup.DELETE("/:id/mute", canWrite, followHandler.Unmute)
+		userGroup.GET("/addresses", canRead, addressHandler.ListAddresses)
+		userGroup.POST("/addresses", canWrite, addressHandler.CreateAddress)
+		userGroup.GET("/addresses/:id", canRead, addressHandler.GetAddress)
+		userGroup.PUT("/addresses/:id", canWrite, addressHandler.UpdateAddress)
+		userGroup.DELETE("/addresses/:id", canWrite, addressHandler.DeleteAddress)
+		userGroup.GET("/notifications", canRead, notificationHandler.ListNotifications)
+		userGroup.POST("/notifications/read-all", canWrite, notificationHandler.MarkAllRead)
+		userGroup.POST("/notifications/:id/read", canWrite, notificationHandler.MarkRead)
+		userGroup.GET("/preferences", canRead, preferencesHandler.GetPreferences)
+		userGroup.PATCH("/preferences", canWrite, preferencesHandler.UpdatePreferences)
+	}
+
+	// Signed data export links work without a login
+	r.GET("/api/v1/exports/:id/download", exportHandler.Download)
+
+	adminGroup := r.Group("/api/v1/admin")
+	adminGroup.Use(jwtMiddleware.ValidateToken(), rateLimit, jwtMiddleware.RequireAdmin(cfg.AdminEmails))
 	{
-		userGroup.GET("/", userHandler.GetUser)
-		userGroup.GET("/profile", userHandler.GetUserProfile)
-		userGroup.POST("/profile", userHandler.CreateUserProfile)
+		adminGroup.GET("/users", canRead, adminHandler.ListUsers)
+		adminGroup.GET("/users/export", canRead, adminHandler.ExportUsers)
+		adminGroup.GET("/users/:id", canRead, adminHandler.GetUser)
+		adminGroup.PUT("/users/:id/status", canWrite, adminHandler.ChangeUserStatus)
+		adminGroup.GET("/users/:id/status/history", canRead, adminHandler.GetUserStatusHistory)
+		adminGroup.GET("/users/:id/history", canRead, historyHandler.GetUserHistory)
 	}
 
 	log.Printf("HTTP server starting on port %s", cfg.Port)
@@ -0,0 +1,160 @@
+// Package audit carries who made a change and in which request through a
+// context, and turns before and after snapshots into masked field diffs
+// for the change history.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+
+	"user-service/internal/models"
+)
+
+type contextKey int
+
+const (
+	actorKey contextKey = iota
+	requestIDKey
+)
+
+// Actor is who made a change. The zero Actor is the system, such as
+// another service calling over gRPC.
+type Actor struct {
+	UserID uint
+	Email  string
+	Admin  bool // acting through the admin API on someone else's account
+}
+
+func (a Actor) Role() models.ActorRole {
+	switch {
+	case a.UserID == 0:
+		return models.ActorRoleSystem
+	case a.Admin:
+		return models.ActorRoleAdmin
+	default:
+		return models.ActorRoleUser
+	}
+}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey, actor)
+}
+
+func ActorFrom(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey).(Actor)
+	return actor
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func RequestIDFrom(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// Fields that change on every write or are kept elsewhere in the entry.
+// A user's profile has its own history.
+var ignoredFields = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"profile":    true,
+}
+
+// Diff compares two JSON-serializable snapshots field by field. Nested
+// objects are compared per member under dotted names such as privacy.bio.
+// Either side may be nil for a create or delete. Sensitive values are
+// masked, see Mask.
+func Diff(before, after any) []models.FieldChange {
+	old := flatten(before)
+	current := flatten(after)
+
+	names := map[string]bool{}
+	for name := range old {
+		names[name] = true
+	}
+	for name := range current {
+		names[name] = true
+	}
+
+	var changes []models.FieldChange
+	for name := range names {
+		if reflect.DeepEqual(old[name], current[name]) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{
+			Field: name,
+			Old:   Mask(name, old[name]),
+			New:   Mask(name, current[name]),
+		})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+func flatten(v any) map[string]any {
+	fields := map[string]any{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return fields
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	var object map[string]any
+	if json.Unmarshal(data, &object) != nil {
+		return fields
+	}
+
+	flattenInto(fields, "", object)
+	return fields
+}
+
+func flattenInto(fields map[string]any, prefix string, object map[string]any) {
+	for key, value := range object {
+		if prefix == "" && ignoredFields[key] {
+			continue
+		}
+		if nested, ok := value.(map[string]any); ok {
+			flattenInto(fields, prefix+key+".", nested)
+			continue
+		}
+		fields[prefix+key] = value
+	}
+}
+
+// Mask hides sensitive values while keeping enough to recognise them: the
+// last two digits of a phone number, the first letter and domain of an
+// email. Dates of birth, streets and postal codes are hidden entirely.
+// nil stays nil so clearing a field remains visible.
+func Mask(field string, value any) any {
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return value
+	}
+
+	switch field {
+	case "phone":
+		if len(s) <= 2 {
+			return "***"
+		}
+		return strings.Repeat("*", len(s)-2) + s[len(s)-2:]
+	case "email":
+		local, domain, found := strings.Cut(s, "@")
+		if !found || local == "" {
+			return "***"
+		}
+		return local[:1] + "***@" + domain
+	case "date_of_birth", "street", "zip_code":
+		return "***"
+	default:
+		return value
+	}
+}
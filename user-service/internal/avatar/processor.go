@@ -0,0 +1,174 @@
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// ThumbnailSizes are the square thumbnails generated for every avatar
+var ThumbnailSizes = []int{64, 256}
+
+var (
+	ErrUnsupportedType = errors.New("avatar must be a JPEG, PNG or WebP image")
+	ErrTooLarge        = errors.New("avatar file is too large")
+	ErrBadDimensions   = errors.New("avatar dimensions are out of range")
+	ErrCorrupt         = errors.New("avatar image could not be decoded")
+)
+
+// Image is an encoded output of Process
+type Image struct {
+	Name        string // original, 64 or 256
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+type Processor struct {
+	maxBytes     int64
+	minDimension int
+	maxDimension int
+	maxOutput    int // longest side of the stored original
+}
+
+func NewProcessor(maxBytes int64, minDimension, maxDimension int) *Processor {
+	return &Processor{
+		maxBytes:     maxBytes,
+		minDimension: minDimension,
+		maxDimension: maxDimension,
+		maxOutput:    1024,
+	}
+}
+
+// MaxBytes is the largest upload Process accepts
+func (p *Processor) MaxBytes() int64 {
+	return p.maxBytes
+}
+
+// Process validates an uploaded image and re-encodes it together with its
+// thumbnails. The type is sniffed from the content, never trusted from the
+// client, and re-encoding drops EXIF and any other metadata.
+func (p *Processor) Process(data []byte) ([]Image, error) {
+	if int64(len(data)) > p.maxBytes {
+		return nil, ErrTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+
+	decodeConfig, decode := decoderFor(contentType)
+	if decode == nil {
+		return nil, ErrUnsupportedType
+	}
+
+	// Check dimensions from the header before decoding the pixels, so a small
+	// file claiming a huge canvas is rejected cheaply
+	config, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	if config.Width < p.minDimension || config.Height < p.minDimension ||
+		config.Width > p.maxDimension || config.Height > p.maxDimension {
+		return nil, fmt.Errorf("%w: must be between %dpx and %dpx", ErrBadDimensions, p.minDimension, p.maxDimension)
+	}
+
+	src, err := decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+
+	// JPEG stays JPEG, anything that may carry transparency becomes PNG
+	encode, outputType, extension := encodeJPEG, "image/jpeg", "jpg"
+	if contentType != "image/jpeg" {
+		encode, outputType, extension = encodePNG, "image/png", "png"
+	}
+
+	images := make([]Image, 0, len(ThumbnailSizes)+1)
+
+	original, err := encode(fit(src, p.maxOutput))
+	if err != nil {
+		return nil, err
+	}
+	images = append(images, Image{Name: "original", Data: original, ContentType: outputType, Extension: extension})
+
+	for _, size := range ThumbnailSizes {
+		thumbnail, err := encode(squareThumbnail(src, size))
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, Image{Name: fmt.Sprint(size), Data: thumbnail, ContentType: outputType, Extension: extension})
+	}
+
+	return images, nil
+}
+
+func decoderFor(contentType string) (func(r *bytes.Reader) (image.Config, error), func(r *bytes.Reader) (image.Image, error)) {
+	switch contentType {
+	case "image/jpeg":
+		return func(r *bytes.Reader) (image.Config, error) { return jpeg.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return jpeg.Decode(r) }
+	case "image/png":
+		return func(r *bytes.Reader) (image.Config, error) { return png.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return png.Decode(r) }
+	case "image/webp":
+		return func(r *bytes.Reader) (image.Config, error) { return webp.DecodeConfig(r) },
+			func(r *bytes.Reader) (image.Image, error) { return webp.Decode(r) }
+	default:
+		return nil, nil
+	}
+}
+
+// fit scales src down so its longest side is at most max
+func fit(src image.Image, max int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= max && height <= max {
+		return src
+	}
+
+	if width >= height {
+		height = height * max / width
+		width = max
+	} else {
+		width = width * max / height
+		height = max
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Src, nil)
+	return dst
+}
+
+// squareThumbnail crops the centre square of src and scales it to size
+func squareThumbnail(src image.Image, size int) image.Image {
+	bounds := src.Bounds()
+	side := min(bounds.Dx(), bounds.Dy())
+	x0 := bounds.Min.X + (bounds.Dx()-side)/2
+	y0 := bounds.Min.Y + (bounds.Dy()-side)/2
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, image.Rect(x0, y0, x0+side, y0+side), draw.Src, nil)
+	return dst
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, fmt.Errorf("failed to encode JPEG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %v", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -30,11 +30,11 @@ func NewAuthServiceClient(address string) (*AuthServiceClient, error) {
 	}, nil
 }
 
-func (c *AuthServiceClient) ValidateToken(ctx context.Context, token string) (*auth_service.ValidateTokenResponse, error) {
+func (c *AuthServiceClient) ValidateToken(ctx context.Context, token string, audience string) (*auth_service.ValidateTokenResponse, error) {
 	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 	defer cancel()
 
-	req := &auth_service.ValidateTokenRequest{Token: token}
+	req := &auth_service.ValidateTokenRequest{Token: token, Audience: audience}
 
 	response, err := c.client.ValidateToken(ctx, req)
 	if err != nil {
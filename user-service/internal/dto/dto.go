@@ -4,14 +4,181 @@ import (
 	"time"
 
 	"user-service/internal/models"
+
+	"shared/locale"
 )
 
+// CreateUserProfileReq is the body of POST and PUT /profile. Every field is
+// optional, omitted fields are stored as NULL.
 type CreateUserProfileReq struct {
-	FirstName   string         `json:"first_name"`
-	LastName    string         `json:"last_name"`
-	Phone       string         `json:"phone"`
-	DateOfBirth time.Time      `json:"date_of_birth"`
-	Gender      models.Gender  `json:"gender"`
-	Bio         string         `json:"bio"`
-	Address     models.Address `json:"address"`
+	FirstName   *string        `json:"first_name" binding:"omitempty,max=100"`
+	LastName    *string        `json:"last_name" binding:"omitempty,max=100"`
+	Phone       *string        `json:"phone" binding:"omitempty,e164"`
+	DateOfBirth *locale.Date   `json:"date_of_birth" binding:"omitempty,lt"` // YYYY-MM-DD
+	Gender      *models.Gender `json:"gender"`
+	Bio         *string        `json:"bio" binding:"omitempty,max=2000"`
+	Privacy     *PrivacyReq    `json:"privacy"`
+}
+
+// AddressReq is the body of POST and PUT /addresses. The postal code is
+// checked against the format of the country.
+type AddressReq struct {
+	Label     string `json:"label" binding:"required,max=50"`
+	Street    string `json:"street" binding:"required,max=255"`
+	City      string `json:"city" binding:"required,max=100"`
+	State     string `json:"state" binding:"omitempty,max=100"`
+	ZipCode   string `json:"zip_code" binding:"omitempty,max=20,postcode_iso3166_alpha2_field=Country"`
+	Country   string `json:"country" binding:"required,iso3166_1_alpha2"`
+	IsDefault bool   `json:"is_default"`
+}
+
+// PrivacyReq sets field visibility, omitted settings fall back to
+// models.DefaultProfilePrivacy
+type PrivacyReq struct {
+	Bio         *models.Visibility `json:"bio"`
+	Location    *models.Visibility `json:"location"`
+	Avatar      *models.Visibility `json:"avatar"`
+	DateOfBirth *models.Visibility `json:"date_of_birth"`
+}
+
+// UpdatePreferencesReq is the body of PATCH /preferences, omitted fields
+// keep their current value
+type UpdatePreferencesReq struct {
+	Theme              *models.Theme          `json:"theme"`
+	Language           *string                `json:"language" binding:"omitempty,bcp47_language_tag"`
+	Timezone           *string                `json:"timezone" binding:"omitempty,timezone"`
+	ItemsPerPage       *int                   `json:"items_per_page" binding:"omitempty,min=1,max=100"`
+	EmailNotifications *EmailNotificationsReq `json:"email_notifications"`
+	// Replaces the whole list, security notifications can't be opted out of
+	NotificationOptOuts *[]models.NotificationType `json:"notification_opt_outs" binding:"omitempty,dive,oneof=price_drop loan_due"`
+}
+
+type EmailNotificationsReq struct {
+	SecurityAlerts  *bool `json:"security_alerts"`
+	Recommendations *bool `json:"recommendations"`
+	Newsletter      *bool `json:"newsletter"`
+}
+
+type ChangeUserStatusReq struct {
+	Status string `json:"status" binding:"required,oneof=active suspended banned"`
+	Reason string `json:"reason" binding:"required,max=1000"`
+}
+
+// ListUsersReq holds the admin user directory filters. Cursor comes from a
+// previous response's next_cursor and must be used with the same filters.
+type ListUsersReq struct {
+	Query       string    `form:"q" binding:"omitempty,max=255"`
+	Phone       string    `form:"phone" binding:"omitempty,max=20"` // exact E.164 match, the + may be left out
+	Status      string    `form:"status"`                           // comma separated
+	CreatedFrom time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedTo   time.Time `form:"created_to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort        string    `form:"sort" binding:"omitempty,oneof=created_at email"`
+	Order       string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor      string    `form:"cursor"`
+	Limit       int       `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// UserCursor is the decoded form of ListUsersReq.Cursor, the sort value and
+// ID of the last user on the previous page
+type UserCursor struct {
+	Sort      string    `json:"s"`
+	Order     string    `json:"o"`
+	CreatedAt time.Time `json:"t,omitempty"`
+	Email     string    `json:"e,omitempty"`
+	ID        uint      `json:"id"`
+}
+
+type UserSummary struct {
+	ID        uint              `json:"id"`
+	Email     string            `json:"email"`
+	Status    models.UserStatus `json:"status"`
+	FirstName *string           `json:"first_name"`
+	LastName  *string           `json:"last_name"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type ListUsersRes struct {
+	Users      []UserSummary `json:"users"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// PublicProfile is what other users and services may see of a profile,
+// fields the user keeps private are left empty
+type PublicProfile struct {
+	UserID           uint              `json:"user_id"`
+	DisplayName      string            `json:"display_name"`
+	AvatarURL        string            `json:"avatar_url,omitempty"`
+	AvatarThumbnails map[string]string `json:"avatar_thumbnails,omitempty"`
+	Bio              string            `json:"bio,omitempty"`
+	City             string            `json:"city,omitempty"`
+	Country          string            `json:"country,omitempty"`
+	AgeRange         string            `json:"age_range,omitempty"`
+}
+
+// RelatedUsersReq pages through followers, following, blocked or muted
+// users, most recent first. Before is the next_before of the previous page.
+type RelatedUsersReq struct {
+	Before uint `form:"before"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// RelatedUser is a user in one of those lists. Since is when the follow,
+// block or mute happened.
+type RelatedUser struct {
+	UserID      uint      `json:"user_id"`
+	DisplayName string    `json:"display_name"`
+	Since       time.Time `json:"since"`
+}
+
+type RelatedUsersRes struct {
+	Users      []RelatedUser `json:"users"`
+	NextBefore uint          `json:"next_before,omitempty"`
+}
+
+// CreateNotificationReq is what another service asks to tell a user
+type CreateNotificationReq struct {
+	UserID uint
+	Type   models.NotificationType
+	Title  string
+	Body   string
+	Link   string
+}
+
+// NotificationsReq pages through the inbox, newest first. Before is the
+// next_before of the previous page.
+type NotificationsReq struct {
+	Unread bool `form:"unread"`
+	Before uint `form:"before"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type NotificationsRes struct {
+	Notifications []models.Notification `json:"notifications"`
+	UnreadCount   int64                 `json:"unread_count"`
+	NextBefore    uint                  `json:"next_before,omitempty"`
+}
+
+// DataExportRes describes an export job. DownloadURL is a fresh signed link,
+// set only once the export is completed.
+type DataExportRes struct {
+	ID                string              `json:"id"`
+	Status            models.ExportStatus `json:"status"`
+	Error             string              `json:"error,omitempty"`
+	CreatedAt         time.Time           `json:"created_at"`
+	CompletedAt       *time.Time          `json:"completed_at,omitempty"`
+	ExpiresAt         *time.Time          `json:"expires_at,omitempty"`
+	DownloadURL       string              `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time          `json:"download_expires_at,omitempty"`
+}
+
+// HistoryReq pages through the change history, newest first. Before is
+// the next_before of the previous page.
+type HistoryReq struct {
+	Before uint `form:"before"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+type HistoryRes struct {
+	Entries    []models.ChangeEntry `json:"entries"`
+	NextBefore uint                 `json:"next_before,omitempty"`
 }
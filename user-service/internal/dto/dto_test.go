@@ -0,0 +1,166 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"user-service/internal/models"
+
+	"shared/utils"
+)
+
+// bind decodes and validates body the way utils.BindJSON does for a request
+func bind(body string, dst any) error {
+	if err := utils.DecodeJSON([]byte(body), dst); err != nil {
+		return err
+	}
+	return utils.Validate(dst)
+}
+
+// invalidFields returns the fields a ValidationFailed error names
+func invalidFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var customErr *utils.CustomError
+	if !errors.As(err, &customErr) || customErr.Fields == nil {
+		t.Fatalf("want a validation error, got %v", err)
+	}
+	var fields []string
+	for field := range customErr.Fields {
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func TestCreateUserProfileReqRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "every field",
+			body: `{"first_name":"An","last_name":"Nguyen","phone":"+84901234567","date_of_birth":"1990-05-31","gender":"female","bio":"Reader","privacy":{"bio":"public"}}`,
+			want: `{"first_name":"An","last_name":"Nguyen","phone":"+84901234567","date_of_birth":"1990-05-31","gender":"female","bio":"Reader","privacy":{"bio":"public","location":null,"avatar":null,"date_of_birth":null}}`,
+		},
+		{
+			name: "omitted fields stay nil",
+			body: `{"first_name":"An"}`,
+			want: `{"first_name":"An","last_name":null,"phone":null,"date_of_birth":null,"gender":null,"bio":null,"privacy":null}`,
+		},
+		{
+			name: "explicit nulls are nil too",
+			body: `{"first_name":null,"gender":null}`,
+			want: `{"first_name":null,"last_name":null,"phone":null,"date_of_birth":null,"gender":null,"bio":null,"privacy":null}`,
+		},
+		{
+			name: "timestamps are read as the date written",
+			body: `{"date_of_birth":"1990-05-31T00:00:00Z"}`,
+			want: `{"first_name":null,"last_name":null,"phone":null,"date_of_birth":"1990-05-31","gender":null,"bio":null,"privacy":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateUserProfileReq
+			if err := bind(tt.body, &req); err != nil {
+				t.Fatalf("bind: %v", err)
+			}
+			got, err := json.Marshal(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateUserProfileReqValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{name: "valid", body: `{"phone":"+84901234567","first_name":"An"}`},
+		{name: "phone without plus", body: `{"phone":"0901234567"}`, wantFields: []string{"phone"}},
+		{name: "phone with spaces", body: `{"phone":"+84 90 123 4567"}`, wantFields: []string{"phone"}},
+		{name: "phone with letters", body: `{"phone":"+84abc"}`, wantFields: []string{"phone"}},
+		{name: "first name too long", body: `{"first_name":"` + strings.Repeat("a", 101) + `"}`, wantFields: []string{"first_name"}},
+		{name: "last name too long", body: `{"last_name":"` + strings.Repeat("a", 101) + `"}`, wantFields: []string{"last_name"}},
+		{name: "bio too long", body: `{"bio":"` + strings.Repeat("a", 2001) + `"}`, wantFields: []string{"bio"}},
+		{name: "bio at the limit", body: `{"bio":"` + strings.Repeat("a", 2000) + `"}`},
+		{name: "date of birth in the future", body: `{"date_of_birth":"2999-01-01"}`, wantFields: []string{"date_of_birth"}},
+		{name: "date of birth not a date", body: `{"date_of_birth":"31/05/1990"}`, wantFields: []string{"date_of_birth"}},
+		{name: "unknown gender", body: `{"gender":"robot"}`, wantFields: []string{"gender"}},
+		{name: "wrong type", body: `{"first_name":1}`, wantFields: []string{"first_name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateUserProfileReq
+			fields := invalidFields(t, bind(tt.body, &req))
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("invalid fields %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestAddressReqValidation(t *testing.T) {
+	valid := `"label":"Home","street":"1 Le Loi","city":"Hanoi"`
+
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{name: "valid", body: `{` + valid + `,"country":"VN","zip_code":"100000"}`},
+		{name: "lower case country", body: `{` + valid + `,"country":"vn"}`, wantFields: []string{"country"}},
+		{name: "country name", body: `{` + valid + `,"country":"Vietnam"}`, wantFields: []string{"country"}},
+		{name: "alpha-3 country", body: `{` + valid + `,"country":"VNM"}`, wantFields: []string{"country"}},
+		{name: "unassigned country", body: `{` + valid + `,"country":"XX"}`, wantFields: []string{"country"}},
+		{name: "missing country", body: `{` + valid + `}`, wantFields: []string{"country"}},
+		{name: "zip code for another country", body: `{` + valid + `,"country":"US","zip_code":"ABC"}`, wantFields: []string{"zip_code"}},
+		{name: "label too long", body: `{"label":"` + strings.Repeat("a", 51) + `","street":"1 Le Loi","city":"Hanoi","country":"VN"}`, wantFields: []string{"label"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req AddressReq
+			fields := invalidFields(t, bind(tt.body, &req))
+			if strings.Join(fields, ",") != strings.Join(tt.wantFields, ",") {
+				t.Errorf("invalid fields %v, want %v", fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestCreateUserProfileReqGender(t *testing.T) {
+	tests := []struct {
+		body string
+		want models.Gender
+	}{
+		{body: `{"gender":"male"}`, want: models.GenderMale},
+		{body: `{"gender":"female"}`, want: models.GenderFemale},
+		{body: `{"gender":"other"}`, want: models.GenderOther},
+		{body: `{"gender":"not_specified"}`, want: models.GenderNotSpecified},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			var req CreateUserProfileReq
+			if err := bind(tt.body, &req); err != nil {
+				t.Fatalf("bind: %v", err)
+			}
+			if req.Gender == nil || *req.Gender != tt.want {
+				t.Errorf("gender %v, want %v", req.Gender, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+// Package export holds the pieces of the GDPR data export that are not
+// business logic: the sources that contribute files and link signing.
+package export
+
+import (
+	"context"
+	"fmt"
+
+	"shared/proto/data_export"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Source is one service contributing files to a user's data export
+type Source interface {
+	Name() string
+	Export(ctx context.Context, userID uint, email string) ([]*data_export.ExportFile, error)
+}
+
+// GRPCSource collects a remote service's files through the shared
+// data_export.DataExport contract
+type GRPCSource struct {
+	name   string
+	conn   *grpc.ClientConn
+	client data_export.DataExportClient
+}
+
+func NewGRPCSource(name, address string) (*GRPCSource, error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", name, err)
+	}
+
+	return &GRPCSource{
+		name:   name,
+		conn:   conn,
+		client: data_export.NewDataExportClient(conn),
+	}, nil
+}
+
+func (s *GRPCSource) Name() string {
+	return s.name
+}
+
+func (s *GRPCSource) Export(ctx context.Context, userID uint, email string) ([]*data_export.ExportFile, error) {
+	response, err := s.client.ExportUserData(ctx, &data_export.ExportUserDataRequest{
+		UserId: uint32(userID),
+		Email:  email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response.Files, nil
+}
+
+func (s *GRPCSource) Close() error {
+	return s.conn.Close()
+}
@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+	"log"
+
+	"user-service/internal/export"
+
+	"shared/proto/data_export"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ExportServer offers user-service's part of a data export over the shared
+// contract, the export job itself calls the source directly
+type ExportServer struct {
+	data_export.UnimplementedDataExportServer
+	source export.Source
+}
+
+func NewExportServer(source export.Source) *ExportServer {
+	return &ExportServer{source: source}
+}
+
+func (s *ExportServer) ExportUserData(ctx context.Context, req *data_export.ExportUserDataRequest) (*data_export.ExportUserDataResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	files, err := s.source.Export(ctx, uint(req.UserId), req.Email)
+	if err != nil {
+		log.Printf("Failed to export user data: %v", err)
+		return nil, status.Error(codes.Internal, "failed to export user data")
+	}
+	return &data_export.ExportUserDataResponse{Files: files}, nil
+}
@@ -2,11 +2,17 @@ package grpc
 
 import (
 	"context"
+	"errors"
 	"log"
+	"net/http"
+	"strings"
 
+	"user-service/internal/dto"
+	"user-service/internal/models"
 	"user-service/internal/services"
 
 	"shared/proto/user_service"
+	"shared/utils"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -14,12 +20,18 @@ import (
 
 type UserServer struct {
 	user_service.UnimplementedUserServiceServer
-	userService services.UserService
+	userService         services.UserService
+	preferencesService  services.PreferencesService
+	followService       services.FollowService
+	notificationService services.NotificationService
 }
 
-func NewUserServer(userService services.UserService) *UserServer {
+func NewUserServer(userService services.UserService, preferencesService services.PreferencesService, followService services.FollowService, notificationService services.NotificationService) *UserServer {
 	return &UserServer{
-		userService: userService,
+		userService:         userService,
+		preferencesService:  preferencesService,
+		followService:       followService,
+		notificationService: notificationService,
 	}
 }
 
@@ -30,10 +42,11 @@ func (s *UserServer) CreateUser(ctx context.Context, req *user_service.CreateUse
 		return nil, status.Error(codes.InvalidArgument, "email is required")
 	}
 
-	user, err := s.userService.CreateUser(req.Email)
+	user, err := s.userService.CreateUser(ctx, req.Email)
 	if err != nil {
 		log.Printf("Failed to create user: %v", err)
-		if err.Error() == "user with this email already exists" {
+		var customErr *utils.CustomError
+		if errors.As(err, &customErr) && customErr.Code == http.StatusConflict {
 			return nil, status.Error(codes.AlreadyExists, "user with this email already exists")
 		}
 		return nil, status.Error(codes.Internal, "failed to create user")
@@ -48,6 +61,42 @@ func (s *UserServer) CreateUser(ctx context.Context, req *user_service.CreateUse
 	}, nil
 }
 
+// maxBatchCreateUsers caps BatchCreateUsers to keep each insert small
+const maxBatchCreateUsers = 100
+
+func (s *UserServer) BatchCreateUsers(ctx context.Context, req *user_service.BatchCreateUsersRequest) (*user_service.BatchCreateUsersResponse, error) {
+	if len(req.Emails) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "emails is required")
+	}
+
+	seen := make(map[string]bool, len(req.Emails))
+	var emails []string
+	for _, email := range req.Emails {
+		if email == "" {
+			return nil, status.Error(codes.InvalidArgument, "emails must not contain an empty email")
+		}
+		if !seen[strings.ToLower(email)] {
+			seen[strings.ToLower(email)] = true
+			emails = append(emails, email)
+		}
+	}
+	if len(emails) > maxBatchCreateUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d emails per call", maxBatchCreateUsers)
+	}
+
+	users, existingEmails, err := s.userService.CreateUsers(ctx, emails)
+	if err != nil {
+		return nil, grpcError(err, "failed to create users")
+	}
+	log.Printf("Created %d users, %d already existed", len(users), len(existingEmails))
+
+	response := &user_service.BatchCreateUsersResponse{ExistingEmails: existingEmails}
+	for i := range users {
+		response.Users = append(response.Users, toProtoUser(&users[i]))
+	}
+	return response, nil
+}
+
 func (s *UserServer) GetUserByEmail(ctx context.Context, req *user_service.GetUserByEmailRequest) (*user_service.GetUserByEmailResponse, error) {
 	log.Printf("Received GetUserByEmail request for email: %s", req.Email)
 
@@ -66,6 +115,173 @@ func (s *UserServer) GetUserByEmail(ctx context.Context, req *user_service.GetUs
 	return &user_service.GetUserByEmailResponse{
 		Id:     uint32(user.ID),
 		Email:  user.Email,
-		Status: user.Status,
+		Status: user.Status.String(),
+	}, nil
+}
+
+func (s *UserServer) GetUserPreferences(ctx context.Context, req *user_service.GetUserPreferencesRequest) (*user_service.GetUserPreferencesResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	preferences, err := s.preferencesService.GetPreferences(uint(req.UserId))
+	if err != nil {
+		log.Printf("Failed to get preferences: %v", err)
+		return nil, status.Error(codes.Internal, "failed to get preferences")
+	}
+
+	return &user_service.GetUserPreferencesResponse{
+		UserId:               req.UserId,
+		Theme:                preferences.Theme.String(),
+		Language:             preferences.Language,
+		Timezone:             preferences.Timezone,
+		ItemsPerPage:         int32(preferences.ItemsPerPage),
+		EmailSecurityAlerts:  preferences.EmailNotifications.SecurityAlerts,
+		EmailRecommendations: preferences.EmailNotifications.Recommendations,
+		EmailNewsletter:      preferences.EmailNotifications.Newsletter,
+	}, nil
+}
+
+// maxBatchGetUsers caps BatchGetUsers so one call cannot load the whole table
+const maxBatchGetUsers = 100
+
+func (s *UserServer) GetUserByID(ctx context.Context, req *user_service.GetUserByIDRequest) (*user_service.GetUserByIDResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	user, err := s.userService.GetUserByID(uint(req.UserId))
+	if err != nil {
+		return nil, grpcError(err, "failed to get user")
+	}
+
+	return &user_service.GetUserByIDResponse{User: toProtoUser(user)}, nil
+}
+
+func (s *UserServer) BatchGetUsers(ctx context.Context, req *user_service.BatchGetUsersRequest) (*user_service.BatchGetUsersResponse, error) {
+	if len(req.UserIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_ids is required")
+	}
+
+	seen := make(map[uint32]bool, len(req.UserIds))
+	var ids []uint
+	for _, id := range req.UserIds {
+		if id == 0 {
+			return nil, status.Error(codes.InvalidArgument, "user_ids must not contain 0")
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, uint(id))
+		}
+	}
+	if len(ids) > maxBatchGetUsers {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d user_ids per call", maxBatchGetUsers)
+	}
+
+	users, err := s.userService.GetUsersByIDs(ids)
+	if err != nil {
+		return nil, grpcError(err, "failed to get users")
+	}
+
+	response := &user_service.BatchGetUsersResponse{}
+	found := make(map[uint32]bool, len(users))
+	for i := range users {
+		response.Users = append(response.Users, toProtoUser(&users[i]))
+		found[uint32(users[i].ID)] = true
+	}
+	for _, id := range ids {
+		if !found[uint32(id)] {
+			response.NotFoundIds = append(response.NotFoundIds, uint32(id))
+		}
+	}
+	return response, nil
+}
+
+func (s *UserServer) GetPublicProfile(ctx context.Context, req *user_service.GetPublicProfileRequest) (*user_service.GetPublicProfileResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	profile, err := s.userService.GetPublicProfile(uint(req.UserId))
+	if err != nil {
+		return nil, grpcError(err, "failed to get public profile")
+	}
+
+	return &user_service.GetPublicProfileResponse{
+		UserId:           uint32(profile.UserID),
+		DisplayName:      profile.DisplayName,
+		AvatarUrl:        profile.AvatarURL,
+		AvatarThumbnails: profile.AvatarThumbnails,
+		Bio:              profile.Bio,
+		City:             profile.City,
+		Country:          profile.Country,
+		AgeRange:         profile.AgeRange,
+	}, nil
+}
+
+func (s *UserServer) GetFollowing(ctx context.Context, req *user_service.GetFollowingRequest) (*user_service.GetFollowingResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	following, err := s.followService.GetFollowingIDs(uint(req.UserId))
+	if err != nil {
+		return nil, grpcError(err, "failed to get following")
+	}
+
+	response := &user_service.GetFollowingResponse{UserIds: make([]uint32, len(following))}
+	for i, id := range following {
+		response.UserIds[i] = uint32(id)
+	}
+	return response, nil
+}
+
+func (s *UserServer) CreateNotification(ctx context.Context, req *user_service.CreateNotificationRequest) (*user_service.CreateNotificationResponse, error) {
+	if req.UserId == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	notification, err := s.notificationService.Notify(ctx, dto.CreateNotificationReq{
+		UserID: uint(req.UserId),
+		Type:   models.NotificationType(req.Type),
+		Title:  req.Title,
+		Body:   req.Body,
+		Link:   req.Link,
+	})
+	if err != nil {
+		return nil, grpcError(err, "failed to create notification")
+	}
+	if notification == nil {
+		return &user_service.CreateNotificationResponse{}, nil
+	}
+
+	return &user_service.CreateNotificationResponse{
+		Created:        true,
+		NotificationId: uint32(notification.ID),
 	}, nil
 }
+
+func toProtoUser(user *models.User) *user_service.User {
+	return &user_service.User{
+		Id:     uint32(user.ID),
+		Email:  user.Email,
+		Status: user.Status.String(),
+	}
+}
+
+// grpcError maps service errors onto gRPC codes, anything unexpected is
+// logged and reported as Internal with the given message
+func grpcError(err error, message string) error {
+	var customErr *utils.CustomError
+	if errors.As(err, &customErr) {
+		switch customErr.Code {
+		case http.StatusNotFound:
+			return status.Error(codes.NotFound, customErr.Message)
+		case http.StatusBadRequest:
+			return status.Error(codes.InvalidArgument, customErr.Message)
+		}
+	}
+
+	log.Printf("%s: %v", message, err)
+	return status.Error(codes.Internal, message)
+}
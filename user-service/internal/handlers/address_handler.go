@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"user-service/internal/dto"
+	"user-service/internal/services"
+
+	"shared/locale"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddressHandler struct {
+	addressService services.AddressService
+}
+
+func NewAddressHandler(addressService services.AddressService) *AddressHandler {
+	return &AddressHandler{addressService: addressService}
+}
+
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	addresses, err := h.addressService.ListAddresses(c.GetUint("user_id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, gin.H{"addresses": addresses})
+}
+
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.GetAddress(c.GetUint("user_id"), addressID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, address)
+}
+
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	var req dto.AddressReq
+	if err := utils.BindJSON(c, &req); err != nil {
+		log.Println("Error binding JSON:", err)
+		utils.HandleError(c, err)
+		return
+	}
+
+	address, err := h.addressService.CreateAddress(requestContext(c), c.GetUint("user_id"), req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusCreated, address)
+}
+
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.AddressReq
+	if err := utils.BindJSON(c, &req); err != nil {
+		log.Println("Error binding JSON:", err)
+		utils.HandleError(c, err)
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(requestContext(c), c.GetUint("user_id"), addressID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, address)
+}
+
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	addressID, ok := addressIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(requestContext(c), c.GetUint("user_id"), addressID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func addressIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, utils.BadRequest("Invalid address ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"user-service/internal/dto"
+	"user-service/internal/services"
+
+	"shared/locale"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	userService services.UserService
+}
+
+func NewAdminHandler(userService services.UserService) *AdminHandler {
+	return &AdminHandler{userService: userService}
+}
+
+// exportBatchSize is how many users ExportUsers reads per page
+const exportBatchSize = 100
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var req dto.ListUsersReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Println("Error binding query:", err)
+		utils.HandleError(c, utils.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	res, err := h.userService.ListUsers(req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, res)
+}
+
+// ExportUsers streams every user matching the ListUsers filters as CSV,
+// paging through the directory so the result set is never held in memory
+func (h *AdminHandler) ExportUsers(c *gin.Context) {
+	var req dto.ListUsersReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Println("Error binding query:", err)
+		utils.HandleError(c, utils.BadRequest("Invalid query parameters"))
+		return
+	}
+	req.Cursor = ""
+	req.Limit = exportBatchSize
+
+	// Fetch the first page before writing headers so errors stay JSON
+	res, err := h.userService.ListUsers(req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	filename := "users-" + time.Now().UTC().Format("20060102-150405") + ".csv"
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"id", "email", "status", "first_name", "last_name", "created_at"})
+	for {
+		for _, user := range res.Users {
+			w.Write([]string{
+				strconv.FormatUint(uint64(user.ID), 10),
+				csvSafe(user.Email),
+				user.Status.String(),
+				csvSafe(deref(user.FirstName)),
+				csvSafe(deref(user.LastName)),
+				user.CreatedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		w.Flush()
+		if w.Error() != nil || res.NextCursor == "" {
+			break
+		}
+
+		req.Cursor = res.NextCursor
+		if res, err = h.userService.ListUsers(req); err != nil {
+			// Headers are already sent, all we can do is stop
+			log.Printf("Error exporting users: %v", err)
+			break
+		}
+	}
+}
+
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, user)
+}
+
+func (h *AdminHandler) ChangeUserStatus(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ChangeUserStatusReq
+	if err := utils.BindJSON(c, &req); err != nil {
+		log.Println("Error binding JSON:", err)
+		utils.HandleError(c, err)
+		return
+	}
+
+	user, err := h.userService.ChangeUserStatus(requestContext(c), userID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, user)
+}
+
+func (h *AdminHandler) GetUserStatusHistory(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	changes, err := h.userService.GetUserStatusHistory(userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, gin.H{"changes": changes})
+}
+
+func userIDParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, utils.BadRequest("Invalid user ID"))
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// csvSafe keeps spreadsheet apps from evaluating user-supplied values as
+// formulas
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
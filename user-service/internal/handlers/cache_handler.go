@@ -5,6 +5,8 @@ import (
 
 	"user-service/internal/clients"
 
+	"shared/locale"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -22,7 +24,7 @@ func NewCacheHandler(authClient *clients.CachedAuthClient) *CacheHandler {
 func (h *CacheHandler) GetCacheStats(c *gin.Context) {
 	stats := h.authClient.GetCacheStats()
 
-	c.JSON(http.StatusOK, gin.H{
+	locale.JSON(c, http.StatusOK, gin.H{
 		"service": "user-service",
 		"cache":   stats,
 	})
@@ -32,7 +34,7 @@ func (h *CacheHandler) GetCacheStats(c *gin.Context) {
 func (h *CacheHandler) GetCacheMetrics(c *gin.Context) {
 	metrics := h.authClient.GetMetrics()
 
-	c.JSON(http.StatusOK, gin.H{
+	locale.JSON(c, http.StatusOK, gin.H{
 		"service": "user-service",
 		"metrics": metrics,
 	})
@@ -42,7 +44,7 @@ func (h *CacheHandler) GetCacheMetrics(c *gin.Context) {
 func (h *CacheHandler) ClearCache(c *gin.Context) {
 	h.authClient.ClearCache()
 
-	c.JSON(http.StatusOK, gin.H{
+	locale.JSON(c, http.StatusOK, gin.H{
 		"service": "user-service",
 		"message": "Cache cleared successfully",
 	})
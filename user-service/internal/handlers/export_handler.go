@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"user-service/internal/services"
+
+	"shared/locale"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExportHandler struct {
+	exportService services.ExportService
+}
+
+func NewExportHandler(exportService services.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// RequestExport starts a data export in the background, poll GetExport
+// until it is completed
+func (h *ExportHandler) RequestExport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	export, err := h.exportService.RequestExport(userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusAccepted, export)
+}
+
+func (h *ExportHandler) GetExport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	export, err := h.exportService.GetExport(userID, c.Param("id"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, export)
+}
+
+// Download serves the ZIP behind a signed link, no login required
+func (h *ExportHandler) Download(c *gin.Context) {
+	exportID := c.Param("id")
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		utils.HandleError(c, utils.Forbidden("Download link is invalid or has expired"))
+		return
+	}
+
+	file, err := h.exportService.OpenDownload(c.Request.Context(), exportID, expires, c.Query("signature"))
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="data-export-`+exportID+`.zip"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	if _, err := io.Copy(c.Writer, file); err != nil {
+		log.Printf("Error sending data export %s: %v", exportID, err)
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"user-service/internal/dto"
+	"user-service/internal/services"
+
+	"shared/locale"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FollowHandler struct {
+	followService services.FollowService
+}
+
+func NewFollowHandler(followService services.FollowService) *FollowHandler {
+	return &FollowHandler{followService: followService}
+}
+
+func (h *FollowHandler) Follow(c *gin.Context) {
+	h.act(c, h.followService.Follow)
+}
+
+func (h *FollowHandler) Unfollow(c *gin.Context) {
+	h.act(c, h.followService.Unfollow)
+}
+
+func (h *FollowHandler) Block(c *gin.Context) {
+	h.act(c, h.followService.Block)
+}
+
+func (h *FollowHandler) Unblock(c *gin.Context) {
+	h.act(c, h.followService.Unblock)
+}
+
+func (h *FollowHandler) Mute(c *gin.Context) {
+	h.act(c, h.followService.Mute)
+}
+
+func (h *FollowHandler) Unmute(c *gin.Context) {
+	h.act(c, h.followService.Unmute)
+}
+
+func (h *FollowHandler) ListFollowers(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	h.list(c, userID, h.followService.ListFollowers)
+}
+
+func (h *FollowHandler) ListFollowing(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	h.list(c, userID, h.followService.ListFollowing)
+}
+
+func (h *FollowHandler) ListBlocked(c *gin.Context) {
+	h.list(c, c.GetUint("user_id"), h.followService.ListBlocked)
+}
+
+func (h *FollowHandler) ListMuted(c *gin.Context) {
+	h.list(c, c.GetUint("user_id"), h.followService.ListMuted)
+}
+
+// act applies a follow, block or mute change from the caller to the user
+// in the :id path parameter
+func (h *FollowHandler) act(c *gin.Context, apply func(userID, targetID uint) error) {
+	targetID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	if err := apply(c.GetUint("user_id"), targetID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *FollowHandler) list(c *gin.Context, userID uint, list func(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error)) {
+	var req dto.RelatedUsersReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Println("Error binding query:", err)
+		utils.HandleError(c, utils.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	res, err := list(userID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, res)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"user-service/internal/dto"
+	"user-service/internal/services"
+
+	"shared/locale"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HistoryHandler struct {
+	historyService services.HistoryService
+}
+
+func NewHistoryHandler(historyService services.HistoryService) *HistoryHandler {
+	return &HistoryHandler{historyService: historyService}
+}
+
+// GetMyHistory lists changes to the caller's account. Admins who made a
+// change are shown by role only.
+func (h *HistoryHandler) GetMyHistory(c *gin.Context) {
+	h.listHistory(c, c.GetUint("user_id"), false)
+}
+
+// GetUserHistory is the admin view of a user's changes, including which
+// admin made them
+func (h *HistoryHandler) GetUserHistory(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	h.listHistory(c, userID, true)
+}
+
+func (h *HistoryHandler) listHistory(c *gin.Context, userID uint, showActors bool) {
+	var req dto.HistoryReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Println("Error binding query:", err)
+		utils.HandleError(c, utils.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	res, err := h.historyService.ListHistory(userID, req, showActors)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, res)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+
+	"user-service/internal/dto"
+	"user-service/internal/services"
+
+	"shared/locale"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// ListNotifications returns the inbox with the unread count, pass
+// unread=true for unread notifications only
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	var req dto.NotificationsReq
+	if err := c.ShouldBindQuery(&req); err != nil {
+		log.Println("Error binding query:", err)
+		utils.HandleError(c, utils.BadRequest("Invalid query parameters"))
+		return
+	}
+
+	res, err := h.notificationService.ListNotifications(c.GetUint("user_id"), req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, res)
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.HandleError(c, utils.BadRequest("Invalid notification ID"))
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.GetUint("user_id"), uint(id)); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	if err := h.notificationService.MarkAllRead(c.GetUint("user_id")); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
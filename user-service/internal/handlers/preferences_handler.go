@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"user-service/internal/dto"
+	"user-service/internal/services"
+
+	"shared/locale"
+	"shared/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PreferencesHandler struct {
+	preferencesService services.PreferencesService
+}
+
+func NewPreferencesHandler(preferencesService services.PreferencesService) *PreferencesHandler {
+	return &PreferencesHandler{preferencesService: preferencesService}
+}
+
+func (h *PreferencesHandler) GetPreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	preferences, err := h.preferencesService.GetPreferences(userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, preferences)
+}
+
+func (h *PreferencesHandler) UpdatePreferences(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req dto.UpdatePreferencesReq
+	if err := utils.BindJSON(c, &req); err != nil {
+		log.Println("Error binding JSON:", err)
+		utils.HandleError(c, err)
+		return
+	}
+
+	preferences, err := h.preferencesService.UpdatePreferences(userID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, preferences)
+}
@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"context"
+
+	"user-service/internal/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// requestContext carries the caller and request ID to the services so
+// changes land in the history with who made them
+func requestContext(c *gin.Context) context.Context {
+	ctx := audit.WithActor(c.Request.Context(), audit.Actor{
+		UserID: c.GetUint("user_id"),
+		Email:  c.GetString("user_email"),
+		Admin:  c.GetBool("is_admin"),
+	})
+	return audit.WithRequestID(ctx, c.GetString("request_id"))
+}
@@ -1,23 +1,30 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"log"
 	"net/http"
 
 	"user-service/internal/dto"
 	"user-service/internal/services"
 
+	"shared/locale"
 	"shared/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	userService services.UserService
+	userService    services.UserService
+	maxAvatarBytes int64
 }
 
-func NewUserHandler(userService services.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService services.UserService, maxAvatarBytes int64) *UserHandler {
+	return &UserHandler{
+		userService:    userService,
+		maxAvatarBytes: maxAvatarBytes,
+	}
 }
 
 func (h *UserHandler) GetUser(c *gin.Context) {
@@ -29,26 +36,26 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	locale.JSON(c, http.StatusOK, user)
 }
 
 func (h *UserHandler) CreateUserProfile(c *gin.Context) {
 	userID := c.GetUint("user_id")
 
 	var req dto.CreateUserProfileReq
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := utils.BindJSON(c, &req); err != nil {
 		log.Println("Error binding JSON:", err)
-		utils.HandleError(c, utils.BadRequest("Invalid request body"))
+		utils.HandleError(c, err)
 		return
 	}
 
-	user_profile, err := h.userService.CreateUserProfile(userID, req)
+	user_profile, err := h.userService.CreateUserProfile(requestContext(c), userID, req)
 	if err != nil {
 		utils.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, user_profile)
+	locale.JSON(c, http.StatusCreated, user_profile)
 }
 
 func (h *UserHandler) GetUserProfile(c *gin.Context) {
@@ -61,5 +68,121 @@ func (h *UserHandler) GetUserProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, profile)
+	locale.JSON(c, http.StatusOK, profile)
+}
+
+// GetPublicProfile shows another user's profile as everyone else sees it,
+// honouring their privacy settings
+func (h *UserHandler) GetPublicProfile(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	profile, err := h.userService.GetPublicProfile(userID)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, profile)
+}
+
+func (h *UserHandler) ReplaceUserProfile(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req dto.CreateUserProfileReq
+	if err := utils.BindJSON(c, &req); err != nil {
+		log.Println("Error binding JSON:", err)
+		utils.HandleError(c, err)
+		return
+	}
+
+	profile, err := h.userService.ReplaceUserProfile(requestContext(c), userID, req)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, profile)
+}
+
+// PatchUserProfile accepts a JSON Merge Patch, null clears a field
+func (h *UserHandler) PatchUserProfile(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	contentType := c.ContentType()
+	if contentType != "application/merge-patch+json" && contentType != "application/json" {
+		utils.HandleError(c, utils.NewCustomError(http.StatusUnsupportedMediaType, "Content-Type must be application/merge-patch+json"))
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		utils.HandleError(c, utils.BadRequest("Invalid request body"))
+		return
+	}
+
+	profile, err := h.userService.PatchUserProfile(requestContext(c), userID, patch)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, profile)
+}
+
+func (h *UserHandler) DeleteUserProfile(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	if err := h.userService.DeleteUserProfile(requestContext(c), userID); err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UploadAvatar takes the image from the "avatar" field of a multipart form
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxAvatarBytes+64*1024)
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			utils.HandleError(c, utils.NewCustomError(http.StatusRequestEntityTooLarge, "avatar file is too large"))
+			return
+		}
+		utils.HandleError(c, utils.BadRequest("Multipart field \"avatar\" is required"))
+		return
+	}
+	if fileHeader.Size > h.maxAvatarBytes {
+		utils.HandleError(c, utils.NewCustomError(http.StatusRequestEntityTooLarge, "avatar file is too large"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		utils.HandleError(c, utils.BadRequest("Invalid avatar upload"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		utils.HandleError(c, utils.BadRequest("Invalid avatar upload"))
+		return
+	}
+
+	profile, err := h.userService.UploadAvatar(requestContext(c), userID, data)
+	if err != nil {
+		utils.HandleError(c, err)
+		return
+	}
+
+	locale.JSON(c, http.StatusOK, profile)
 }
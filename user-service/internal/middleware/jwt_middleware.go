@@ -4,10 +4,13 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
 
 	"user-service/internal/clients"
 
+	"shared/locale"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -24,13 +27,13 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		authHeader := c.GetHeader("Authorization")
 		log.Println("authHeader", authHeader)
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
+			locale.JSON(c, http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			c.Abort()
 			return
 		}
 
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization format. Use 'Bearer <token>'"})
+			locale.JSON(c, http.StatusUnauthorized, gin.H{"error": "Invalid authorization format. Use 'Bearer <token>'"})
 			c.Abort()
 			return
 		}
@@ -42,13 +45,13 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		response, err := m.authClient.ValidateToken(ctx, tokenString)
 		if err != nil {
 			log.Printf("Failed to call auth service: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication service unavailable"})
+			locale.JSON(c, http.StatusInternalServerError, gin.H{"error": "Authentication service unavailable"})
 			c.Abort()
 			return
 		}
 
 		if !response.IsValid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": response.ErrorMessage})
+			locale.JSON(c, http.StatusUnauthorized, gin.H{"error": response.ErrorMessage})
 			c.Abort()
 			return
 		}
@@ -59,7 +62,45 @@ func (m *JWTMiddleware) ValidateToken() gin.HandlerFunc {
 		c.Set("user_email", response.Claims.Email)
 		c.Set("user_id", uint(response.Claims.UserId))
 		c.Set("user_claims", response.Claims)
+		c.Set("token_scope", response.Claims.Scope)
+		c.Set(locale.UserLocaleKey, response.Claims.Locale)
+		c.Set(locale.UserTimezoneKey, response.Claims.Timezone)
+
+		c.Next()
+	}
+}
+
+// RequireScope rejects downscoped tokens that weren't granted scope.
+// Regular user tokens carry no scope and are always allowed.
+func (m *JWTMiddleware) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenScope := c.GetString("token_scope")
+		if tokenScope != "" && !slices.Contains(strings.Fields(tokenScope), scope) {
+			locale.JSON(c, http.StatusForbidden, gin.H{"error": "Token is missing required scope: " + scope})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdmin only lets through full login tokens of the configured admin
+// accounts. Downscoped tokens never reach admin endpoints.
+func (m *JWTMiddleware) RequireAdmin(adminEmails []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := c.GetString("user_email")
+		isAdmin := slices.ContainsFunc(adminEmails, func(adminEmail string) bool {
+			return strings.EqualFold(adminEmail, email)
+		})
+
+		if !isAdmin || c.GetString("token_scope") != "" {
+			locale.JSON(c, http.StatusForbidden, gin.H{"error": "Admin access required"})
+			c.Abort()
+			return
+		}
 
+		c.Set("is_admin", true)
 		c.Next()
 	}
 }
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+type EntityType string
+
+const (
+	EntityUser        EntityType = "user"
+	EntityUserProfile EntityType = "user_profile"
+	EntityUserAddress EntityType = "user_address"
+)
+
+type ChangeAction string
+
+const (
+	ChangeCreate ChangeAction = "create"
+	ChangeUpdate ChangeAction = "update"
+	ChangeDelete ChangeAction = "delete"
+)
+
+type ActorRole string
+
+const (
+	ActorRoleUser   ActorRole = "user"
+	ActorRoleAdmin  ActorRole = "admin"
+	ActorRoleSystem ActorRole = "system"
+)
+
+// ChangeEntry is one version of an entity in the change history. Version
+// counts up from 1 per entity, sensitive values in Changes are masked.
+type ChangeEntry struct {
+	ID         uint          `gorm:"primaryKey" json:"id"`
+	UserID     uint          `gorm:"not null;index" json:"user_id"` // whose data changed
+	EntityType EntityType    `gorm:"size:20;not null;uniqueIndex:idx_change_entity_version" json:"entity_type"`
+	EntityID   uint          `gorm:"not null;uniqueIndex:idx_change_entity_version" json:"entity_id"`
+	Version    int           `gorm:"not null;uniqueIndex:idx_change_entity_version" json:"version"`
+	Action     ChangeAction  `gorm:"size:10;not null" json:"action"`
+	Changes    []FieldChange `gorm:"serializer:json;type:text" json:"changes"`
+	ActorID    *uint         `json:"actor_id,omitempty"`
+	ActorEmail string        `gorm:"size:255" json:"actor_email,omitempty"`
+	ActorRole  ActorRole     `gorm:"size:10;not null" json:"actor_role"`
+	RequestID  string        `gorm:"size:64;index" json:"request_id,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// FieldChange is one field of a diff, Old is unset on create and New on
+// delete
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+func (ChangeEntry) TableName() string {
+	return "change_history"
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DataExport is a user's request for a copy of all their data. Each source
+// service's part is kept as it arrives so a failed or interrupted export
+// resumes where it stopped.
+type DataExport struct {
+	ID             string       `gorm:"primaryKey;size:32" json:"id"`
+	UserID         uint         `gorm:"not null;index" json:"user_id"`
+	Status         ExportStatus `gorm:"type:varchar(10);not null" json:"status"`
+	CompletedParts []string     `gorm:"serializer:json;type:text" json:"-"` // names of the sources already collected
+	BlobKey        string       `gorm:"size:255" json:"-"`                  // the finished ZIP
+	Error          string       `gorm:"size:500" json:"error,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+	CompletedAt    *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt      *time.Time   `json:"expires_at,omitempty"` // when the ZIP is deleted
+}
+
+func (DataExport) TableName() string {
+	return "data_exports"
+}
+
+type ExportStatus int
+
+const (
+	ExportStatusPending ExportStatus = iota
+	ExportStatusRunning
+	ExportStatusCompleted
+	ExportStatusFailed
+	ExportStatusExpired
+)
+
+func (s ExportStatus) String() string {
+	switch s {
+	case ExportStatusRunning:
+		return "running"
+	case ExportStatusCompleted:
+		return "completed"
+	case ExportStatusFailed:
+		return "failed"
+	case ExportStatusExpired:
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+func (s *ExportStatus) FromString(str string) error {
+	switch str {
+	case "pending":
+		*s = ExportStatusPending
+	case "running":
+		*s = ExportStatusRunning
+	case "completed":
+		*s = ExportStatusCompleted
+	case "failed":
+		*s = ExportStatusFailed
+	case "expired":
+		*s = ExportStatusExpired
+	default:
+		return fmt.Errorf("invalid export status: %s", str)
+	}
+	return nil
+}
+
+// Unfinished reports whether the export still has work to do
+func (s ExportStatus) Unfinished() bool {
+	return s == ExportStatusPending || s == ExportStatusRunning
+}
+
+func (s ExportStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s ExportStatus) Value() (driver.Value, error) {
+	return s.String(), nil
+}
+
+func (s *ExportStatus) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case string:
+		return s.FromString(v)
+	case []byte:
+		return s.FromString(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into ExportStatus", value)
+	}
+}
@@ -2,7 +2,9 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"slices"
 )
 
 type UserStatus int
@@ -50,6 +52,39 @@ func (s *UserStatus) FromString(str string) error {
 	return nil
 }
 
+// allowedStatusTransitions lists where each status may move, except that
+// any status may move to banned
+var allowedStatusTransitions = map[UserStatus][]UserStatus{
+	UserStatusPendingVerification: {UserStatusActive},
+	UserStatusActive:              {UserStatusSuspended},
+	UserStatusSuspended:           {UserStatusActive},
+}
+
+// CanTransitionTo reports whether an admin may move a user from s to next.
+// Banned is final.
+func (s UserStatus) CanTransitionTo(next UserStatus) bool {
+	if s == UserStatusBanned {
+		return false
+	}
+	if next == UserStatusBanned {
+		return true
+	}
+	return slices.Contains(allowedStatusTransitions[s], next)
+}
+
+// Statuses are exchanged as their string names in JSON
+func (s UserStatus) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+func (s *UserStatus) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil || s.FromString(str) != nil {
+		return fmt.Errorf("must be one of active, inactive, suspended, pending_verification or banned")
+	}
+	return nil
+}
+
 func (s UserStatus) Value() (driver.Value, error) {
 	return s.String(), nil
 }
@@ -108,6 +143,19 @@ func (g *Gender) FromString(str string) error {
 	return nil
 }
 
+// Genders are exchanged as their string names in JSON
+func (g Gender) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.String())
+}
+
+func (g *Gender) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil || g.FromString(str) != nil {
+		return fmt.Errorf("must be one of male, female, other or not_specified")
+	}
+	return nil
+}
+
 func (g Gender) Value() (driver.Value, error) {
 	return g.String(), nil
 }
@@ -163,6 +211,19 @@ func (t *Theme) FromString(str string) error {
 	return nil
 }
 
+// Themes are exchanged as their string names in JSON
+func (t Theme) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+func (t *Theme) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil || t.FromString(str) != nil {
+		return fmt.Errorf("must be one of light, dark or auto")
+	}
+	return nil
+}
+
 func (t Theme) Value() (driver.Value, error) {
 	return t.String(), nil
 }
@@ -182,3 +243,67 @@ func (t *Theme) Scan(value interface{}) error {
 		return fmt.Errorf("cannot scan %T into Theme", value)
 	}
 }
+
+// Visibility decides who may see a profile field
+type Visibility int
+
+const (
+	VisibilityPublic Visibility = iota
+	VisibilityOnlyMe
+)
+
+func (v Visibility) String() string {
+	switch v {
+	case VisibilityOnlyMe:
+		return "only_me"
+	default:
+		return "public"
+	}
+}
+
+func (v *Visibility) FromString(str string) error {
+	switch str {
+	case "public":
+		*v = VisibilityPublic
+	case "only_me":
+		*v = VisibilityOnlyMe
+	default:
+		return fmt.Errorf("invalid visibility: %s", str)
+	}
+	return nil
+}
+
+// Visibilities are exchanged as their string names in JSON
+func (v Visibility) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+func (v *Visibility) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil || v.FromString(str) != nil {
+		return fmt.Errorf("must be one of public or only_me")
+	}
+	return nil
+}
+
+func (v Visibility) Value() (driver.Value, error) {
+	return v.String(), nil
+}
+
+// Scan treats NULL as only_me, so profiles created before a setting existed
+// keep the field private until the user opts in
+func (v *Visibility) Scan(value interface{}) error {
+	if value == nil {
+		*v = VisibilityOnlyMe
+		return nil
+	}
+
+	switch s := value.(type) {
+	case string:
+		return v.FromString(s)
+	case []byte:
+		return v.FromString(string(s))
+	default:
+		return fmt.Errorf("cannot scan %T into Visibility", value)
+	}
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestGenderJSON(t *testing.T) {
+	tests := []struct {
+		gender Gender
+		json   string
+	}{
+		{GenderNotSpecified, `"not_specified"`},
+		{GenderMale, `"male"`},
+		{GenderFemale, `"female"`},
+		{GenderOther, `"other"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gender.String(), func(t *testing.T) {
+			data, err := json.Marshal(tt.gender)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.json {
+				t.Errorf("MarshalJSON = %s, want %s", data, tt.json)
+			}
+
+			var got Gender
+			if err := json.Unmarshal([]byte(tt.json), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.gender {
+				t.Errorf("UnmarshalJSON(%s) = %v, want %v", tt.json, got, tt.gender)
+			}
+		})
+	}
+}
+
+func TestGenderUnmarshalJSONInvalid(t *testing.T) {
+	for _, data := range []string{`"robot"`, `"Male"`, `1`, `true`, `{}`} {
+		t.Run(data, func(t *testing.T) {
+			got := GenderFemale
+			if err := json.Unmarshal([]byte(data), &got); err == nil {
+				t.Errorf("UnmarshalJSON(%s) = %v, want an error", data, got)
+			}
+		})
+	}
+}
+
+// An empty string is accepted as not_specified, as older clients send it
+func TestGenderUnmarshalJSONEmpty(t *testing.T) {
+	got := GenderFemale
+	if err := json.Unmarshal([]byte(`""`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != GenderNotSpecified {
+		t.Errorf("got %v, want not_specified", got)
+	}
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// NotificationType says what a notification is about. Other services pick
+// one when calling CreateNotification.
+type NotificationType string
+
+const (
+	NotificationPasswordChanged NotificationType = "password_changed"
+	NotificationNewDeviceLogin  NotificationType = "new_device_login"
+	NotificationPriceDrop       NotificationType = "price_drop"
+	NotificationLoanDue         NotificationType = "loan_due"
+)
+
+var notificationTypes = map[NotificationType]bool{
+	NotificationPasswordChanged: true,
+	NotificationNewDeviceLogin:  true,
+	NotificationPriceDrop:       true,
+	NotificationLoanDue:         true,
+}
+
+func (t NotificationType) Valid() bool {
+	return notificationTypes[t]
+}
+
+// Security notifications always reach the inbox, users can only turn off
+// their emails with EmailNotifications.SecurityAlerts
+func (t NotificationType) Security() bool {
+	return t == NotificationPasswordChanged || t == NotificationNewDeviceLogin
+}
+
+type Notification struct {
+	ID        uint             `gorm:"primaryKey" json:"id"`
+	UserID    uint             `gorm:"not null;index:idx_notification_user_read" json:"-"`
+	Type      NotificationType `gorm:"size:50;not null" json:"type"`
+	Title     string           `gorm:"size:255;not null" json:"title"`
+	Body      string           `gorm:"type:text" json:"body"`
+	Link      string           `gorm:"size:500" json:"link,omitempty"`
+	ReadAt    *time.Time       `gorm:"index:idx_notification_user_read" json:"read_at"`
+	CreatedAt time.Time        `gorm:"index" json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
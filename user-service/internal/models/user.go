@@ -9,11 +9,15 @@ import (
 type User struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
 	Email     string         `gorm:"uniqueIndex;not null;size:255" json:"email"`
-	Status    string         `gorm:"type:varchar(20);default:'active'" json:"status"`
+	Status    UserStatus     `gorm:"type:varchar(20);default:'active'" json:"status"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Kept in step with user_follows by the follow repository
+	FollowersCount int `gorm:"not null;default:0" json:"followers_count"`
+	FollowingCount int `gorm:"not null;default:0" json:"following_count"`
+
 	Profile UserProfile `gorm:"foreignKey:ID;references:ID;constraint:OnDelete:CASCADE" json:"profile,omitempty"`
 }
 
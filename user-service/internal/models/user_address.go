@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MaxAddressesPerUser keeps the address book a reasonable size
+const MaxAddressesPerUser = 20
+
+// UserAddress is one entry of a user's address book. Exactly one address
+// of a user with any addresses is the default shipping address.
+type UserAddress struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"-"`
+	Label     string    `gorm:"size:50;not null" json:"label"` // e.g. home, work
+	Street    string    `gorm:"serializer:pii;type:text;not null" json:"street"`
+	City      string    `gorm:"serializer:pii;type:text;not null" json:"city"`
+	State     *string   `gorm:"serializer:pii;type:text" json:"state,omitempty"`
+	ZipCode   *string   `gorm:"serializer:pii;type:text" json:"zip_code,omitempty"`
+	Country   string    `gorm:"size:100;not null" json:"country"` // ISO 3166-1 alpha-2, older rows may hold names
+	IsDefault bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (UserAddress) TableName() string {
+	return "user_addresses"
+}
+
+// AfterCreate writes the encrypted fields again now the row has its ID,
+// their ciphertext is bound to it
+func (a *UserAddress) AfterCreate(tx *gorm.DB) error {
+	return tx.Model(a).Select("street", "city", "state", "zip_code").UpdateColumns(a).Error
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// UserFollow means FollowerID follows FolloweeID
+type UserFollow struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	FollowerID uint      `gorm:"not null;uniqueIndex:idx_follow_pair;index" json:"follower_id"`
+	FolloweeID uint      `gorm:"not null;uniqueIndex:idx_follow_pair;index" json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (UserFollow) TableName() string {
+	return "user_follows"
+}
+
+// UserBlock means UserID blocked BlockedID. Neither can follow the other
+// while the block stands.
+type UserBlock struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_block_pair" json:"user_id"`
+	BlockedID uint      `gorm:"not null;uniqueIndex:idx_block_pair;index" json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
+
+// UserMute hides MutedID from UserID's feed without unfollowing
+type UserMute struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_mute_pair" json:"user_id"`
+	MutedID   uint      `gorm:"not null;uniqueIndex:idx_mute_pair" json:"muted_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (UserMute) TableName() string {
+	return "user_mutes"
+}
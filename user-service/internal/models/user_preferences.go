@@ -0,0 +1,38 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// UserPreferences are per-user settings. Users without a row get the
+// defaults from config.
+type UserPreferences struct {
+	ID           uint   `gorm:"primaryKey" json:"-"` // same as the user ID
+	Theme        Theme  `gorm:"type:varchar(10);not null" json:"theme"`
+	Language     string `gorm:"size:35;not null" json:"language"`
+	Timezone     string `gorm:"size:64;not null" json:"timezone"`
+	ItemsPerPage int    `gorm:"not null" json:"items_per_page"`
+
+	EmailNotifications EmailNotifications `gorm:"embedded;embeddedPrefix:email_" json:"email_notifications"`
+
+	// Notification types the user doesn't want, security types excluded
+	NotificationOptOuts []NotificationType `gorm:"serializer:json;type:text" json:"notification_opt_outs"`
+
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// EmailNotifications toggles which kinds of email a user receives
+type EmailNotifications struct {
+	SecurityAlerts  bool `gorm:"not null" json:"security_alerts"`
+	Recommendations bool `gorm:"not null" json:"recommendations"`
+	Newsletter      bool `gorm:"not null" json:"newsletter"`
+}
+
+func (UserPreferences) TableName() string {
+	return "user_preferences"
+}
+
+func (p *UserPreferences) OptedOut(notificationType NotificationType) bool {
+	return !notificationType.Security() && slices.Contains(p.NotificationOptOuts, notificationType)
+}
@@ -3,33 +3,60 @@ package models
 import (
 	"time"
 
+	"user-service/internal/pii"
+
+	"shared/locale"
+
 	"gorm.io/gorm"
 )
 
 type UserProfile struct {
-	ID          uint       `gorm:"primaryKey" json:"id"`
-	FirstName   *string    `gorm:"size:100" json:"first_name,omitempty"`
-	LastName    *string    `gorm:"size:100" json:"last_name,omitempty"`
-	Phone       *string    `gorm:"size:20" json:"phone,omitempty"`
-	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
-	Gender      *Gender    `gorm:"type:varchar(15);default:'not_specified'" json:"gender,omitempty"`
-	Bio         *string    `gorm:"type:text" json:"bio,omitempty"`
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	FirstName   *string      `gorm:"size:100" json:"first_name,omitempty"`
+	LastName    *string      `gorm:"size:100" json:"last_name,omitempty"`
+	Phone       *string      `gorm:"serializer:pii;type:text" json:"phone,omitempty"`
+	DateOfBirth *locale.Date `gorm:"serializer:pii;type:text" json:"date_of_birth,omitempty"`
+	Gender      *Gender      `gorm:"type:varchar(15);default:'not_specified'" json:"gender,omitempty"`
+	Bio         *string      `gorm:"type:text" json:"bio,omitempty"`
+
+	// Avatar URLs are set by the avatar upload endpoint only
+	AvatarURL        *string           `gorm:"size:500" json:"avatar_url,omitempty"`
+	AvatarThumbnails map[string]string `gorm:"serializer:json;type:text" json:"avatar_thumbnails,omitempty"` // size in px -> URL
+	AvatarKeys       []string          `gorm:"serializer:json;type:text" json:"-"`                           // blob keys, for cleanup
+
+	// Blind index of Phone for exact-match lookups, set on save
+	PhoneIndex *string `gorm:"size:64;index" json:"-"`
 
-	Address *Address `gorm:"embedded;embeddedPrefix:address_" json:"address,omitempty"`
+	Privacy ProfilePrivacy `gorm:"embedded;embeddedPrefix:privacy_" json:"privacy"`
 
 	CreatedAt *time.Time     `json:"created_at,omitempty"`
 	UpdatedAt *time.Time     `json:"updated_at,omitempty"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
-type Address struct {
-	Street  *string `gorm:"size:255" json:"street,omitempty"`
-	City    *string `gorm:"size:100" json:"city,omitempty"`
-	State   *string `gorm:"size:100" json:"state,omitempty"`
-	ZipCode *string `gorm:"size:20" json:"zip_code,omitempty"`
-	Country *string `gorm:"size:100" json:"country,omitempty"`
+// ProfilePrivacy controls which fields other users see in the public
+// profile. Name is always shown.
+type ProfilePrivacy struct {
+	Bio         Visibility `gorm:"type:varchar(10)" json:"bio"`
+	Location    Visibility `gorm:"type:varchar(10)" json:"location"` // default address city and country
+	Avatar      Visibility `gorm:"type:varchar(10)" json:"avatar"`
+	DateOfBirth Visibility `gorm:"type:varchar(10)" json:"date_of_birth"` // shown as an age range
+}
+
+// DefaultProfilePrivacy applies to settings a new profile leaves out
+var DefaultProfilePrivacy = ProfilePrivacy{
+	Bio:         VisibilityPublic,
+	Location:    VisibilityPublic,
+	Avatar:      VisibilityPublic,
+	DateOfBirth: VisibilityOnlyMe,
 }
 
 func (UserProfile) TableName() string {
 	return "user_profiles"
 }
+
+// BeforeSave keeps PhoneIndex in step with Phone
+func (p *UserProfile) BeforeSave(tx *gorm.DB) error {
+	p.PhoneIndex = pii.BlindIndexOf(p.Phone)
+	return nil
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// UserStatusChange records one admin status transition
+type UserStatusChange struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	FromStatus UserStatus `gorm:"type:varchar(20);not null" json:"from_status"`
+	ToStatus   UserStatus `gorm:"type:varchar(20);not null" json:"to_status"`
+	Reason     string     `gorm:"type:text;not null" json:"reason"`
+	ActorID    uint       `gorm:"not null" json:"actor_id"`
+	ActorEmail string     `gorm:"size:255;not null" json:"actor_email"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (UserStatusChange) TableName() string {
+	return "user_status_changes"
+}
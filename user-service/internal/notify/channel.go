@@ -0,0 +1,69 @@
+// Package notify delivers inbox notifications outside the app. Each
+// Channel decides from the user's preferences whether it wants a
+// notification, so new channels plug in without touching the inbox.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"user-service/internal/models"
+
+	"shared/mail"
+)
+
+// Recipient is who a notification is delivered to
+type Recipient struct {
+	UserID uint
+	Email  string
+}
+
+type Channel interface {
+	Name() string
+	// Accepts reports whether the user wants this notification delivered
+	// on the channel
+	Accepts(preferences *models.UserPreferences, notification *models.Notification) bool
+	Deliver(ctx context.Context, to Recipient, notification *models.Notification) error
+}
+
+// EmailChannel sends notifications through a mail.Sender, following the
+// user's email notification settings
+type EmailChannel struct {
+	sender mail.Sender
+}
+
+func NewEmailChannel(sender mail.Sender) *EmailChannel {
+	return &EmailChannel{sender: sender}
+}
+
+func (c *EmailChannel) Name() string {
+	return "email"
+}
+
+func (c *EmailChannel) Accepts(preferences *models.UserPreferences, notification *models.Notification) bool {
+	switch {
+	case notification.Type.Security():
+		return preferences.EmailNotifications.SecurityAlerts
+	case notification.Type == models.NotificationPriceDrop:
+		return preferences.EmailNotifications.Recommendations
+	default:
+		return true
+	}
+}
+
+func (c *EmailChannel) Deliver(ctx context.Context, to Recipient, notification *models.Notification) error {
+	if to.Email == "" {
+		return fmt.Errorf("user %d has no email address", to.UserID)
+	}
+
+	body := notification.Body
+	if notification.Link != "" {
+		body = strings.TrimRight(body, "\n") + "\n\n" + notification.Link
+	}
+	return c.sender.Send(ctx, mail.Message{
+		To:      to.Email,
+		Subject: notification.Title,
+		Body:    body,
+	})
+}
@@ -0,0 +1,255 @@
+// Package pii encrypts personal data at the application level. Every value
+// gets its own data key, which is stored next to the ciphertext wrapped by
+// a key from the keyring (envelope encryption). Rotating means adding a key
+// to the keyring file and making it active; values are moved to it in the
+// background by rewrapping their data keys.
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix marks an encrypted value, the format is
+// pii:v2:<key id>:<wrapped data key>:<ciphertext>, both base64. The
+// ciphertext is bound to where it is stored, see Encrypt.
+const prefix = "pii:v2:"
+
+// unboundPrefix marks values written before ciphertexts were bound to
+// their row. They still decrypt, and the migration at startup rewrites them.
+const unboundPrefix = "pii:v1:"
+
+const keySize = 32
+
+// KeyringFile is the JSON layout of the keyring file. Keys and the index key
+// are base64 encoded 32 byte AES keys.
+type KeyringFile struct {
+	ActiveKey string            `json:"active_key"`
+	Keys      map[string]string `json:"keys"`
+	IndexKey  string            `json:"index_key"`
+}
+
+type Keyring struct {
+	activeID string
+	keys     map[string][]byte
+	indexKey []byte
+}
+
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %v", err)
+	}
+
+	var file KeyringFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring: %v", err)
+	}
+	return NewKeyring(file)
+}
+
+func NewKeyring(file KeyringFile) (*Keyring, error) {
+	keyring := &Keyring{activeID: file.ActiveKey, keys: map[string][]byte{}}
+	for id, encoded := range file.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key id %q", id)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %v", id, err)
+		}
+		keyring.keys[id] = key
+	}
+	if _, ok := keyring.keys[file.ActiveKey]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", file.ActiveKey)
+	}
+
+	indexKey, err := decodeKey(file.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key: %v", err)
+	}
+	keyring.indexKey = indexKey
+	return keyring, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+// IsEncrypted tells ciphertext from values written before encryption
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix) || strings.HasPrefix(value, unboundPrefix)
+}
+
+// IsBound reports whether value is ciphertext bound to its row
+func IsBound(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// NeedsRewrite reports whether value is still plaintext or encrypted under
+// a key other than the active one
+func (k *Keyring) NeedsRewrite(value string) bool {
+	_, keyID, _, _, err := split(value)
+	return err != nil || keyID != k.activeID
+}
+
+// Encrypt seals plaintext under a fresh data key wrapped by the active key.
+// associatedData names where the value is stored, see AssociatedData, so
+// ciphertext copied to another row or column fails to decrypt.
+func (k *Keyring) Encrypt(plaintext, associatedData []byte) (string, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, plaintext, associatedData)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.activeID], dataKey, nil)
+	if err != nil {
+		return "", err
+	}
+	return join(prefix, k.activeID, wrapped, ciphertext), nil
+}
+
+// Decrypt opens value with the associatedData it was encrypted with.
+// Unbound values ignore associatedData.
+func (k *Keyring) Decrypt(value string, associatedData []byte) ([]byte, error) {
+	version, keyID, wrapped, ciphertext, err := split(value)
+	if err != nil {
+		return nil, err
+	}
+	if version == unboundPrefix {
+		associatedData = nil
+	}
+
+	dataKey, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	return open(dataKey, ciphertext, associatedData)
+}
+
+// Rewrap moves value to the active key without touching its ciphertext
+func (k *Keyring) Rewrap(value string) (string, error) {
+	version, keyID, wrapped, ciphertext, err := split(value)
+	if err != nil {
+		return "", err
+	}
+	if keyID == k.activeID {
+		return value, nil
+	}
+
+	dataKey, err := k.unwrap(keyID, wrapped)
+	if err != nil {
+		return "", err
+	}
+	rewrapped, err := seal(k.keys[k.activeID], dataKey, nil)
+	if err != nil {
+		return "", err
+	}
+	return join(version, k.activeID, rewrapped, ciphertext), nil
+}
+
+// BlindIndex is a keyed hash of value for exact-match lookups on encrypted
+// columns. Equal values give equal indexes, nothing else is revealed.
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (k *Keyring) unwrap(keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key %q is not in the keyring", keyID)
+	}
+	return open(key, wrapped, nil)
+}
+
+// AssociatedData names the table, column and row a value is stored in
+func AssociatedData(table, column string, id uint) []byte {
+	return []byte(fmt.Sprintf("%s.%s:%d", table, column, id))
+}
+
+func join(version, keyID string, wrapped, ciphertext []byte) string {
+	return version + keyID + ":" + base64.StdEncoding.EncodeToString(wrapped) + ":" + base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+// split returns the parts of value, version is its prefix
+func split(value string) (version, keyID string, wrapped, ciphertext []byte, err error) {
+	switch {
+	case strings.HasPrefix(value, prefix):
+		version = prefix
+	case strings.HasPrefix(value, unboundPrefix):
+		version = unboundPrefix
+	default:
+		return "", "", nil, nil, errors.New("value is not encrypted")
+	}
+	parts := strings.Split(strings.TrimPrefix(value, version), ":")
+	if len(parts) != 3 {
+		return "", "", nil, nil, errors.New("malformed encrypted value")
+	}
+
+	if wrapped, err = base64.StdEncoding.DecodeString(parts[1]); err != nil {
+		return "", "", nil, nil, errors.New("malformed encrypted value")
+	}
+	if ciphertext, err = base64.StdEncoding.DecodeString(parts[2]); err != nil {
+		return "", "", nil, nil, errors.New("malformed encrypted value")
+	}
+	return version, parts[0], wrapped, ciphertext, nil
+}
+
+// seal is AES-256-GCM with the nonce in front of the ciphertext
+func seal(key, plaintext, associatedData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func open(key, sealed, associatedData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], associatedData)
+	if err != nil {
+		return nil, errors.New("failed to decrypt value")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
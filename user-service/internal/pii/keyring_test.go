@@ -0,0 +1,237 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"shared/locale"
+)
+
+// testKeys are fixed keys, so a keyring can be rebuilt with fewer of them
+var testKeys = map[string]string{
+	"2025-01": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, keySize)),
+	"2026-01": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, keySize)),
+}
+
+func newTestKeyring(t *testing.T, active string, ids ...string) *Keyring {
+	t.Helper()
+	file := KeyringFile{
+		ActiveKey: active,
+		Keys:      map[string]string{},
+		IndexKey:  base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{9}, keySize)),
+	}
+	for _, id := range ids {
+		file.Keys[id] = testKeys[id]
+	}
+	keyring, err := NewKeyring(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	keyring := newTestKeyring(t, "2026-01", "2026-01")
+	row := AssociatedData("user_profiles", "phone", 7)
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+	}{
+		{name: "text", plaintext: []byte(`"+84901234567"`)},
+		{name: "unicode", plaintext: []byte(`"Phố Huế"`)},
+		{name: "empty", plaintext: []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := keyring.Encrypt(tt.plaintext, row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !IsEncrypted(value) || !IsBound(value) || keyring.NeedsRewrite(value) {
+				t.Errorf("%q is not a bound value under the active key", value)
+			}
+			if len(tt.plaintext) > 0 && strings.Contains(value, string(tt.plaintext)) {
+				t.Errorf("%q contains the plaintext", value)
+			}
+
+			got, err := keyring.Decrypt(value, row)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, tt.plaintext) {
+				t.Errorf("Decrypt = %q, want %q", got, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestKeyringEncryptIsRandomized(t *testing.T) {
+	keyring := newTestKeyring(t, "2026-01", "2026-01")
+	row := AssociatedData("user_profiles", "phone", 7)
+
+	first, _ := keyring.Encrypt([]byte("same"), row)
+	second, _ := keyring.Encrypt([]byte("same"), row)
+	if first == second {
+		t.Error("equal plaintexts gave equal ciphertexts")
+	}
+}
+
+func TestKeyringRewrap(t *testing.T) {
+	row := AssociatedData("user_addresses", "street", 3)
+	old := newTestKeyring(t, "2025-01", "2025-01")
+	value, err := old.Encrypt([]byte(`"1 Le Loi"`), row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := newTestKeyring(t, "2026-01", "2025-01", "2026-01")
+	if !rotated.NeedsRewrite(value) {
+		t.Fatal("a value under the retired key does not need a rewrite")
+	}
+	rewrapped, err := rotated.Rewrap(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rotated.NeedsRewrite(rewrapped) {
+		t.Error("the rewrapped value still needs a rewrite")
+	}
+	if again, _ := rotated.Rewrap(rewrapped); again != rewrapped {
+		t.Error("rewrapping under the active key changed the value")
+	}
+
+	// The ciphertext is untouched, only the data key moves
+	lastPart := func(v string) string { return v[strings.LastIndex(v, ":"):] }
+	if lastPart(rewrapped) != lastPart(value) {
+		t.Error("rewrap changed the ciphertext")
+	}
+
+	// Retire the old key for good
+	current := newTestKeyring(t, "2026-01", "2026-01")
+	got, err := current.Decrypt(rewrapped, row)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `"1 Le Loi"` {
+		t.Errorf("Decrypt = %q", got)
+	}
+	if _, err := current.Decrypt(value, row); err == nil {
+		t.Error("a value under a removed key decrypted")
+	}
+}
+
+func TestKeyringRejectsTampering(t *testing.T) {
+	keyring := newTestKeyring(t, "2026-01", "2026-01")
+	row := AssociatedData("user_profiles", "phone", 7)
+	value, err := keyring.Encrypt([]byte(`"+84901234567"`), row)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flip changes the last byte of one base64 part of value
+	flip := func(part int) string {
+		parts := strings.Split(strings.TrimPrefix(value, prefix), ":")
+		data, _ := base64.StdEncoding.DecodeString(parts[part])
+		data[len(data)-1] ^= 1
+		parts[part] = base64.StdEncoding.EncodeToString(data)
+		return prefix + strings.Join(parts, ":")
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		row   []byte
+	}{
+		{name: "ciphertext changed", value: flip(2), row: row},
+		{name: "wrapped key changed", value: flip(1), row: row},
+		{name: "copied to another row", value: value, row: AssociatedData("user_profiles", "phone", 8)},
+		{name: "copied to another column", value: value, row: AssociatedData("user_profiles", "date_of_birth", 7)},
+		{name: "copied to another table", value: value, row: AssociatedData("user_addresses", "phone", 7)},
+		{name: "unknown key", value: strings.Replace(value, "2026-01", "2024-01", 1), row: row},
+		{name: "missing part", value: value[:strings.LastIndex(value, ":")], row: row},
+		{name: "bad base64", value: value + "!", row: row},
+		{name: "plaintext", value: "+84901234567", row: row},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := keyring.Decrypt(tt.value, tt.row); err == nil {
+				t.Errorf("Decrypt = %q, want an error", got)
+			}
+		})
+	}
+}
+
+// Values written before ciphertexts were bound decrypt anywhere, until the
+// migration rewrites them
+func TestKeyringDecryptsUnboundValues(t *testing.T) {
+	keyring := newTestKeyring(t, "2026-01", "2026-01")
+	dataKey := bytes.Repeat([]byte{5}, keySize)
+	ciphertext, _ := seal(dataKey, []byte(`"Hanoi"`), nil)
+	wrapped, _ := seal(keyring.keys["2026-01"], dataKey, nil)
+	value := join(unboundPrefix, "2026-01", wrapped, ciphertext)
+
+	if !IsEncrypted(value) || IsBound(value) {
+		t.Fatalf("%q is not an unbound encrypted value", value)
+	}
+	got, err := keyring.Decrypt(value, AssociatedData("user_addresses", "city", 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != `"Hanoi"` {
+		t.Errorf("Decrypt = %q", got)
+	}
+}
+
+func TestLegacyValue(t *testing.T) {
+	dob := locale.NewDate(time.Date(1990, 5, 31, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name    string
+		raw     string
+		dst     any
+		want    any
+		wantErr bool
+	}{
+		{name: "string", raw: "1 Le Loi", dst: new(string), want: "1 Le Loi"},
+		{name: "optional string", raw: "+84901234567", dst: new(*string), want: ptr("+84901234567")},
+		{name: "quotes in text", raw: `say "hi"`, dst: new(string), want: `say "hi"`},
+		{name: "date column", raw: "1990-05-31", dst: new(*locale.Date), want: &dob},
+		{name: "datetime column", raw: "1990-05-31 00:00:00", dst: new(*locale.Date), want: &dob},
+		{name: "RFC 3339", raw: "1990-05-31T00:00:00Z", dst: new(*locale.Date), want: &dob},
+		{name: "unreadable date", raw: "31/05/1990", dst: new(*locale.Date), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fieldType := reflect.TypeOf(tt.dst).Elem()
+			data, err := decode(tt.raw, fieldType, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want an error, got %s", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := json.Unmarshal(data, tt.dst); err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(tt.dst).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,132 @@
+package pii
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"shared/locale"
+
+	"gorm.io/gorm/schema"
+)
+
+// The keyring used by the gorm serializer and BlindIndexOf, set once at
+// startup with Use
+var active *Keyring
+
+// Use makes keyring the one the "pii" gorm serializer encrypts with.
+// Models with serializer:pii fields can't be read or written before.
+func Use(keyring *Keyring) {
+	active = keyring
+	schema.RegisterSerializer("pii", Serializer{})
+}
+
+// BlindIndexOf indexes an optional value with the keyring passed to Use,
+// nil stays nil
+func BlindIndexOf(value *string) *string {
+	if value == nil || active == nil {
+		return nil
+	}
+	index := active.BlindIndex(*value)
+	return &index
+}
+
+// Serializer stores a field as an encrypted JSON document. nil pointers are
+// stored as NULL. Values written before encryption are still read, see
+// legacyValue, until the migration has encrypted them.
+//
+// Ciphertexts are bound to the row's primary key, which must be scanned
+// before the field and set before the row is written. An insert that gets
+// its ID from the database has to write the field again afterwards, see
+// models.UserAddress.
+type Serializer struct{}
+
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	fieldValue := reflect.New(field.FieldType)
+	if dbValue != nil {
+		var raw string
+		switch v := dbValue.(type) {
+		case []byte:
+			raw = string(v)
+		case string:
+			raw = v
+		case time.Time:
+			raw = v.Format(time.RFC3339Nano)
+		default:
+			return fmt.Errorf("cannot scan %T into encrypted field %s", dbValue, field.Name)
+		}
+
+		data, err := decode(raw, field.FieldType, associatedData(ctx, field, dst))
+		if err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+		if err := json.Unmarshal(data, fieldValue.Interface()); err != nil {
+			return fmt.Errorf("field %s: %v", field.Name, err)
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	if v := reflect.ValueOf(fieldValue); !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return nil, nil
+	}
+	if active == nil {
+		return nil, errors.New("no keyring for encrypted fields, call pii.Use first")
+	}
+
+	data, err := json.Marshal(fieldValue)
+	if err != nil {
+		return nil, err
+	}
+	return active.Encrypt(data, associatedData(ctx, field, dst))
+}
+
+// associatedData binds a value to the table, column and primary key of
+// the row in dst
+func associatedData(ctx context.Context, field *schema.Field, dst reflect.Value) []byte {
+	var id uint
+	if primaryKey := field.Schema.PrioritizedPrimaryField; primaryKey != nil {
+		value, _ := primaryKey.ValueOf(ctx, dst)
+		id, _ = value.(uint)
+	}
+	return AssociatedData(field.Schema.Table, field.DBName, id)
+}
+
+func decode(raw string, fieldType reflect.Type, associatedData []byte) ([]byte, error) {
+	if !IsEncrypted(raw) {
+		return legacyValue(raw, fieldType)
+	}
+	if active == nil {
+		return nil, errors.New("no keyring for encrypted fields, call pii.Use first")
+	}
+	return active.Decrypt(raw, associatedData)
+}
+
+// legacyValue turns a plaintext column value into the JSON the field would
+// have been stored as
+func legacyValue(raw string, fieldType reflect.Type) ([]byte, error) {
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+
+	// A Date reads the JSON of a time.Time too
+	if fieldType == reflect.TypeOf(time.Time{}) || fieldType == reflect.TypeOf(locale.Date{}) {
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", time.DateOnly} {
+			if t, err := time.Parse(layout, strings.TrimSpace(raw)); err == nil {
+				return json.Marshal(t)
+			}
+		}
+		return nil, fmt.Errorf("unreadable plaintext time %q", raw)
+	}
+	if fieldType.Kind() == reflect.String {
+		return json.Marshal(raw)
+	}
+	return []byte(raw), nil
+}
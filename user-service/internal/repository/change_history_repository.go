@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"errors"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ChangeHistoryRepository interface {
+	Create(entry *models.ChangeEntry) error
+	ListByUserID(userID, beforeID uint, limit int) ([]models.ChangeEntry, error)
+}
+
+type changeHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewChangeHistoryRepository(db *gorm.DB) ChangeHistoryRepository {
+	return &changeHistoryRepository{db: db}
+}
+
+// createAttempts bounds how often Create retries after losing a race for
+// the next version
+const createAttempts = 5
+
+// Create stores the entry as the next version of its entity. Concurrent
+// changes to one entity can read the same latest version, the unique
+// (entity, version) index rejects all but one and the others retry.
+func (r *changeHistoryRepository) Create(entry *models.ChangeEntry) error {
+	var err error
+	for attempt := 0; attempt < createAttempts; attempt++ {
+		entry.ID = 0
+		err = r.db.Transaction(func(tx *gorm.DB) error {
+			var latest int
+			err := tx.Model(&models.ChangeEntry{}).
+				Where("entity_type = ? AND entity_id = ?", entry.EntityType, entry.EntityID).
+				Select("COALESCE(MAX(version), 0)").
+				Scan(&latest).Error
+			if err != nil {
+				return err
+			}
+
+			entry.Version = latest + 1
+			return tx.Create(entry).Error
+		})
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+	}
+	return err
+}
+
+// ListByUserID returns the newest entries first, starting below beforeID
+// when it is set
+func (r *changeHistoryRepository) ListByUserID(userID, beforeID uint, limit int) ([]models.ChangeEntry, error) {
+	var entries []models.ChangeEntry
+	query := r.db.Where("user_id = ?", userID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"time"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DataExportRepository interface {
+	Create(export *models.DataExport) error
+	GetByID(id string) (*models.DataExport, error)
+	GetLatestByUserID(userID uint) (*models.DataExport, error)
+	Update(export *models.DataExport) error
+	ListUnfinished() ([]models.DataExport, error)
+	ListExpired(now time.Time) ([]models.DataExport, error)
+}
+
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+func NewDataExportRepository(db *gorm.DB) DataExportRepository {
+	return &dataExportRepository{db: db}
+}
+
+func (r *dataExportRepository) Create(export *models.DataExport) error {
+	return r.db.Create(export).Error
+}
+
+func (r *dataExportRepository) GetByID(id string) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.Where("id = ?", id).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) GetLatestByUserID(userID uint) (*models.DataExport, error) {
+	var export models.DataExport
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *dataExportRepository) Update(export *models.DataExport) error {
+	return r.db.Save(export).Error
+}
+
+// ListUnfinished returns pending and running exports, oldest first
+func (r *dataExportRepository) ListUnfinished() ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Where("status IN ?", []models.ExportStatus{models.ExportStatusPending, models.ExportStatusRunning}).
+		Order("created_at").
+		Find(&exports).Error
+	return exports, err
+}
+
+// ListExpired returns completed exports whose ZIP is past its retention
+func (r *dataExportRepository) ListExpired(now time.Time) ([]models.DataExport, error) {
+	var exports []models.DataExport
+	err := r.db.Where("status = ? AND expires_at < ?", models.ExportStatusCompleted, now).
+		Find(&exports).Error
+	return exports, err
+}
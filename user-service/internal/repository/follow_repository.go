@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FollowRepository interface {
+	Follow(followerID, followeeID uint) error
+	Unfollow(followerID, followeeID uint) error
+	ListFollowers(userID, beforeID uint, limit int) ([]RelatedUserRow, error)
+	ListFollowing(userID, beforeID uint, limit int) ([]RelatedUserRow, error)
+	// FollowingIDs returns everyone userID follows except muted users
+	FollowingIDs(userID uint) ([]uint, error)
+
+	Block(userID, blockedID uint) error
+	Unblock(userID, blockedID uint) error
+	ListBlocked(userID, beforeID uint, limit int) ([]RelatedUserRow, error)
+	Mute(userID, mutedID uint) error
+	Unmute(userID, mutedID uint) error
+	ListMuted(userID, beforeID uint, limit int) ([]RelatedUserRow, error)
+}
+
+// RelatedUserRow is a user in a follow, block or mute list. ID is the row
+// in that list, for paging.
+type RelatedUserRow struct {
+	ID        uint
+	UserID    uint
+	FirstName *string
+	LastName  *string
+	CreatedAt time.Time
+}
+
+// ErrBlocked means one of the two users blocked the other
+var ErrBlocked = errors.New("user is blocked")
+
+type followRepository struct {
+	db *gorm.DB
+}
+
+func NewFollowRepository(db *gorm.DB) FollowRepository {
+	return &followRepository{db: db}
+}
+
+// Follow is a no-op when the follow already exists
+func (r *followRepository) Follow(followerID, followeeID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var blocks int64
+		err := tx.Model(&models.UserBlock{}).
+			Where("(user_id = ? AND blocked_id = ?) OR (user_id = ? AND blocked_id = ?)", followerID, followeeID, followeeID, followerID).
+			Count(&blocks).Error
+		if err != nil {
+			return err
+		}
+		if blocks > 0 {
+			return ErrBlocked
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserFollow{FollowerID: followerID, FolloweeID: followeeID})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		return adjustFollowCounts(tx, followerID, followeeID, 1)
+	})
+}
+
+// Unfollow is a no-op when there is nothing to undo
+func (r *followRepository) Unfollow(followerID, followeeID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteFollow(tx, followerID, followeeID)
+	})
+}
+
+func (r *followRepository) ListFollowers(userID, beforeID uint, limit int) ([]RelatedUserRow, error) {
+	return listRelated(r.db, "user_follows", "follower_id", "followee_id", userID, beforeID, limit)
+}
+
+func (r *followRepository) ListFollowing(userID, beforeID uint, limit int) ([]RelatedUserRow, error) {
+	return listRelated(r.db, "user_follows", "followee_id", "follower_id", userID, beforeID, limit)
+}
+
+func (r *followRepository) FollowingIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.UserFollow{}).
+		Where("follower_id = ?", userID).
+		Where("followee_id NOT IN (?)", r.db.Model(&models.UserMute{}).Select("muted_id").Where("user_id = ?", userID)).
+		Pluck("followee_id", &ids).Error
+	return ids, err
+}
+
+// Block also removes any follow between the two users, in both directions
+func (r *followRepository) Block(userID, blockedID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&models.UserBlock{UserID: userID, BlockedID: blockedID}).Error
+		if err != nil {
+			return err
+		}
+		if err := deleteFollow(tx, userID, blockedID); err != nil {
+			return err
+		}
+		return deleteFollow(tx, blockedID, userID)
+	})
+}
+
+func (r *followRepository) Unblock(userID, blockedID uint) error {
+	return r.db.Where("user_id = ? AND blocked_id = ?", userID, blockedID).Delete(&models.UserBlock{}).Error
+}
+
+func (r *followRepository) ListBlocked(userID, beforeID uint, limit int) ([]RelatedUserRow, error) {
+	return listRelated(r.db, "user_blocks", "blocked_id", "user_id", userID, beforeID, limit)
+}
+
+func (r *followRepository) Mute(userID, mutedID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.UserMute{UserID: userID, MutedID: mutedID}).Error
+}
+
+func (r *followRepository) Unmute(userID, mutedID uint) error {
+	return r.db.Where("user_id = ? AND muted_id = ?", userID, mutedID).Delete(&models.UserMute{}).Error
+}
+
+func (r *followRepository) ListMuted(userID, beforeID uint, limit int) ([]RelatedUserRow, error) {
+	return listRelated(r.db, "user_mutes", "muted_id", "user_id", userID, beforeID, limit)
+}
+
+func deleteFollow(tx *gorm.DB, followerID, followeeID uint) error {
+	result := tx.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.UserFollow{})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return result.Error
+	}
+	return adjustFollowCounts(tx, followerID, followeeID, -1)
+}
+
+func adjustFollowCounts(tx *gorm.DB, followerID, followeeID uint, delta int) error {
+	err := tx.Model(&models.User{}).Where("id = ?", followerID).
+		UpdateColumn("following_count", gorm.Expr("following_count + ?", delta)).Error
+	if err != nil {
+		return err
+	}
+	return tx.Model(&models.User{}).Where("id = ?", followeeID).
+		UpdateColumn("followers_count", gorm.Expr("followers_count + ?", delta)).Error
+}
+
+// listRelated lists the users in otherColumn of table whose ownerColumn is
+// userID, newest first, with their profile names
+func listRelated(db *gorm.DB, table, otherColumn, ownerColumn string, userID, beforeID uint, limit int) ([]RelatedUserRow, error) {
+	var rows []RelatedUserRow
+	query := db.Table(table+" AS r").
+		Select("r.id, r."+otherColumn+" AS user_id, p.first_name, p.last_name, r.created_at").
+		Joins("JOIN users u ON u.id = r."+otherColumn+" AND u.deleted_at IS NULL").
+		Joins("LEFT JOIN user_profiles p ON p.id = u.id AND p.deleted_at IS NULL").
+		Where("r."+ownerColumn+" = ?", userID)
+	if beforeID > 0 {
+		query = query.Where("r.id < ?", beforeID)
+	}
+	err := query.Order("r.id DESC").Limit(limit).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository interface {
+	Create(notification *models.Notification) error
+	List(userID uint, unreadOnly bool, beforeID uint, limit int) ([]models.Notification, error)
+	ListAll(userID uint) ([]models.Notification, error)
+	CountUnread(userID uint) (int64, error)
+	MarkRead(userID, id uint) error
+	MarkAllRead(userID uint) error
+	DeleteOlderThan(cutoff time.Time, limit int) (int64, error)
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Create(notification *models.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+// List returns the newest notifications first, starting below beforeID
+// when it is set
+func (r *notificationRepository) List(userID uint, unreadOnly bool, beforeID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	query := r.db.Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	err := query.Order("id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+// ListAll returns every notification of the user, oldest first
+func (r *notificationRepository) ListAll(userID uint) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Notification{}).Where("user_id = ? AND read_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+// MarkRead returns gorm.ErrRecordNotFound when the user has no such
+// notification. Marking a read notification again keeps its ReadAt.
+func (r *notificationRepository) MarkRead(userID, id uint) error {
+	var notification models.Notification
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&notification).Error; err != nil {
+		return err
+	}
+	if notification.ReadAt != nil {
+		return nil
+	}
+	return r.db.Model(&notification).Update("read_at", time.Now()).Error
+}
+
+func (r *notificationRepository) MarkAllRead(userID uint) error {
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now()).Error
+}
+
+// DeleteOlderThan removes up to limit notifications created before cutoff
+func (r *notificationRepository) DeleteOlderThan(cutoff time.Time, limit int) (int64, error) {
+	result := r.db.Where("created_at < ?", cutoff).Limit(limit).Delete(&models.Notification{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"errors"
+
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UserAddressRepository interface {
+	ListByUserID(userID uint) ([]models.UserAddress, error)
+	GetByID(userID, id uint) (*models.UserAddress, error)
+	GetDefault(userID uint) (*models.UserAddress, error)
+	CountByUserID(userID uint) (int64, error)
+	Create(address *models.UserAddress) error
+	Update(address *models.UserAddress) error
+	Delete(address *models.UserAddress) error
+}
+
+type userAddressRepository struct {
+	db *gorm.DB
+}
+
+func NewUserAddressRepository(db *gorm.DB) UserAddressRepository {
+	return &userAddressRepository{db: db}
+}
+
+// ListByUserID returns the default address first, then oldest first
+func (r *userAddressRepository) ListByUserID(userID uint) ([]models.UserAddress, error) {
+	var addresses []models.UserAddress
+	err := r.db.Where("user_id = ?", userID).
+		Order("is_default DESC").Order("id").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+func (r *userAddressRepository) GetByID(userID, id uint) (*models.UserAddress, error) {
+	var address models.UserAddress
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *userAddressRepository) GetDefault(userID uint) (*models.UserAddress, error) {
+	var address models.UserAddress
+	if err := r.db.Where("user_id = ? AND is_default = ?", userID, true).First(&address).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+func (r *userAddressRepository) CountByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserAddress{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// Create adds the address. The user's first address always becomes the
+// default, and a new default replaces the old one.
+func (r *userAddressRepository) Create(address *models.UserAddress) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.UserAddress{}).Where("user_id = ?", address.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault {
+			if err := clearDefault(tx, address.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+}
+
+// Update saves the address. Making it the default clears the old default;
+// the default itself cannot be unset this way, another address has to
+// become the default instead.
+func (r *userAddressRepository) Update(address *models.UserAddress) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var current models.UserAddress
+		if err := tx.Where("id = ? AND user_id = ?", address.ID, address.UserID).First(&current).Error; err != nil {
+			return err
+		}
+		if current.IsDefault {
+			address.IsDefault = true
+		}
+
+		if address.IsDefault && !current.IsDefault {
+			if err := clearDefault(tx, address.UserID); err != nil {
+				return err
+			}
+		}
+		return tx.Save(address).Error
+	})
+}
+
+// Delete removes the address. When it was the default, the user's oldest
+// remaining address takes over.
+func (r *userAddressRepository) Delete(address *models.UserAddress) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", address.ID, address.UserID).Delete(&models.UserAddress{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next models.UserAddress
+		err := tx.Where("user_id = ?", address.UserID).Order("id").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+func clearDefault(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.UserAddress{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
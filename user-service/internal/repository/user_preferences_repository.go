@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"user-service/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type UserPreferencesRepository interface {
+	GetByUserID(userID uint) (*models.UserPreferences, error)
+	Save(preferences *models.UserPreferences) error
+}
+
+type userPreferencesRepository struct {
+	db *gorm.DB
+}
+
+func NewUserPreferencesRepository(db *gorm.DB) UserPreferencesRepository {
+	return &userPreferencesRepository{db: db}
+}
+
+func (r *userPreferencesRepository) GetByUserID(userID uint) (*models.UserPreferences, error) {
+	var preferences models.UserPreferences
+	if err := r.db.First(&preferences, userID).Error; err != nil {
+		return nil, err
+	}
+	return &preferences, nil
+}
+
+// Save inserts or updates the row for preferences.ID
+func (r *userPreferencesRepository) Save(preferences *models.UserPreferences) error {
+	return r.db.Save(preferences).Error
+}
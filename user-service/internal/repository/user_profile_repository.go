@@ -9,6 +9,8 @@ import (
 type UserProfileRepository interface {
 	Create(profile *models.UserProfile) error
 	GetByUserID(userID uint) (*models.UserProfile, error)
+	Update(profile *models.UserProfile) error
+	Delete(userID uint) error
 }
 
 type userProfileRepository struct {
@@ -25,6 +27,26 @@ func (r *userProfileRepository) Create(profile *models.UserProfile) error {
 
 func (r *userProfileRepository) GetByUserID(userID uint) (*models.UserProfile, error) {
 	var profile models.UserProfile
-	err := r.db.First(&profile, userID).Error
-	return &profile, err
+	if err := r.db.First(&profile, userID).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+// Update writes every column, so nil fields are cleared
+func (r *userProfileRepository) Update(profile *models.UserProfile) error {
+	return r.db.Save(profile).Error
+}
+
+// Delete removes the row for good. The profile ID is the user ID, a soft
+// deleted row would stop the user from ever creating a new profile.
+func (r *userProfileRepository) Delete(userID uint) error {
+	result := r.db.Unscoped().Delete(&models.UserProfile{}, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
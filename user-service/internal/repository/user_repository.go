@@ -1,17 +1,31 @@
 package repository
 
 import (
+	"errors"
+	"strings"
+
+	"user-service/internal/dto"
 	"user-service/internal/models"
+	"user-service/internal/pii"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
 	Create(user *models.User) error
+	CreateBatch(users []models.User) error
 	GetByEmail(email string) (*models.User, error)
+	GetByEmails(emails []string) ([]models.User, error)
 	GetByID(id uint) (*models.User, error)
+	GetByIDs(ids []uint) ([]models.User, error)
+	ChangeStatus(change *models.UserStatusChange) error
+	GetStatusHistory(userID uint) ([]models.UserStatusChange, error)
+	ListUsers(req dto.ListUsersReq, statuses []models.UserStatus, after *dto.UserCursor) ([]dto.UserSummary, error)
 }
 
+// ErrStatusChanged means the user's status moved between reading and updating it
+var ErrStatusChanged = errors.New("user status changed concurrently")
+
 type userRepository struct {
 	db *gorm.DB
 }
@@ -24,6 +38,19 @@ func (r *userRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
+// CreateBatch inserts users in one statement, all or none
+func (r *userRepository) CreateBatch(users []models.User) error {
+	return r.db.Create(&users).Error
+}
+
+func (r *userRepository) GetByEmails(emails []string) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.Where("email IN ?", emails).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	var user models.User
 	if err := r.db.Where("email = ?", email).First(&user).Error; err != nil {
@@ -39,3 +66,92 @@ func (r *userRepository) GetByID(id uint) (*models.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *userRepository) GetByIDs(ids []uint) ([]models.User, error) {
+	var users []models.User
+	if err := r.db.Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ChangeStatus moves the user from change.FromStatus to change.ToStatus and
+// records the change, both or neither
+func (r *userRepository) ChangeStatus(change *models.UserStatusChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND status = ?", change.UserID, change.FromStatus).
+			Update("status", change.ToStatus)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrStatusChanged
+		}
+
+		return tx.Create(change).Error
+	})
+}
+
+// GetStatusHistory returns the user's status changes, newest first
+func (r *userRepository) GetStatusHistory(userID uint) ([]models.UserStatusChange, error) {
+	var changes []models.UserStatusChange
+	err := r.db.Where("user_id = ?", userID).Order("created_at DESC, id DESC").Find(&changes).Error
+	return changes, err
+}
+
+// ListUsers returns up to req.Limit users after the cursor, ordered by
+// req.Sort and then ID so pages never overlap
+func (r *userRepository) ListUsers(req dto.ListUsersReq, statuses []models.UserStatus, after *dto.UserCursor) ([]dto.UserSummary, error) {
+	var users []dto.UserSummary
+
+	query := r.db.Model(&models.User{}).
+		Select("users.id, users.email, users.status, user_profiles.first_name, user_profiles.last_name, users.created_at").
+		Joins("LEFT JOIN user_profiles ON user_profiles.id = users.id AND user_profiles.deleted_at IS NULL")
+
+	// Filters
+	if req.Query != "" {
+		like := "%" + escapeLike(req.Query) + "%"
+		query = query.Where("users.email LIKE ? OR user_profiles.first_name LIKE ? OR user_profiles.last_name LIKE ? OR CONCAT_WS(' ', user_profiles.first_name, user_profiles.last_name) LIKE ?",
+			like, like, like, like)
+	}
+	if req.Phone != "" {
+		// Phones are encrypted, so match on the blind index
+		phone := "+" + strings.TrimPrefix(strings.TrimSpace(req.Phone), "+")
+		query = query.Where("user_profiles.phone_index = ?", pii.BlindIndexOf(&phone))
+	}
+	if len(statuses) > 0 {
+		query = query.Where("users.status IN ?", statuses)
+	}
+	if !req.CreatedFrom.IsZero() {
+		query = query.Where("users.created_at >= ?", req.CreatedFrom)
+	}
+	if !req.CreatedTo.IsZero() {
+		query = query.Where("users.created_at < ?", req.CreatedTo)
+	}
+
+	// Keyset pagination on (sort column, id)
+	column := "users." + req.Sort
+	comparison := ">"
+	if req.Order == "desc" {
+		comparison = "<"
+	}
+	if after != nil {
+		var value any = after.CreatedAt
+		if req.Sort == "email" {
+			value = after.Email
+		}
+		query = query.Where(column+" "+comparison+" ? OR ("+column+" = ? AND users.id "+comparison+" ?)",
+			value, value, after.ID)
+	}
+
+	err := query.Order(column + " " + req.Order).Order("users.id " + req.Order).
+		Limit(req.Limit).
+		Find(&users).Error
+	return users, err
+}
+
+// escapeLike makes LIKE treat % and _ in user input literally
+func escapeLike(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(s)
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"user-service/internal/dto"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"shared/utils"
+
+	"gorm.io/gorm"
+)
+
+type AddressService interface {
+	ListAddresses(userID uint) ([]models.UserAddress, error)
+	GetAddress(userID, addressID uint) (*models.UserAddress, error)
+	CreateAddress(ctx context.Context, userID uint, req dto.AddressReq) (*models.UserAddress, error)
+	UpdateAddress(ctx context.Context, userID, addressID uint, req dto.AddressReq) (*models.UserAddress, error)
+	DeleteAddress(ctx context.Context, userID, addressID uint) error
+}
+
+type addressService struct {
+	addressRepo repository.UserAddressRepository
+	history     HistoryService
+}
+
+func NewAddressService(addressRepo repository.UserAddressRepository, history HistoryService) AddressService {
+	return &addressService{addressRepo: addressRepo, history: history}
+}
+
+func (s *addressService) ListAddresses(userID uint) ([]models.UserAddress, error) {
+	addresses, err := s.addressRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get addresses")
+	}
+	if addresses == nil {
+		addresses = []models.UserAddress{}
+	}
+	return addresses, nil
+}
+
+func (s *addressService) GetAddress(userID, addressID uint) (*models.UserAddress, error) {
+	address, err := s.addressRepo.GetByID(userID, addressID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NotFound("Address not found")
+		}
+		return nil, utils.InternalServerError("Failed to get address")
+	}
+	return address, nil
+}
+
+func (s *addressService) CreateAddress(ctx context.Context, userID uint, req dto.AddressReq) (*models.UserAddress, error) {
+	count, err := s.addressRepo.CountByUserID(userID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to create address")
+	}
+	if count >= models.MaxAddressesPerUser {
+		return nil, utils.NewLocalizedError(http.StatusConflict, "address.limit_reached", map[string]string{
+			"max": strconv.Itoa(models.MaxAddressesPerUser),
+		})
+	}
+
+	address := addressFromReq(userID, req)
+	if err := s.addressRepo.Create(address); err != nil {
+		return nil, utils.InternalServerError("Failed to create address")
+	}
+	s.history.Record(ctx, userID, models.EntityUserAddress, address.ID, nil, address)
+	return address, nil
+}
+
+// UpdateAddress replaces the address. The default address stays the
+// default, make another address the default to change it.
+func (s *addressService) UpdateAddress(ctx context.Context, userID, addressID uint, req dto.AddressReq) (*models.UserAddress, error) {
+	existing, err := s.GetAddress(userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	address := addressFromReq(userID, req)
+	address.ID = existing.ID
+	address.CreatedAt = existing.CreatedAt
+	if err := s.addressRepo.Update(address); err != nil {
+		return nil, utils.InternalServerError("Failed to update address")
+	}
+	s.history.Record(ctx, userID, models.EntityUserAddress, address.ID, existing, address)
+	return address, nil
+}
+
+// DeleteAddress removes the address, the oldest remaining address becomes
+// the default when the default is deleted
+func (s *addressService) DeleteAddress(ctx context.Context, userID, addressID uint) error {
+	address, err := s.GetAddress(userID, addressID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addressRepo.Delete(address); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NotFound("Address not found")
+		}
+		return utils.InternalServerError("Failed to delete address")
+	}
+	s.history.Record(ctx, userID, models.EntityUserAddress, address.ID, address, nil)
+	return nil
+}
+
+func addressFromReq(userID uint, req dto.AddressReq) *models.UserAddress {
+	return &models.UserAddress{
+		UserID:    userID,
+		Label:     strings.TrimSpace(req.Label),
+		Street:    strings.TrimSpace(req.Street),
+		City:      strings.TrimSpace(req.City),
+		State:     trimmed(&req.State),
+		ZipCode:   trimmed(&req.ZipCode),
+		Country:   req.Country,
+		IsDefault: req.IsDefault,
+	}
+}
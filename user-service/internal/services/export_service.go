@@ -0,0 +1,418 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"slices"
+	"time"
+
+	"user-service/internal/dto"
+	"user-service/internal/export"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+	"user-service/internal/storage"
+
+	"shared/proto/data_export"
+	"shared/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	exportSourceTimeout  = 30 * time.Second
+	exportSourceAttempts = 3
+	exportSweepInterval  = time.Minute
+)
+
+type ExportService interface {
+	RequestExport(userID uint) (*dto.DataExportRes, error)
+	GetExport(userID uint, exportID string) (*dto.DataExportRes, error)
+	OpenDownload(ctx context.Context, exportID string, expires int64, signature string) (io.ReadCloser, error)
+	// Run processes exports until ctx is done. Exports left unfinished by a
+	// restart are picked up again, so only one instance should run it.
+	Run(ctx context.Context)
+}
+
+type ExportOptions struct {
+	LinkTTL     time.Duration // how long a download link works
+	Retention   time.Duration // how long a finished ZIP is kept
+	DownloadURL string        // base URL download links point to
+}
+
+type exportService struct {
+	exportRepo repository.DataExportRepository
+	userRepo   repository.UserRepository
+	blobStore  storage.BlobStore
+	signer     *export.Signer
+	sources    []export.Source
+	options    ExportOptions
+	wake       chan struct{}
+}
+
+func NewExportService(exportRepo repository.DataExportRepository, userRepo repository.UserRepository, blobStore storage.BlobStore, signer *export.Signer, sources []export.Source, options ExportOptions) ExportService {
+	return &exportService{
+		exportRepo: exportRepo,
+		userRepo:   userRepo,
+		blobStore:  blobStore,
+		signer:     signer,
+		sources:    sources,
+		options:    options,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// RequestExport starts an export for the user. A request while one is in
+// progress returns that one, and a failed export is resumed rather than
+// started over.
+func (s *exportService) RequestExport(userID uint) (*dto.DataExportRes, error) {
+	latest, err := s.exportRepo.GetLatestByUserID(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, utils.InternalServerError("Failed to request data export")
+	}
+
+	switch {
+	case latest != nil && latest.Status.Unfinished():
+		return s.toRes(latest), nil
+	case latest != nil && latest.Status == models.ExportStatusFailed:
+		latest.Status = models.ExportStatusPending
+		latest.Error = ""
+		if err := s.exportRepo.Update(latest); err != nil {
+			return nil, utils.InternalServerError("Failed to request data export")
+		}
+		s.notify()
+		return s.toRes(latest), nil
+	}
+
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to request data export")
+	}
+	job := &models.DataExport{
+		ID:     id,
+		UserID: userID,
+		Status: models.ExportStatusPending,
+	}
+	if err := s.exportRepo.Create(job); err != nil {
+		return nil, utils.InternalServerError("Failed to request data export")
+	}
+
+	s.notify()
+	return s.toRes(job), nil
+}
+
+func (s *exportService) GetExport(userID uint, exportID string) (*dto.DataExportRes, error) {
+	job, err := s.exportRepo.GetByID(exportID)
+	if err != nil || job.UserID != userID {
+		if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NotFound("Data export not found")
+		}
+		return nil, utils.InternalServerError("Failed to get data export")
+	}
+	return s.toRes(job), nil
+}
+
+// OpenDownload checks a signed link and opens the export's ZIP
+func (s *exportService) OpenDownload(ctx context.Context, exportID string, expires int64, signature string) (io.ReadCloser, error) {
+	if !s.signer.Verify(exportID, expires, signature, time.Now()) {
+		return nil, utils.Forbidden("Download link is invalid or has expired")
+	}
+
+	job, err := s.exportRepo.GetByID(exportID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NotFound("Data export not found")
+		}
+		return nil, utils.InternalServerError("Failed to get data export")
+	}
+	if job.Status != models.ExportStatusCompleted {
+		return nil, utils.NotFound("Data export is no longer available")
+	}
+
+	file, err := s.blobStore.Get(ctx, job.BlobKey)
+	if err != nil {
+		log.Printf("Failed to open data export %s: %v", job.ID, err)
+		return nil, utils.InternalServerError("Failed to open data export")
+	}
+	return file, nil
+}
+
+func (s *exportService) toRes(job *models.DataExport) *dto.DataExportRes {
+	res := &dto.DataExportRes{
+		ID:          job.ID,
+		Status:      job.Status,
+		Error:       job.Error,
+		CreatedAt:   job.CreatedAt,
+		CompletedAt: job.CompletedAt,
+		ExpiresAt:   job.ExpiresAt,
+	}
+
+	if job.Status == models.ExportStatusCompleted {
+		expires := time.Now().Add(s.options.LinkTTL).Truncate(time.Second)
+		if job.ExpiresAt != nil && job.ExpiresAt.Before(expires) {
+			expires = *job.ExpiresAt
+		}
+		res.DownloadURL = fmt.Sprintf("%s/api/v1/exports/%s/download?expires=%d&signature=%s",
+			s.options.DownloadURL, job.ID, expires.Unix(), s.signer.Sign(job.ID, expires))
+		res.DownloadExpiresAt = &expires
+	}
+	return res
+}
+
+func (s *exportService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *exportService) Run(ctx context.Context) {
+	ticker := time.NewTicker(exportSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.processUnfinished(ctx)
+		s.deleteExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *exportService) processUnfinished(ctx context.Context) {
+	jobs, err := s.exportRepo.ListUnfinished()
+	if err != nil {
+		log.Printf("Failed to list data exports: %v", err)
+		return
+	}
+
+	for i := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.process(ctx, &jobs[i]); err != nil {
+			log.Printf("Data export %s failed: %v", jobs[i].ID, err)
+			jobs[i].Status = models.ExportStatusFailed
+			jobs[i].Error = "Export failed, request it again to resume"
+			if err := s.exportRepo.Update(&jobs[i]); err != nil {
+				log.Printf("Failed to save data export %s: %v", jobs[i].ID, err)
+			}
+		}
+	}
+}
+
+// process collects every source not yet collected, keeping each part in
+// the blob store as it arrives, then packages the parts as a ZIP
+func (s *exportService) process(ctx context.Context, job *models.DataExport) error {
+	user, err := s.userRepo.GetByID(job.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %v", err)
+	}
+
+	job.Status = models.ExportStatusRunning
+	if err := s.exportRepo.Update(job); err != nil {
+		return fmt.Errorf("failed to save progress: %v", err)
+	}
+
+	for _, source := range s.sources {
+		if slices.Contains(job.CompletedParts, source.Name()) {
+			continue
+		}
+
+		files, err := s.collect(ctx, source, user)
+		if err != nil {
+			return fmt.Errorf("%s: %v", source.Name(), err)
+		}
+		data, err := json.Marshal(files)
+		if err != nil {
+			return fmt.Errorf("%s: %v", source.Name(), err)
+		}
+		if err := s.blobStore.Put(ctx, partKey(job, source.Name()), bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+			return fmt.Errorf("%s: %v", source.Name(), err)
+		}
+
+		job.CompletedParts = append(job.CompletedParts, source.Name())
+		if err := s.exportRepo.Update(job); err != nil {
+			return fmt.Errorf("failed to save progress: %v", err)
+		}
+	}
+
+	archive, err := s.buildArchive(ctx, job)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("exports/%d/%s.zip", job.UserID, job.ID)
+	if err := s.blobStore.Put(ctx, key, bytes.NewReader(archive), int64(len(archive)), "application/zip"); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(s.options.Retention)
+	job.Status = models.ExportStatusCompleted
+	job.BlobKey = key
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+	if err := s.exportRepo.Update(job); err != nil {
+		return fmt.Errorf("failed to save progress: %v", err)
+	}
+
+	for _, name := range job.CompletedParts {
+		if err := s.blobStore.Delete(ctx, partKey(job, name)); err != nil {
+			log.Printf("Failed to delete export part: %v", err)
+		}
+	}
+
+	log.Printf("Data export %s for user %d completed", job.ID, job.UserID)
+	return nil
+}
+
+// collect asks one source for its files, retrying with backoff
+func (s *exportService) collect(ctx context.Context, source export.Source, user *models.User) ([]*data_export.ExportFile, error) {
+	var err error
+	for attempt := 1; attempt <= exportSourceAttempts; attempt++ {
+		callCtx, cancel := context.WithTimeout(ctx, exportSourceTimeout)
+		var files []*data_export.ExportFile
+		files, err = source.Export(callCtx, user.ID, user.Email)
+		cancel()
+		if err == nil {
+			return files, nil
+		}
+
+		if attempt < exportSourceAttempts {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(attempt) * 2 * time.Second):
+			}
+		}
+	}
+	return nil, err
+}
+
+type exportManifest struct {
+	ExportID    string           `json:"export_id"`
+	UserID      uint             `json:"user_id"`
+	RequestedAt time.Time        `json:"requested_at"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Sources     []manifestSource `json:"sources"`
+}
+
+type manifestSource struct {
+	Name  string   `json:"name"`
+	Files []string `json:"files"`
+}
+
+// buildArchive writes manifest.json and every part's files, one directory
+// per source, into a ZIP
+func (s *exportService) buildArchive(ctx context.Context, job *models.DataExport) ([]byte, error) {
+	manifest := exportManifest{
+		ExportID:    job.ID,
+		UserID:      job.UserID,
+		RequestedAt: job.CreatedAt,
+		GeneratedAt: time.Now(),
+	}
+	contents := map[string][]byte{}
+
+	for _, name := range job.CompletedParts {
+		files, err := s.readPart(ctx, job, name)
+		if err != nil {
+			return nil, err
+		}
+
+		source := manifestSource{Name: name, Files: []string{}}
+		for _, file := range files {
+			// Names come from other services, keep them inside the directory
+			base := path.Base(file.Name)
+			if base == "." || base == "/" || base == ".." {
+				continue
+			}
+			filePath := name + "/" + base
+			contents[filePath] = file.Content
+			source.Files = append(source.Files, filePath)
+		}
+		manifest.Sources = append(manifest.Sources, source)
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeZipFile(archive, "manifest.json", manifestJSON); err != nil {
+		return nil, err
+	}
+	for _, source := range manifest.Sources {
+		for _, filePath := range source.Files {
+			if err := writeZipFile(archive, filePath, contents[filePath]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build archive: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *exportService) readPart(ctx context.Context, job *models.DataExport, name string) ([]*data_export.ExportFile, error) {
+	reader, err := s.blobStore.Get(ctx, partKey(job, name))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var files []*data_export.ExportFile
+	if err := json.NewDecoder(reader).Decode(&files); err != nil {
+		return nil, fmt.Errorf("failed to read %s part: %v", name, err)
+	}
+	return files, nil
+}
+
+func writeZipFile(archive *zip.Writer, name string, content []byte) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to build archive: %v", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("failed to build archive: %v", err)
+	}
+	return nil
+}
+
+// deleteExpired removes ZIPs past their retention
+func (s *exportService) deleteExpired(ctx context.Context) {
+	jobs, err := s.exportRepo.ListExpired(time.Now())
+	if err != nil {
+		log.Printf("Failed to list expired data exports: %v", err)
+		return
+	}
+
+	for i := range jobs {
+		if err := s.blobStore.Delete(ctx, jobs[i].BlobKey); err != nil {
+			log.Printf("Failed to delete data export %s: %v", jobs[i].ID, err)
+			continue
+		}
+		jobs[i].Status = models.ExportStatusExpired
+		jobs[i].BlobKey = ""
+		if err := s.exportRepo.Update(&jobs[i]); err != nil {
+			log.Printf("Failed to save data export %s: %v", jobs[i].ID, err)
+		}
+	}
+}
+
+func partKey(job *models.DataExport, source string) string {
+	return fmt.Sprintf("exports/%d/%s/parts/%s.json", job.UserID, job.ID, source)
+}
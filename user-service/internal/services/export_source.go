@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"shared/dataexport"
+	"shared/proto/data_export"
+
+	"gorm.io/gorm"
+)
+
+// UserDataSource is user-service's own part of a data export: the user
+// record, profile, addresses, preferences, status history and notifications
+type UserDataSource struct {
+	userRepo           repository.UserRepository
+	userProfileRepo    repository.UserProfileRepository
+	addressRepo        repository.UserAddressRepository
+	preferencesService PreferencesService
+	notificationRepo   repository.NotificationRepository
+}
+
+func NewUserDataSource(userRepo repository.UserRepository, userProfileRepo repository.UserProfileRepository, addressRepo repository.UserAddressRepository, preferencesService PreferencesService, notificationRepo repository.NotificationRepository) *UserDataSource {
+	return &UserDataSource{
+		userRepo:           userRepo,
+		userProfileRepo:    userProfileRepo,
+		addressRepo:        addressRepo,
+		preferencesService: preferencesService,
+		notificationRepo:   notificationRepo,
+	}
+}
+
+// statusChange leaves out who made the change, that is the admin's data
+type statusChange struct {
+	FromStatus models.UserStatus `json:"from_status"`
+	ToStatus   models.UserStatus `json:"to_status"`
+	Reason     string            `json:"reason"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+func (s *UserDataSource) Name() string {
+	return "user-service"
+}
+
+func (s *UserDataSource) Export(ctx context.Context, userID uint, email string) ([]*data_export.ExportFile, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	profile, err := s.userProfileRepo.GetByUserID(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	addresses, err := s.addressRepo.ListByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if addresses == nil {
+		addresses = []models.UserAddress{}
+	}
+
+	preferences, err := s.preferencesService.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := s.userRepo.GetStatusHistory(userID)
+	if err != nil {
+		return nil, err
+	}
+	history := make([]statusChange, 0, len(changes))
+	for _, change := range changes {
+		history = append(history, statusChange{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Reason:     change.Reason,
+			CreatedAt:  change.CreatedAt,
+		})
+	}
+
+	notifications, err := s.notificationRepo.ListAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []models.Notification{}
+	}
+
+	documents := map[string]any{
+		"user":           user,
+		"addresses":      addresses,
+		"preferences":    preferences,
+		"status_history": history,
+		"notifications":  notifications,
+	}
+	if profile != nil {
+		documents["profile"] = profile
+	}
+
+	var files []*data_export.ExportFile
+	for name, data := range documents {
+		file, err := dataexport.JSONFile(name, data)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"user-service/internal/dto"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"shared/utils"
+
+	"gorm.io/gorm"
+)
+
+const defaultRelatedUsersLimit = 20
+
+type FollowService interface {
+	Follow(userID, targetID uint) error
+	Unfollow(userID, targetID uint) error
+	ListFollowers(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error)
+	ListFollowing(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error)
+	// GetFollowingIDs is who userID follows minus muted users, for feeds
+	GetFollowingIDs(userID uint) ([]uint, error)
+
+	Block(userID, targetID uint) error
+	Unblock(userID, targetID uint) error
+	ListBlocked(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error)
+	Mute(userID, targetID uint) error
+	Unmute(userID, targetID uint) error
+	ListMuted(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error)
+}
+
+type followService struct {
+	followRepo repository.FollowRepository
+	userRepo   repository.UserRepository
+}
+
+func NewFollowService(followRepo repository.FollowRepository, userRepo repository.UserRepository) FollowService {
+	return &followService{followRepo: followRepo, userRepo: userRepo}
+}
+
+// Follow fails with 403 when either user blocked the other, following
+// someone twice is fine
+func (s *followService) Follow(userID, targetID uint) error {
+	if err := s.checkTarget(userID, targetID, "follow"); err != nil {
+		return err
+	}
+
+	if err := s.followRepo.Follow(userID, targetID); err != nil {
+		if errors.Is(err, repository.ErrBlocked) {
+			return utils.Forbidden("You can't follow this user")
+		}
+		return utils.InternalServerError("Failed to follow user")
+	}
+	return nil
+}
+
+func (s *followService) Unfollow(userID, targetID uint) error {
+	if err := s.followRepo.Unfollow(userID, targetID); err != nil {
+		return utils.InternalServerError("Failed to unfollow user")
+	}
+	return nil
+}
+
+func (s *followService) ListFollowers(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error) {
+	return s.listRelated(userID, req, s.followRepo.ListFollowers, "followers")
+}
+
+func (s *followService) ListFollowing(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error) {
+	return s.listRelated(userID, req, s.followRepo.ListFollowing, "following")
+}
+
+func (s *followService) GetFollowingIDs(userID uint) ([]uint, error) {
+	ids, err := s.followRepo.FollowingIDs(userID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get following")
+	}
+	return ids, nil
+}
+
+// Block removes follows between the two users both ways and keeps them
+// from following each other until unblocked
+func (s *followService) Block(userID, targetID uint) error {
+	if err := s.checkTarget(userID, targetID, "block"); err != nil {
+		return err
+	}
+
+	if err := s.followRepo.Block(userID, targetID); err != nil {
+		return utils.InternalServerError("Failed to block user")
+	}
+	return nil
+}
+
+func (s *followService) Unblock(userID, targetID uint) error {
+	if err := s.followRepo.Unblock(userID, targetID); err != nil {
+		return utils.InternalServerError("Failed to unblock user")
+	}
+	return nil
+}
+
+func (s *followService) ListBlocked(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error) {
+	return s.listRelated(userID, req, s.followRepo.ListBlocked, "blocked users")
+}
+
+// Mute keeps the follow but hides the user from the following feed
+func (s *followService) Mute(userID, targetID uint) error {
+	if err := s.checkTarget(userID, targetID, "mute"); err != nil {
+		return err
+	}
+
+	if err := s.followRepo.Mute(userID, targetID); err != nil {
+		return utils.InternalServerError("Failed to mute user")
+	}
+	return nil
+}
+
+func (s *followService) Unmute(userID, targetID uint) error {
+	if err := s.followRepo.Unmute(userID, targetID); err != nil {
+		return utils.InternalServerError("Failed to unmute user")
+	}
+	return nil
+}
+
+func (s *followService) ListMuted(userID uint, req dto.RelatedUsersReq) (*dto.RelatedUsersRes, error) {
+	return s.listRelated(userID, req, s.followRepo.ListMuted, "muted users")
+}
+
+// checkTarget rejects acting on yourself or on an account that isn't active
+func (s *followService) checkTarget(userID, targetID uint, action string) error {
+	if userID == targetID {
+		return utils.NewLocalizedError(http.StatusBadRequest, "follow.self_"+action, nil)
+	}
+
+	target, err := s.userRepo.GetByID(targetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NotFound("User not found")
+		}
+		return utils.InternalServerError("Failed to get user by id")
+	}
+	if target.Status != models.UserStatusActive {
+		return utils.NotFound("User not found")
+	}
+	return nil
+}
+
+func (s *followService) listRelated(userID uint, req dto.RelatedUsersReq, list func(userID, beforeID uint, limit int) ([]repository.RelatedUserRow, error), what string) (*dto.RelatedUsersRes, error) {
+	if req.Limit == 0 {
+		req.Limit = defaultRelatedUsersLimit
+	}
+
+	rows, err := list(userID, req.Before, req.Limit)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get " + what)
+	}
+
+	res := &dto.RelatedUsersRes{Users: make([]dto.RelatedUser, 0, len(rows))}
+	for _, row := range rows {
+		res.Users = append(res.Users, dto.RelatedUser{
+			UserID:      row.UserID,
+			DisplayName: displayName(row.UserID, row.FirstName, row.LastName),
+			Since:       row.CreatedAt,
+		})
+	}
+	if len(rows) == req.Limit {
+		res.NextBefore = rows[len(rows)-1].ID
+	}
+	return res, nil
+}
+
+// displayName is the name other users see: the profile's full name, or
+// "User <id>" without one
+func displayName(userID uint, firstName, lastName *string) string {
+	if name := strings.TrimSpace(deref(firstName) + " " + deref(lastName)); name != "" {
+		return name
+	}
+	return fmt.Sprintf("User %d", userID)
+}
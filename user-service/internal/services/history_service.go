@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"user-service/internal/audit"
+	"user-service/internal/dto"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"shared/utils"
+)
+
+const defaultHistoryLimit = 20
+
+type HistoryService interface {
+	// Record stores the diff between two snapshots of an entity, before is
+	// nil for a create and after for a delete. The actor and request ID come
+	// from ctx, see the audit package. Failures are logged, not returned,
+	// so history never blocks the change itself.
+	Record(ctx context.Context, userID uint, entityType models.EntityType, entityID uint, before, after any)
+	// ListHistory returns a user's change history. Admin identities are
+	// only included when showActors is set.
+	ListHistory(userID uint, req dto.HistoryReq, showActors bool) (*dto.HistoryRes, error)
+}
+
+type historyService struct {
+	historyRepo repository.ChangeHistoryRepository
+}
+
+func NewHistoryService(historyRepo repository.ChangeHistoryRepository) HistoryService {
+	return &historyService{historyRepo: historyRepo}
+}
+
+func (s *historyService) Record(ctx context.Context, userID uint, entityType models.EntityType, entityID uint, before, after any) {
+	changes := audit.Diff(before, after)
+	if len(changes) == 0 {
+		return
+	}
+
+	action := models.ChangeUpdate
+	switch {
+	case before == nil:
+		action = models.ChangeCreate
+	case after == nil:
+		action = models.ChangeDelete
+	}
+
+	actor := audit.ActorFrom(ctx)
+	entry := &models.ChangeEntry{
+		UserID:     userID,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Action:     action,
+		Changes:    changes,
+		ActorEmail: actor.Email,
+		ActorRole:  actor.Role(),
+		RequestID:  audit.RequestIDFrom(ctx),
+	}
+	if actor.UserID != 0 {
+		entry.ActorID = &actor.UserID
+	}
+
+	if err := s.historyRepo.Create(entry); err != nil {
+		log.Printf("Failed to record %s %d change for user %d: %v", entityType, entityID, userID, err)
+	}
+}
+
+func (s *historyService) ListHistory(userID uint, req dto.HistoryReq, showActors bool) (*dto.HistoryRes, error) {
+	if req.Limit == 0 {
+		req.Limit = defaultHistoryLimit
+	}
+
+	entries, err := s.historyRepo.ListByUserID(userID, req.Before, req.Limit)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get change history")
+	}
+
+	res := &dto.HistoryRes{Entries: entries}
+	if entries == nil {
+		res.Entries = []models.ChangeEntry{}
+	}
+	if len(entries) == req.Limit {
+		res.NextBefore = entries[len(entries)-1].ID
+	}
+	if !showActors {
+		for i := range res.Entries {
+			if res.Entries[i].ActorRole == models.ActorRoleAdmin {
+				res.Entries[i].ActorID = nil
+				res.Entries[i].ActorEmail = ""
+			}
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"user-service/internal/dto"
+	"user-service/internal/models"
+	"user-service/internal/notify"
+	"user-service/internal/repository"
+
+	"shared/utils"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNotificationsLimit = 20
+
+	// How often old notifications are removed, and how many per query
+	notificationSweepInterval = time.Hour
+	notificationSweepBatch    = 1000
+
+	// How long a channel gets to deliver one notification
+	deliveryTimeout = 30 * time.Second
+)
+
+type NotificationService interface {
+	// Notify stores a notification in the user's inbox and hands it to every
+	// channel the user wants it on. It returns nil without error when the
+	// user opted out of the type.
+	Notify(ctx context.Context, req dto.CreateNotificationReq) (*models.Notification, error)
+	ListNotifications(userID uint, req dto.NotificationsReq) (*dto.NotificationsRes, error)
+	MarkRead(userID, notificationID uint) error
+	MarkAllRead(userID uint) error
+	// Run removes notifications past the retention period until ctx is done
+	Run(ctx context.Context)
+}
+
+type notificationService struct {
+	notificationRepo   repository.NotificationRepository
+	userRepo           repository.UserRepository
+	preferencesService PreferencesService
+	channels           []notify.Channel
+	retention          time.Duration
+}
+
+func NewNotificationService(notificationRepo repository.NotificationRepository, userRepo repository.UserRepository, preferencesService PreferencesService, channels []notify.Channel, retention time.Duration) NotificationService {
+	return &notificationService{
+		notificationRepo:   notificationRepo,
+		userRepo:           userRepo,
+		preferencesService: preferencesService,
+		channels:           channels,
+		retention:          retention,
+	}
+}
+
+func (s *notificationService) Notify(ctx context.Context, req dto.CreateNotificationReq) (*models.Notification, error) {
+	if !req.Type.Valid() {
+		return nil, utils.NewLocalizedError(http.StatusBadRequest, "notification.unknown_type", map[string]string{"type": string(req.Type)})
+	}
+	title := strings.TrimSpace(req.Title)
+	if title == "" || len(title) > 255 {
+		return nil, utils.BadRequest("Title is required and must be at most 255 characters")
+	}
+	if len(req.Link) > 500 {
+		return nil, utils.BadRequest("Link must be at most 500 characters")
+	}
+
+	user, err := s.userRepo.GetByID(req.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.NotFound("User not found")
+		}
+		return nil, utils.InternalServerError("Failed to get user by id")
+	}
+
+	preferences, err := s.preferencesService.GetPreferences(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if preferences.OptedOut(req.Type) {
+		return nil, nil
+	}
+
+	notification := &models.Notification{
+		UserID: user.ID,
+		Type:   req.Type,
+		Title:  title,
+		Body:   req.Body,
+		Link:   req.Link,
+	}
+	if err := s.notificationRepo.Create(notification); err != nil {
+		return nil, utils.InternalServerError("Failed to create notification")
+	}
+
+	recipient := notify.Recipient{UserID: user.ID, Email: user.Email}
+	for _, channel := range s.channels {
+		if channel.Accepts(preferences, notification) {
+			go s.deliver(channel, recipient, notification)
+		}
+	}
+	return notification, nil
+}
+
+// deliver runs apart from the request so a slow channel never holds up
+// the caller, failures are only logged
+func (s *notificationService) deliver(channel notify.Channel, to notify.Recipient, notification *models.Notification) {
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	if err := channel.Deliver(ctx, to, notification); err != nil {
+		log.Printf("Failed to deliver notification %d by %s: %v", notification.ID, channel.Name(), err)
+	}
+}
+
+func (s *notificationService) ListNotifications(userID uint, req dto.NotificationsReq) (*dto.NotificationsRes, error) {
+	if req.Limit == 0 {
+		req.Limit = defaultNotificationsLimit
+	}
+
+	notifications, err := s.notificationRepo.List(userID, req.Unread, req.Before, req.Limit)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get notifications")
+	}
+	unread, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get notifications")
+	}
+
+	res := &dto.NotificationsRes{Notifications: notifications, UnreadCount: unread}
+	if notifications == nil {
+		res.Notifications = []models.Notification{}
+	}
+	if len(notifications) == req.Limit {
+		res.NextBefore = notifications[len(notifications)-1].ID
+	}
+	return res, nil
+}
+
+func (s *notificationService) MarkRead(userID, notificationID uint) error {
+	if err := s.notificationRepo.MarkRead(userID, notificationID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NotFound("Notification not found")
+		}
+		return utils.InternalServerError("Failed to mark notification as read")
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(userID uint) error {
+	if err := s.notificationRepo.MarkAllRead(userID); err != nil {
+		return utils.InternalServerError("Failed to mark notifications as read")
+	}
+	return nil
+}
+
+func (s *notificationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(notificationSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		s.deleteExpired(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *notificationService) deleteExpired(ctx context.Context) {
+	cutoff := time.Now().Add(-s.retention)
+	for ctx.Err() == nil {
+		deleted, err := s.notificationRepo.DeleteOlderThan(cutoff, notificationSweepBatch)
+		if err != nil {
+			log.Printf("Failed to delete old notifications: %v", err)
+			return
+		}
+		if deleted < notificationSweepBatch {
+			return
+		}
+	}
+}
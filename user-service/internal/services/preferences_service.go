@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"slices"
+
+	"user-service/internal/dto"
+	"user-service/internal/models"
+	"user-service/internal/repository"
+
+	"shared/utils"
+
+	"gorm.io/gorm"
+)
+
+type PreferencesService interface {
+	GetPreferences(userID uint) (*models.UserPreferences, error)
+	UpdatePreferences(userID uint, req dto.UpdatePreferencesReq) (*models.UserPreferences, error)
+}
+
+type preferencesService struct {
+	preferencesRepo repository.UserPreferencesRepository
+	defaults        models.UserPreferences
+}
+
+func NewPreferencesService(preferencesRepo repository.UserPreferencesRepository, defaults models.UserPreferences) PreferencesService {
+	return &preferencesService{
+		preferencesRepo: preferencesRepo,
+		defaults:        defaults,
+	}
+}
+
+// GetPreferences returns the stored preferences, or the defaults when the
+// user never changed any. Defaults are not persisted so changing them in
+// config applies to every such user.
+func (s *preferencesService) GetPreferences(userID uint) (*models.UserPreferences, error) {
+	preferences, err := s.preferencesRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			defaults := s.defaults
+			defaults.ID = userID
+			defaults.NotificationOptOuts = []models.NotificationType{}
+			return &defaults, nil
+		}
+		return nil, utils.InternalServerError("Failed to get preferences")
+	}
+	if preferences.NotificationOptOuts == nil {
+		preferences.NotificationOptOuts = []models.NotificationType{}
+	}
+	return preferences, nil
+}
+
+func (s *preferencesService) UpdatePreferences(userID uint, req dto.UpdatePreferencesReq) (*models.UserPreferences, error) {
+	preferences, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Theme != nil {
+		preferences.Theme = *req.Theme
+	}
+	if req.Language != nil {
+		preferences.Language = *req.Language
+	}
+	if req.Timezone != nil {
+		preferences.Timezone = *req.Timezone
+	}
+	if req.ItemsPerPage != nil {
+		preferences.ItemsPerPage = *req.ItemsPerPage
+	}
+	if email := req.EmailNotifications; email != nil {
+		if email.SecurityAlerts != nil {
+			preferences.EmailNotifications.SecurityAlerts = *email.SecurityAlerts
+		}
+		if email.Recommendations != nil {
+			preferences.EmailNotifications.Recommendations = *email.Recommendations
+		}
+		if email.Newsletter != nil {
+			preferences.EmailNotifications.Newsletter = *email.Newsletter
+		}
+	}
+
+	if req.NotificationOptOuts != nil {
+		preferences.NotificationOptOuts = slices.Compact(slices.Sorted(slices.Values(*req.NotificationOptOuts)))
+	}
+
+	if err := s.preferencesRepo.Save(preferences); err != nil {
+		return nil, utils.InternalServerError("Failed to update preferences")
+	}
+
+	return preferences, nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+
+	"user-service/internal/dto"
+	"user-service/internal/models"
+
+	"shared/utils"
+)
+
+// Fields a client may set through PATCH, everything else is read-only
+var (
+	editableProfileFields = map[string]bool{
+		"first_name":    true,
+		"last_name":     true,
+		"phone":         true,
+		"date_of_birth": true,
+		"gender":        true,
+		"bio":           true,
+		"privacy":       true,
+	}
+	editableNestedFields = map[string]map[string]bool{
+		"privacy": {
+			"bio":           true,
+			"location":      true,
+			"avatar":        true,
+			"date_of_birth": true,
+		},
+	}
+)
+
+// profileFromReq maps a request onto a profile. Omitted and blank fields
+// become nil so they are stored as NULL rather than empty strings.
+func profileFromReq(userID uint, req dto.CreateUserProfileReq) *models.UserProfile {
+	profile := &models.UserProfile{
+		ID:          userID,
+		FirstName:   trimmed(req.FirstName),
+		LastName:    trimmed(req.LastName),
+		Phone:       trimmed(req.Phone),
+		DateOfBirth: req.DateOfBirth,
+		Gender:      req.Gender,
+		Bio:         trimmed(req.Bio),
+		Privacy:     models.DefaultProfilePrivacy,
+	}
+
+	if req.Privacy != nil {
+		setIfPresent(&profile.Privacy.Bio, req.Privacy.Bio)
+		setIfPresent(&profile.Privacy.Location, req.Privacy.Location)
+		setIfPresent(&profile.Privacy.Avatar, req.Privacy.Avatar)
+		setIfPresent(&profile.Privacy.DateOfBirth, req.Privacy.DateOfBirth)
+	}
+
+	return profile
+}
+
+func setIfPresent[T any](dst *T, value *T) {
+	if value != nil {
+		*dst = *value
+	}
+}
+
+// checkPatchFields rejects patches that touch read-only or unknown fields
+func checkPatchFields(patch map[string]any) error {
+	fields := map[string]string{}
+
+	for key, value := range patch {
+		if !editableProfileFields[key] {
+			fields[key] = "cannot be changed"
+			continue
+		}
+
+		nested, ok := value.(map[string]any)
+		if !ok || editableNestedFields[key] == nil {
+			continue
+		}
+		for nestedKey := range nested {
+			if !editableNestedFields[key][nestedKey] {
+				fields[key+"."+nestedKey] = "cannot be changed"
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return utils.ValidationFailed(fields)
+	}
+	return nil
+}
+
+// mergePatch applies an RFC 7396 JSON Merge Patch: objects are merged
+// recursively, null removes a member and anything else replaces it
+func mergePatch(target, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergePatch(targetObject[key], value)
+	}
+
+	return targetObject
+}
+
+// applyProfilePatch merges the patch into the current profile and returns
+// the result as a validated request, ready for profileFromReq
+func applyProfilePatch(profile *models.UserProfile, patchJSON []byte) (*dto.CreateUserProfileReq, error) {
+	var patch map[string]any
+	if err := json.Unmarshal(patchJSON, &patch); err != nil {
+		return nil, utils.BadRequest("Request body must be a JSON object")
+	}
+	if err := checkPatchFields(patch); err != nil {
+		return nil, err
+	}
+
+	currentJSON, err := json.Marshal(profile)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to update user profile")
+	}
+	var current map[string]any
+	if err := json.Unmarshal(currentJSON, &current); err != nil {
+		return nil, utils.InternalServerError("Failed to update user profile")
+	}
+
+	mergedJSON, err := json.Marshal(mergePatch(current, patch))
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to update user profile")
+	}
+
+	var req dto.CreateUserProfileReq
+	if err := utils.DecodeJSON(mergedJSON, &req); err != nil {
+		return nil, err
+	}
+	if err := utils.Validate(&req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func trimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	s := strings.TrimSpace(*value)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -1,11 +1,25 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
 
+	"user-service/internal/audit"
+	"user-service/internal/avatar"
 	"user-service/internal/dto"
 	"user-service/internal/models"
 	"user-service/internal/repository"
+	"user-service/internal/storage"
 
 	"shared/utils"
 
@@ -13,22 +27,40 @@ import (
 )
 
 type UserService interface {
-	CreateUserProfile(userID uint, req dto.CreateUserProfileReq) (*models.UserProfile, error)
+	CreateUserProfile(ctx context.Context, userID uint, req dto.CreateUserProfileReq) (*models.UserProfile, error)
 	GetUserProfile(userID uint) (*models.UserProfile, error)
-	CreateUser(email string) (*models.User, error)
+	ReplaceUserProfile(ctx context.Context, userID uint, req dto.CreateUserProfileReq) (*models.UserProfile, error)
+	PatchUserProfile(ctx context.Context, userID uint, patch []byte) (*models.UserProfile, error)
+	DeleteUserProfile(ctx context.Context, userID uint) error
+	UploadAvatar(ctx context.Context, userID uint, data []byte) (*models.UserProfile, error)
+	ChangeUserStatus(ctx context.Context, userID uint, req dto.ChangeUserStatusReq) (*models.User, error)
+	GetUserStatusHistory(userID uint) ([]models.UserStatusChange, error)
+	ListUsers(req dto.ListUsersReq) (*dto.ListUsersRes, error)
+	CreateUser(ctx context.Context, email string) (*models.User, error)
+	CreateUsers(ctx context.Context, emails []string) ([]models.User, []string, error)
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(userID uint) (*models.User, error)
+	GetUsersByIDs(userIDs []uint) ([]models.User, error)
+	GetPublicProfile(userID uint) (*dto.PublicProfile, error)
 }
 
 type userService struct {
 	userRepo        repository.UserRepository
 	userProfileRepo repository.UserProfileRepository
+	addressRepo     repository.UserAddressRepository
+	history         HistoryService
+	blobStore       storage.BlobStore
+	avatarProcessor *avatar.Processor
 }
 
-func NewUserService(userRepo repository.UserRepository, userProfileRepo repository.UserProfileRepository) UserService {
+func NewUserService(userRepo repository.UserRepository, userProfileRepo repository.UserProfileRepository, addressRepo repository.UserAddressRepository, history HistoryService, blobStore storage.BlobStore, avatarProcessor *avatar.Processor) UserService {
 	return &userService{
 		userRepo:        userRepo,
 		userProfileRepo: userProfileRepo,
+		addressRepo:     addressRepo,
+		history:         history,
+		blobStore:       blobStore,
+		avatarProcessor: avatarProcessor,
 	}
 }
 
@@ -43,7 +75,7 @@ func (s *userService) GetUserProfile(userID uint) (*models.UserProfile, error) {
 	return profile, nil
 }
 
-func (s *userService) CreateUserProfile(userID uint, req dto.CreateUserProfileReq) (*models.UserProfile, error) {
+func (s *userService) CreateUserProfile(ctx context.Context, userID uint, req dto.CreateUserProfileReq) (*models.UserProfile, error) {
 	existingProfile, err := s.userProfileRepo.GetByUserID(userID)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, utils.InternalServerError("Failed to check existing profile")
@@ -52,24 +84,170 @@ func (s *userService) CreateUserProfile(userID uint, req dto.CreateUserProfileRe
 		return nil, utils.Conflict("User profile already exists")
 	}
 
-	profile := &models.UserProfile{
-		ID:          userID,
-		FirstName:   &req.FirstName,
-		LastName:    &req.LastName,
-		Phone:       &req.Phone,
-		DateOfBirth: &req.DateOfBirth,
-		Gender:      &req.Gender,
-		Bio:         &req.Bio,
-		Address:     &req.Address,
-	}
+	profile := profileFromReq(userID, req)
 
 	if err := s.userProfileRepo.Create(profile); err != nil {
 		return nil, utils.InternalServerError("Failed to create user profile")
 	}
 
+	s.history.Record(ctx, userID, models.EntityUserProfile, userID, nil, profile)
+	return profile, nil
+}
+
+// ReplaceUserProfile overwrites every editable field, fields missing from
+// req are cleared
+func (s *userService) ReplaceUserProfile(ctx context.Context, userID uint, req dto.CreateUserProfileReq) (*models.UserProfile, error) {
+	existingProfile, err := s.GetUserProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := profileFromReq(userID, req)
+	keepReadOnlyFields(profile, existingProfile)
+
+	if err := s.userProfileRepo.Update(profile); err != nil {
+		return nil, utils.InternalServerError("Failed to update user profile")
+	}
+
+	s.history.Record(ctx, userID, models.EntityUserProfile, userID, existingProfile, profile)
+	return profile, nil
+}
+
+// PatchUserProfile applies a JSON Merge Patch (RFC 7396) to the profile
+func (s *userService) PatchUserProfile(ctx context.Context, userID uint, patch []byte) (*models.UserProfile, error) {
+	existingProfile, err := s.GetUserProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := applyProfilePatch(existingProfile, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := profileFromReq(userID, *req)
+	keepReadOnlyFields(profile, existingProfile)
+
+	if err := s.userProfileRepo.Update(profile); err != nil {
+		return nil, utils.InternalServerError("Failed to update user profile")
+	}
+
+	s.history.Record(ctx, userID, models.EntityUserProfile, userID, existingProfile, profile)
 	return profile, nil
 }
 
+func (s *userService) DeleteUserProfile(ctx context.Context, userID uint) error {
+	profile, err := s.GetUserProfile(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userProfileRepo.Delete(userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.NotFound("User profile not found")
+		}
+		return utils.InternalServerError("Failed to delete user profile")
+	}
+
+	s.history.Record(ctx, userID, models.EntityUserProfile, userID, profile, nil)
+	s.deleteBlobs(ctx, profile.AvatarKeys)
+	return nil
+}
+
+// UploadAvatar stores a new avatar with its thumbnails and replaces the
+// previous one
+func (s *userService) UploadAvatar(ctx context.Context, userID uint, data []byte) (*models.UserProfile, error) {
+	profile, err := s.GetUserProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	images, err := s.avatarProcessor.Process(data)
+	if err != nil {
+		return nil, avatarError(err)
+	}
+
+	// A fresh prefix per upload keeps URLs cacheable forever
+	prefix, err := randomHex(8)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to store avatar")
+	}
+
+	var keys []string
+	thumbnails := make(map[string]string, len(avatar.ThumbnailSizes))
+	var avatarURL string
+	for _, img := range images {
+		key := fmt.Sprintf("avatars/%d/%s/%s.%s", userID, prefix, img.Name, img.Extension)
+		if err := s.blobStore.Put(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType); err != nil {
+			log.Printf("Failed to store avatar blob %s: %v", key, err)
+			s.deleteBlobs(ctx, keys)
+			return nil, utils.InternalServerError("Failed to store avatar")
+		}
+		keys = append(keys, key)
+
+		if img.Name == "original" {
+			avatarURL = s.blobStore.URL(key)
+		} else {
+			thumbnails[img.Name] = s.blobStore.URL(key)
+		}
+	}
+
+	before := *profile
+	previousKeys := profile.AvatarKeys
+	profile.AvatarURL = &avatarURL
+	profile.AvatarThumbnails = thumbnails
+	profile.AvatarKeys = keys
+
+	if err := s.userProfileRepo.Update(profile); err != nil {
+		s.deleteBlobs(ctx, keys)
+		return nil, utils.InternalServerError("Failed to update user profile")
+	}
+
+	s.history.Record(ctx, userID, models.EntityUserProfile, userID, &before, profile)
+	s.deleteBlobs(ctx, previousKeys)
+	return profile, nil
+}
+
+// deleteBlobs is best effort, a leftover blob is only wasted space
+func (s *userService) deleteBlobs(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := s.blobStore.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete blob %s: %v", key, err)
+		}
+	}
+}
+
+func avatarError(err error) error {
+	switch {
+	case errors.Is(err, avatar.ErrUnsupportedType):
+		return utils.NewCustomError(http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, avatar.ErrTooLarge):
+		return utils.NewCustomError(http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, avatar.ErrBadDimensions), errors.Is(err, avatar.ErrCorrupt):
+		return utils.BadRequest(err.Error())
+	default:
+		log.Printf("Failed to process avatar: %v", err)
+		return utils.InternalServerError("Failed to process avatar")
+	}
+}
+
+// keepReadOnlyFields copies what PUT and PATCH cannot change from the stored
+// profile onto its replacement
+func keepReadOnlyFields(profile, existing *models.UserProfile) {
+	profile.CreatedAt = existing.CreatedAt
+	profile.AvatarURL = existing.AvatarURL
+	profile.AvatarThumbnails = existing.AvatarThumbnails
+	profile.AvatarKeys = existing.AvatarKeys
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 func (s *userService) GetUserByEmail(email string) (*models.User, error) {
 	user, err := s.userRepo.GetByEmail(email)
 	if err != nil {
@@ -92,7 +270,101 @@ func (s *userService) GetUserByID(userID uint) (*models.User, error) {
 	return user, nil
 }
 
-func (s *userService) CreateUser(email string) (*models.User, error) {
+// GetUsersByIDs returns the users that exist among userIDs, in no
+// particular order
+func (s *userService) GetUsersByIDs(userIDs []uint) ([]models.User, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	users, err := s.userRepo.GetByIDs(userIDs)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get users")
+	}
+	return users, nil
+}
+
+// GetPublicProfile returns what others may see of a user: the name and
+// whichever of avatar, bio, location and age range the user made public.
+// Users without a name are shown as "User <id>" so the email is never exposed.
+func (s *userService) GetPublicProfile(userID uint) (*dto.PublicProfile, error) {
+	if _, err := s.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	public := &dto.PublicProfile{
+		UserID:      userID,
+		DisplayName: displayName(userID, nil, nil),
+	}
+
+	profile, err := s.userProfileRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return public, nil
+		}
+		return nil, utils.InternalServerError("Failed to get user profile")
+	}
+
+	public.DisplayName = displayName(userID, profile.FirstName, profile.LastName)
+
+	privacy := profile.Privacy
+	if privacy.Avatar == models.VisibilityPublic {
+		public.AvatarURL = deref(profile.AvatarURL)
+		public.AvatarThumbnails = profile.AvatarThumbnails
+	}
+	if privacy.Bio == models.VisibilityPublic {
+		public.Bio = deref(profile.Bio)
+	}
+	if privacy.Location == models.VisibilityPublic {
+		address, err := s.addressRepo.GetDefault(userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.InternalServerError("Failed to get user profile")
+		}
+		if address != nil {
+			public.City = address.City
+			public.Country = address.Country
+		}
+	}
+	if privacy.DateOfBirth == models.VisibilityPublic && profile.DateOfBirth != nil {
+		public.AgeRange = ageRange(profile.DateOfBirth.Time, time.Now())
+	}
+	return public, nil
+}
+
+// ageRange buckets an age so the public profile never reveals the exact
+// date of birth
+func ageRange(dateOfBirth, now time.Time) string {
+	age := now.Year() - dateOfBirth.Year()
+	if now.Month() < dateOfBirth.Month() || (now.Month() == dateOfBirth.Month() && now.Day() < dateOfBirth.Day()) {
+		age--
+	}
+
+	switch {
+	case age < 18:
+		return "under 18"
+	case age < 25:
+		return "18-24"
+	case age < 35:
+		return "25-34"
+	case age < 45:
+		return "35-44"
+	case age < 55:
+		return "45-54"
+	case age < 65:
+		return "55-64"
+	default:
+		return "65+"
+	}
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func (s *userService) CreateUser(ctx context.Context, email string) (*models.User, error) {
 	existingUser, err := s.userRepo.GetByEmail(email)
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, utils.InternalServerError("Failed to check existing email")
@@ -103,12 +375,205 @@ func (s *userService) CreateUser(email string) (*models.User, error) {
 
 	user := &models.User{
 		Email:  email,
-		Status: "active",
+		Status: models.UserStatusActive,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, utils.InternalServerError("Failed to create user")
 	}
 
+	s.history.Record(ctx, user.ID, models.EntityUser, user.ID, nil, user)
 	return user, nil
 }
+
+// CreateUsers creates a user for each of emails that has none and returns
+// them, along with the emails that already had one
+func (s *userService) CreateUsers(ctx context.Context, emails []string) ([]models.User, []string, error) {
+	existingUsers, err := s.userRepo.GetByEmails(emails)
+	if err != nil {
+		return nil, nil, utils.InternalServerError("Failed to check existing emails")
+	}
+	existing := make(map[string]bool, len(existingUsers))
+	for _, user := range existingUsers {
+		existing[strings.ToLower(user.Email)] = true
+	}
+
+	var users []models.User
+	var existingEmails []string
+	for _, email := range emails {
+		if existing[strings.ToLower(email)] {
+			existingEmails = append(existingEmails, email)
+			continue
+		}
+		existing[strings.ToLower(email)] = true
+		users = append(users, models.User{
+			Email:  email,
+			Status: models.UserStatusActive,
+		})
+	}
+	if len(users) == 0 {
+		return nil, existingEmails, nil
+	}
+
+	if err := s.userRepo.CreateBatch(users); err != nil {
+		return nil, nil, utils.InternalServerError("Failed to create users")
+	}
+
+	for i := range users {
+		s.history.Record(ctx, users[i].ID, models.EntityUser, users[i].ID, nil, &users[i])
+	}
+	return users, existingEmails, nil
+}
+
+// ChangeUserStatus applies an admin status transition, see
+// models.UserStatus.CanTransitionTo for the allowed moves. The admin is the
+// actor in ctx.
+func (s *userService) ChangeUserStatus(ctx context.Context, userID uint, req dto.ChangeUserStatusReq) (*models.User, error) {
+	actor := audit.ActorFrom(ctx)
+
+	var next models.UserStatus
+	if err := next.FromString(req.Status); err != nil {
+		return nil, utils.ValidationFailed(map[string]string{"status": "is not a valid status"})
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.Status.CanTransitionTo(next) {
+		return nil, utils.NewLocalizedError(http.StatusConflict, "user.status_transition", map[string]string{
+			"from": user.Status.String(),
+			"to":   next.String(),
+		})
+	}
+
+	change := &models.UserStatusChange{
+		UserID:     user.ID,
+		FromStatus: user.Status,
+		ToStatus:   next,
+		Reason:     req.Reason,
+		ActorID:    actor.UserID,
+		ActorEmail: actor.Email,
+	}
+	if err := s.userRepo.ChangeStatus(change); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return nil, utils.Conflict("User status was changed by someone else, reload and try again")
+		}
+		return nil, utils.InternalServerError("Failed to change user status")
+	}
+
+	log.Printf("User %d status changed from %s to %s by %s", user.ID, change.FromStatus, change.ToStatus, actor.Email)
+
+	before := *user
+	user.Status = next
+	s.history.Record(ctx, user.ID, models.EntityUser, user.ID, &before, user)
+	return user, nil
+}
+
+func (s *userService) GetUserStatusHistory(userID uint) ([]models.UserStatusChange, error) {
+	if _, err := s.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+
+	changes, err := s.userRepo.GetStatusHistory(userID)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to get status history")
+	}
+	return changes, nil
+}
+
+const (
+	defaultListUsersLimit = 20
+	defaultListUsersSort  = "created_at"
+)
+
+// ListUsers returns one page of the admin user directory. The next cursor
+// is only set when there are more users after this page.
+func (s *userService) ListUsers(req dto.ListUsersReq) (*dto.ListUsersRes, error) {
+	if req.Sort == "" {
+		req.Sort = defaultListUsersSort
+	}
+	if req.Order == "" {
+		req.Order = "desc"
+		if req.Sort == "email" {
+			req.Order = "asc"
+		}
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultListUsersLimit
+	}
+	req.Query = strings.TrimSpace(req.Query)
+
+	statuses, err := parseStatusFilter(req.Status)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *dto.UserCursor
+	if req.Cursor != "" {
+		after, err = decodeUserCursor(req.Cursor)
+		if err != nil || after.Sort != req.Sort || after.Order != req.Order {
+			return nil, utils.ValidationFailed(map[string]string{"cursor": "is invalid for this sort order"})
+		}
+	}
+
+	// Fetch one extra row to know whether another page follows
+	limit := req.Limit
+	req.Limit++
+	users, err := s.userRepo.ListUsers(req, statuses, after)
+	if err != nil {
+		return nil, utils.InternalServerError("Failed to list users")
+	}
+
+	res := &dto.ListUsersRes{Users: users}
+	if len(users) > limit {
+		res.Users = users[:limit]
+		last := res.Users[limit-1]
+		res.NextCursor = encodeUserCursor(dto.UserCursor{
+			Sort:      req.Sort,
+			Order:     req.Order,
+			CreatedAt: last.CreatedAt,
+			Email:     last.Email,
+			ID:        last.ID,
+		})
+	}
+	if res.Users == nil {
+		res.Users = []dto.UserSummary{}
+	}
+	return res, nil
+}
+
+func parseStatusFilter(filter string) ([]models.UserStatus, error) {
+	if filter == "" {
+		return nil, nil
+	}
+
+	var statuses []models.UserStatus
+	for _, name := range strings.Split(filter, ",") {
+		var status models.UserStatus
+		if err := status.FromString(strings.TrimSpace(name)); err != nil {
+			return nil, utils.ValidationFailed(map[string]string{"status": "must be a comma separated list of active, inactive, suspended, pending_verification or banned"})
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func encodeUserCursor(cursor dto.UserCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeUserCursor(s string) (*dto.UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var cursor dto.UserCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, err
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// BlobStore keeps binary objects such as avatars under slash-separated keys
+type BlobStore interface {
+	Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error
+	// Get opens an object for reading, the caller closes it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// URL is the public address clients use to download the object
+	URL(key string) string
+}
+
+// Config selects and configures a BlobStore
+type Config struct {
+	Driver    string // local or s3
+	PublicURL string // base URL objects are served from
+
+	LocalDir string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+}
+
+// NewBlobStore builds the BlobStore selected by cfg.Driver
+func NewBlobStore(ctx context.Context, cfg Config) (BlobStore, error) {
+	switch cfg.Driver {
+	case "local", "":
+		return NewLocalBlobStore(cfg.LocalDir, cfg.PublicURL)
+	case "s3":
+		return NewS3BlobStore(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unknown blob store driver: %s", cfg.Driver)
+	}
+}
+
+// -----------------------
+// -- Local filesystem --
+// -----------------------
+
+// LocalBlobStore writes objects below a directory. The service serves that
+// directory itself, see cmd/main.go.
+type LocalBlobStore struct {
+	dir       string
+	publicURL string
+}
+
+func NewLocalBlobStore(dir, publicURL string) (*LocalBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %v", err)
+	}
+
+	return &LocalBlobStore{
+		dir:       dir,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}, nil
+}
+
+func (s *LocalBlobStore) Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %v", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write blob: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to store blob: %v", err)
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %v", err)
+	}
+	return file, nil
+}
+
+func (s *LocalBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete blob: %v", err)
+	}
+	return nil
+}
+
+func (s *LocalBlobStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// Dir is the directory objects are written to
+func (s *LocalBlobStore) Dir() string {
+	return s.dir
+}
+
+// path maps a key into the store directory, rejecting keys that escape it
+func (s *LocalBlobStore) path(key string) (string, error) {
+	path := filepath.Join(s.dir, filepath.FromSlash(key))
+	if !strings.HasPrefix(path, filepath.Clean(s.dir)+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid blob key: %s", key)
+	}
+	return path, nil
+}
+
+// -----------------------
+// -- S3 compatible --
+// -----------------------
+
+// S3BlobStore talks to AWS S3 or any compatible server such as MinIO
+type S3BlobStore struct {
+	client    *minio.Client
+	bucket    string
+	publicURL string
+}
+
+func NewS3BlobStore(ctx context.Context, cfg Config) (*S3BlobStore, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure: cfg.S3UseSSL,
+		Region: cfg.S3Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %v", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.S3Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check S3 bucket: %v", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.S3Bucket, minio.MakeBucketOptions{Region: cfg.S3Region}); err != nil {
+			return nil, fmt.Errorf("failed to create S3 bucket: %v", err)
+		}
+	}
+
+	publicURL := cfg.PublicURL
+	if publicURL == "" {
+		// Path-style URL straight to the bucket
+		scheme := "http"
+		if cfg.S3UseSSL {
+			scheme = "https"
+		}
+		publicURL = (&url.URL{Scheme: scheme, Host: cfg.S3Endpoint, Path: "/" + cfg.S3Bucket}).String()
+	}
+
+	return &S3BlobStore{
+		client:    client,
+		bucket:    cfg.S3Bucket,
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}, nil
+}
+
+func (s *S3BlobStore) Put(ctx context.Context, key string, data io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, data, size, minio.PutObjectOptions{
+		ContentType:  contentType,
+		CacheControl: "public, max-age=31536000, immutable",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload blob: %v", err)
+	}
+	return nil
+}
+
+func (s *S3BlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %v", err)
+	}
+	// GetObject is lazy, Stat surfaces missing objects before the first read
+	if _, err := object.Stat(); err != nil {
+		object.Close()
+		return nil, fmt.Errorf("failed to open blob: %v", err)
+	}
+	return object, nil
+}
+
+func (s *S3BlobStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete blob: %v", err)
+	}
+	return nil
+}
+
+func (s *S3BlobStore) URL(key string) string {
+	return s.publicURL + "/" + key
+}
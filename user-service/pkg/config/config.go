@@ -2,9 +2,17 @@ package config
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"user-service/internal/models"
+	"user-service/internal/storage"
+
+	"shared/mail"
+	"shared/ratelimit"
 )
 
 type Config struct {
@@ -20,12 +28,75 @@ type Config struct {
 	AuthServiceURL string
 	RedisURL       string
 
+	// Audience this service expects in access tokens
+	TokenAudience string
+
 	// Cache configuration
 	CacheEnabled      bool
 	L1CacheTTLMinutes int
 	L2CacheTTLMinutes int
 
 	JWTSecret string
+
+	// Rate limiting
+	RateLimitEnabled  bool
+	RateLimitPolicies []ratelimit.Policy
+
+	// Accounts allowed to use the /api/v1/admin endpoints
+	AdminEmails []string
+
+	// Avatar uploads
+	AvatarMaxBytes     int64
+	AvatarMinDimension int
+	AvatarMaxDimension int
+
+	// Blob storage for avatars, local or s3
+	BlobStoreDriver    string
+	BlobStorePublicURL string
+	BlobStoreLocalDir  string
+	S3Endpoint         string
+	S3Region           string
+	S3Bucket           string
+	S3AccessKey        string
+	S3SecretKey        string
+	S3UseSSL           bool
+
+	// GDPR data exports, kept apart from the publicly served avatars
+	ExportSigningKey     string
+	ExportLinkTTLMinutes int
+	ExportRetentionHours int
+	ExportDownloadURL    string
+	ExportSources        []ExportSource
+	ExportLocalDir       string
+	ExportS3Bucket       string
+
+	// Keyring for the encrypted profile and address columns
+	PIIKeyringPath string
+
+	// Notifications, emailed through MAIL_DRIVER
+	NotificationRetentionDays int
+	MailDriver                string
+	SMTPHost                  string
+	SMTPPort                  string
+	SMTPUsername              string
+	SMTPPassword              string
+	MailFrom                  string
+
+	// Preferences for users who never saved their own
+	DefaultTheme                string
+	DefaultLanguage             string
+	DefaultTimezone             string
+	DefaultItemsPerPage         int
+	DefaultEmailSecurityAlerts  bool
+	DefaultEmailRecommendations bool
+	DefaultEmailNewsletter      bool
+}
+
+// ExportSource is a service that contributes to data exports through the
+// DataExport gRPC contract
+type ExportSource struct {
+	Name    string
+	Address string
 }
 
 func LoadConfig() (*Config, error) {
@@ -34,6 +105,50 @@ func LoadConfig() (*Config, error) {
 	l1CacheTTL, _ := strconv.Atoi(getEnv("L1_CACHE_TTL_MINUTES", "5"))
 	l2CacheTTL, _ := strconv.Atoi(getEnv("L2_CACHE_TTL_MINUTES", "15"))
 
+	// Parse rate limit configuration, see ratelimit.ParsePolicies for the format
+	rateLimitEnabled, _ := strconv.ParseBool(getEnv("RATE_LIMIT_ENABLED", "true"))
+	rateLimitPolicies, err := ratelimit.ParsePolicies(getEnv("RATE_LIMIT_POLICIES", "POST /cache/clear=sliding_window:2/1m:ip;*=token_bucket:120/1m:user"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMIT_POLICIES: %v", err)
+	}
+
+	// Parse avatar limits
+	avatarMaxBytes, _ := strconv.ParseInt(getEnv("AVATAR_MAX_BYTES", "5242880"), 10, 64)
+	avatarMinDimension, _ := strconv.Atoi(getEnv("AVATAR_MIN_DIMENSION", "64"))
+	avatarMaxDimension, _ := strconv.Atoi(getEnv("AVATAR_MAX_DIMENSION", "4096"))
+	s3UseSSL, _ := strconv.ParseBool(getEnv("S3_USE_SSL", "false"))
+
+	// Parse data export settings, sources are name=address pairs
+	exportLinkTTL, _ := strconv.Atoi(getEnv("EXPORT_LINK_TTL_MINUTES", "15"))
+	exportRetention, _ := strconv.Atoi(getEnv("EXPORT_RETENTION_HOURS", "72"))
+	var exportSources []ExportSource
+	for _, item := range getEnvList("EXPORT_SOURCES", "auth-service=localhost:9080,book-service=localhost:9082") {
+		name, address, ok := strings.Cut(item, "=")
+		if !ok || name == "" || address == "" {
+			return nil, fmt.Errorf("invalid EXPORT_SOURCES entry %q, want name=address", item)
+		}
+		exportSources = append(exportSources, ExportSource{Name: name, Address: address})
+	}
+
+	notificationRetention, _ := strconv.Atoi(getEnv("NOTIFICATION_RETENTION_DAYS", "90"))
+
+	// The bundled dev keyring is public, so it is only a fallback when
+	// debug mode is set explicitly
+	piiKeyringPath := getEnv("PII_KEYRING_PATH", "")
+	if piiKeyringPath == "" {
+		if os.Getenv("GIN_MODE") != "debug" {
+			return nil, fmt.Errorf("PII_KEYRING_PATH is required unless GIN_MODE=debug")
+		}
+		piiKeyringPath = "keyring.dev.json"
+		log.Printf("PII_KEYRING_PATH is not set, encrypting PII with the development keyring")
+	}
+
+	// Parse preference defaults
+	defaultItemsPerPage, _ := strconv.Atoi(getEnv("DEFAULT_ITEMS_PER_PAGE", "10"))
+	defaultEmailSecurityAlerts, _ := strconv.ParseBool(getEnv("DEFAULT_EMAIL_SECURITY_ALERTS", "true"))
+	defaultEmailRecommendations, _ := strconv.ParseBool(getEnv("DEFAULT_EMAIL_RECOMMENDATIONS", "true"))
+	defaultEmailNewsletter, _ := strconv.ParseBool(getEnv("DEFAULT_EMAIL_NEWSLETTER", "false"))
+
 	config := &Config{
 		Port:    getEnv("PORT", "8081"),
 		GinMode: getEnv("GIN_MODE", "debug"),
@@ -47,11 +162,62 @@ func LoadConfig() (*Config, error) {
 		AuthServiceURL: getEnv("AUTH_SERVICE_URL", "localhost:9080"),
 		RedisURL:       getEnv("REDIS_URL", "localhost:6379"),
 
+		TokenAudience: getEnv("TOKEN_AUDIENCE", "user-service"),
+
 		CacheEnabled:      cacheEnabled,
 		L1CacheTTLMinutes: l1CacheTTL,
 		L2CacheTTLMinutes: l2CacheTTL,
 
 		JWTSecret: getEnv("JWT_SECRET", "your-secret-key-change-this-in-production"),
+
+		RateLimitEnabled:  rateLimitEnabled,
+		RateLimitPolicies: rateLimitPolicies,
+
+		AdminEmails: getEnvList("ADMIN_EMAILS", ""),
+
+		AvatarMaxBytes:     avatarMaxBytes,
+		AvatarMinDimension: avatarMinDimension,
+		AvatarMaxDimension: avatarMaxDimension,
+
+		BlobStoreDriver:    getEnv("BLOB_STORE_DRIVER", "local"),
+		BlobStorePublicURL: getEnv("BLOB_STORE_PUBLIC_URL", "http://localhost:8081/media"),
+		BlobStoreLocalDir:  getEnv("BLOB_STORE_LOCAL_DIR", "./uploads"),
+		S3Endpoint:         getEnv("S3_ENDPOINT", "localhost:9000"),
+		S3Region:           getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:           getEnv("S3_BUCKET", "user-avatars"),
+		S3AccessKey:        getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:        getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:           s3UseSSL,
+
+		ExportSigningKey:     getEnv("EXPORT_SIGNING_KEY", "your-export-signing-key-change-this-in-production"),
+		ExportLinkTTLMinutes: exportLinkTTL,
+		ExportRetentionHours: exportRetention,
+		ExportDownloadURL:    strings.TrimSuffix(getEnv("EXPORT_DOWNLOAD_URL", "http://localhost:8081"), "/"),
+		ExportSources:        exportSources,
+		ExportLocalDir:       getEnv("EXPORT_LOCAL_DIR", "./exports"),
+		ExportS3Bucket:       getEnv("EXPORT_S3_BUCKET", "user-exports"),
+
+		PIIKeyringPath: piiKeyringPath,
+
+		NotificationRetentionDays: notificationRetention,
+		MailDriver:                getEnv("MAIL_DRIVER", "log"),
+		SMTPHost:                  getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:                  getEnv("SMTP_PORT", "587"),
+		SMTPUsername:              getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:              getEnv("SMTP_PASSWORD", ""),
+		MailFrom:                  getEnv("MAIL_FROM", "no-reply@bookstore.local"),
+
+		DefaultTheme:                getEnv("DEFAULT_THEME", "light"),
+		DefaultLanguage:             getEnv("DEFAULT_LANGUAGE", "en"),
+		DefaultTimezone:             getEnv("DEFAULT_TIMEZONE", "UTC"),
+		DefaultItemsPerPage:         defaultItemsPerPage,
+		DefaultEmailSecurityAlerts:  defaultEmailSecurityAlerts,
+		DefaultEmailRecommendations: defaultEmailRecommendations,
+		DefaultEmailNewsletter:      defaultEmailNewsletter,
+	}
+
+	if _, err := config.GetDefaultPreferences(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
@@ -65,6 +231,78 @@ func (c *Config) GetL2CacheTTL() time.Duration {
 	return time.Duration(c.L2CacheTTLMinutes) * time.Minute
 }
 
+func (c *Config) GetBlobStoreConfig() storage.Config {
+	return storage.Config{
+		Driver:      c.BlobStoreDriver,
+		PublicURL:   c.BlobStorePublicURL,
+		LocalDir:    c.BlobStoreLocalDir,
+		S3Endpoint:  c.S3Endpoint,
+		S3Region:    c.S3Region,
+		S3Bucket:    c.S3Bucket,
+		S3AccessKey: c.S3AccessKey,
+		S3SecretKey: c.S3SecretKey,
+		S3UseSSL:    c.S3UseSSL,
+	}
+}
+
+// GetExportBlobStoreConfig uses the avatar storage driver but a separate
+// directory or bucket, which is never served publicly
+func (c *Config) GetExportBlobStoreConfig() storage.Config {
+	cfg := c.GetBlobStoreConfig()
+	cfg.PublicURL = ""
+	cfg.LocalDir = c.ExportLocalDir
+	cfg.S3Bucket = c.ExportS3Bucket
+	return cfg
+}
+
+func (c *Config) GetExportLinkTTL() time.Duration {
+	return time.Duration(c.ExportLinkTTLMinutes) * time.Minute
+}
+
+func (c *Config) GetExportRetention() time.Duration {
+	return time.Duration(c.ExportRetentionHours) * time.Hour
+}
+
+func (c *Config) GetNotificationRetention() time.Duration {
+	return time.Duration(c.NotificationRetentionDays) * 24 * time.Hour
+}
+
+func (c *Config) GetMailConfig() mail.Config {
+	return mail.Config{
+		Driver:       c.MailDriver,
+		SMTPHost:     c.SMTPHost,
+		SMTPPort:     c.SMTPPort,
+		SMTPUsername: c.SMTPUsername,
+		SMTPPassword: c.SMTPPassword,
+		From:         c.MailFrom,
+	}
+}
+
+func (c *Config) GetDefaultPreferences() (models.UserPreferences, error) {
+	var theme models.Theme
+	if err := theme.FromString(c.DefaultTheme); err != nil {
+		return models.UserPreferences{}, fmt.Errorf("invalid DEFAULT_THEME: %v", err)
+	}
+	if _, err := time.LoadLocation(c.DefaultTimezone); err != nil {
+		return models.UserPreferences{}, fmt.Errorf("invalid DEFAULT_TIMEZONE: %v", err)
+	}
+	if c.DefaultItemsPerPage < 1 || c.DefaultItemsPerPage > 100 {
+		return models.UserPreferences{}, fmt.Errorf("invalid DEFAULT_ITEMS_PER_PAGE: must be between 1 and 100")
+	}
+
+	return models.UserPreferences{
+		Theme:        theme,
+		Language:     c.DefaultLanguage,
+		Timezone:     c.DefaultTimezone,
+		ItemsPerPage: c.DefaultItemsPerPage,
+		EmailNotifications: models.EmailNotifications{
+			SecurityAlerts:  c.DefaultEmailSecurityAlerts,
+			Recommendations: c.DefaultEmailRecommendations,
+			Newsletter:      c.DefaultEmailNewsletter,
+		},
+	}, nil
+}
+
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -72,7 +310,21 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvList splits a comma separated variable, skipping empty items
+func getEnvList(key, fallback string) []string {
+	var values []string
+	for _, value := range strings.Split(getEnv(key, fallback), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// GetDatabaseURL reads and writes DATETIME columns as UTC. Rows written
+// while the DSN had loc=Local hold the host's local time, see the readme
+// before upgrading a database written by a host not on UTC.
 func (c *Config) GetDatabaseURL() string {
-	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=UTC",
 		c.DBUser, c.DBPassword, c.DBHost, c.DBPort, c.DBName)
 }
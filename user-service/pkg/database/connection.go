@@ -20,8 +20,10 @@ var DB *gorm.DB
 func InitDB(cfg *config.Config) error {
 	dsn := cfg.GetDatabaseURL()
 
+	// TranslateError turns unique index violations into gorm.ErrDuplicatedKey
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger:         logger.Default.LogMode(logger.Info),
+		TranslateError: true,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %v", err)
@@ -41,10 +43,59 @@ func InitDB(cfg *config.Config) error {
 // automatically create or update database tables based on model definitions
 // It's important because it ensures the database schema matches the Go models
 func AutoMigrate() error {
-	return DB.AutoMigrate(
+	if err := DB.AutoMigrate(
 		&models.User{},
 		&models.UserProfile{},
-	)
+		&models.UserAddress{},
+		&models.UserPreferences{},
+		&models.UserStatusChange{},
+		&models.DataExport{},
+		&models.ChangeEntry{},
+		&models.UserFollow{},
+		&models.UserBlock{},
+		&models.UserMute{},
+		&models.Notification{},
+	); err != nil {
+		return err
+	}
+
+	if err := migrateProfileAddresses(DB); err != nil {
+		return err
+	}
+	return migratePIIEncryption(DB)
+}
+
+// migrateProfileAddresses moves the address that used to be embedded in
+// user_profiles (address_* columns) into user_addresses as the default
+// address, then drops the old columns. Safe to run again after a failure.
+func migrateProfileAddresses(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.UserProfile{}, "address_street") {
+		return nil
+	}
+
+	err := db.Exec(`
+		INSERT INTO user_addresses (user_id, label, street, city, state, zip_code, country, is_default, created_at, updated_at)
+		SELECT p.id, 'home', COALESCE(p.address_street, ''), COALESCE(p.address_city, ''), p.address_state,
+			p.address_zip_code, COALESCE(p.address_country, ''), TRUE, NOW(3), NOW(3)
+		FROM user_profiles p
+		WHERE p.deleted_at IS NULL
+			AND COALESCE(p.address_street, p.address_city, p.address_state, p.address_zip_code, p.address_country) IS NOT NULL
+			AND NOT EXISTS (SELECT 1 FROM user_addresses a WHERE a.user_id = p.id)`).Error
+	if err != nil {
+		return fmt.Errorf("failed to copy profile addresses: %v", err)
+	}
+
+	// One statement so the columns are dropped together or not at all
+	err = db.Exec(`
+		ALTER TABLE user_profiles
+			DROP COLUMN address_street, DROP COLUMN address_city, DROP COLUMN address_state,
+			DROP COLUMN address_zip_code, DROP COLUMN address_country`).Error
+	if err != nil {
+		return fmt.Errorf("failed to drop profile address columns: %v", err)
+	}
+
+	log.Println("Moved profile addresses to user_addresses")
+	return nil
 }
 
 // GetDB is used to
@@ -0,0 +1,240 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"user-service/internal/models"
+	"user-service/internal/pii"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	piiBatchSize = 500
+
+	// How often values left under a retired key are looked for
+	piiRewrapInterval = time.Hour
+)
+
+// piiTable is a table with serializer:pii columns. rewrite loads one row
+// through its model and writes the encrypted columns back, which encrypts
+// them under the active key and fills in blind indexes.
+type piiTable struct {
+	name    string
+	columns []string
+	rewrite func(tx *gorm.DB, id uint) error
+}
+
+var piiTables = []piiTable{
+	{
+		name:    "user_profiles",
+		columns: []string{"phone", "date_of_birth"},
+		rewrite: func(tx *gorm.DB, id uint) error {
+			var profile models.UserProfile
+			if err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).First(&profile, id).Error; err != nil {
+				return err
+			}
+			profile.PhoneIndex = pii.BlindIndexOf(profile.Phone)
+			return tx.Unscoped().Model(&profile).Select("phone", "date_of_birth", "phone_index").UpdateColumns(&profile).Error
+		},
+	},
+	{
+		name:    "user_addresses",
+		columns: []string{"street", "city", "state", "zip_code"},
+		rewrite: func(tx *gorm.DB, id uint) error {
+			var address models.UserAddress
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&address, id).Error; err != nil {
+				return err
+			}
+			return tx.Model(&address).Select("street", "city", "state", "zip_code").UpdateColumns(&address).Error
+		},
+	},
+}
+
+// migratePIIEncryption encrypts PII written before encryption was added,
+// and re-encrypts values written before ciphertexts were bound to their
+// row. Rows are read with the plaintext fallback of the pii serializer, so
+// the service keeps working if this is interrupted, and it resumes on the
+// next start.
+func migratePIIEncryption(db *gorm.DB) error {
+	rewritten, err := rewritePII(context.Background(), db, func(value string) bool {
+		return !pii.IsBound(value)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encrypt existing PII: %v", err)
+	}
+	if rewritten > 0 {
+		log.Printf("Encrypted PII in %d rows", rewritten)
+	}
+	return nil
+}
+
+// RunPIIRewrap moves values encrypted under a retired key to the active key
+// of keyring, until ctx is done. Only the wrapped data keys change, values
+// are not decrypted. Retired keys must stay in the keyring until a pass
+// finds nothing left to move.
+func RunPIIRewrap(ctx context.Context, keyring *pii.Keyring) {
+	ticker := time.NewTicker(piiRewrapInterval)
+	defer ticker.Stop()
+
+	for {
+		rewrapped, err := rewrapPII(ctx, DB, keyring)
+		if err != nil {
+			log.Printf("Failed to rewrap PII: %v", err)
+		} else if rewrapped > 0 {
+			log.Printf("Rewrapped %d PII values", rewrapped)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// piiRewrap is one value to move to the active key
+type piiRewrap struct {
+	id       uint
+	column   string
+	old, new string
+}
+
+// rewrapPII rewraps every value under a retired key, returning how many it
+// rewrapped. A value is only replaced while it still holds what was read,
+// so a concurrent save under the active key wins.
+func rewrapPII(ctx context.Context, db *gorm.DB, keyring *pii.Keyring) (int, error) {
+	rewrapped := 0
+	for _, table := range piiTables {
+		var lastID uint
+		for {
+			if err := ctx.Err(); err != nil {
+				return rewrapped, err
+			}
+
+			var rewraps []piiRewrap
+			next, err := scanPIIRows(db, table, lastID, func(id uint, values []sql.NullString) error {
+				for i, value := range values {
+					if !value.Valid || !pii.IsEncrypted(value.String) || !keyring.NeedsRewrite(value.String) {
+						continue
+					}
+					rewrappedValue, err := keyring.Rewrap(value.String)
+					if err != nil {
+						return fmt.Errorf("%s %d %s: %v", table.name, id, table.columns[i], err)
+					}
+					rewraps = append(rewraps, piiRewrap{id: id, column: table.columns[i], old: value.String, new: rewrappedValue})
+				}
+				return nil
+			})
+			if err != nil {
+				return rewrapped, err
+			}
+
+			for _, r := range rewraps {
+				result := db.Table(table.name).
+					Where("id = ? AND "+r.column+" = ?", r.id, r.old).
+					Update(r.column, r.new)
+				if result.Error != nil {
+					return rewrapped, fmt.Errorf("%s %d: %v", table.name, r.id, result.Error)
+				}
+				rewrapped += int(result.RowsAffected)
+			}
+
+			if next == 0 {
+				break
+			}
+			lastID = next
+		}
+	}
+	return rewrapped, nil
+}
+
+// rewritePII rewrites every row with an encrypted column value that
+// needsRewrite, returning how many rows it rewrote
+func rewritePII(ctx context.Context, db *gorm.DB, needsRewrite func(value string) bool) (int, error) {
+	rewritten := 0
+	for _, table := range piiTables {
+		var lastID uint
+		for {
+			if err := ctx.Err(); err != nil {
+				return rewritten, err
+			}
+
+			var ids []uint
+			next, err := scanPIIRows(db, table, lastID, func(id uint, values []sql.NullString) error {
+				for _, value := range values {
+					if value.Valid && needsRewrite(value.String) {
+						ids = append(ids, id)
+						break
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return rewritten, err
+			}
+			for _, id := range ids {
+				err := db.Transaction(func(tx *gorm.DB) error {
+					return table.rewrite(tx, id)
+				})
+				if err != nil {
+					return rewritten, fmt.Errorf("%s %d: %v", table.name, id, err)
+				}
+				rewritten++
+			}
+
+			if next == 0 {
+				break
+			}
+			lastID = next
+		}
+	}
+	return rewritten, nil
+}
+
+// scanPIIRows calls visit with the encrypted columns of one batch of table
+// after lastID, and returns where the next batch starts or 0 after the last
+// batch
+func scanPIIRows(db *gorm.DB, table piiTable, lastID uint, visit func(id uint, values []sql.NullString) error) (uint, error) {
+	rows, err := db.Table(table.name).
+		Select(append([]string{"id"}, table.columns...)).
+		Where("id > ?", lastID).
+		Order("id").
+		Limit(piiBatchSize).
+		Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var id uint
+	scanned := 0
+	values := make([]sql.NullString, len(table.columns))
+	dest := []any{&id}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return 0, err
+		}
+		scanned++
+		if err := visit(id, values); err != nil {
+			return 0, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if scanned < piiBatchSize {
+		return 0, nil
+	}
+	return id, nil
+}